@@ -38,53 +38,72 @@ type PictureUploadByUrlReq struct {
 
 // PictureVO 图片视图对象（用户视图）
 type PictureVO struct {
-	Id             int64    `json:"id"`
-	Url            string   `json:"url"`
-	Name           string   `json:"name"`
-	Introduction   string   `json:"introduction"`
-	Category       string   `json:"category"`
-	Tags           []string `json:"tags"`
-	PicSize        int64    `json:"picSize"`
-	PicWidth       int      `json:"picWidth"`
-	PicHeight      int      `json:"picHeight"`
-	PicScale       float64  `json:"picScale"`
-	PicFormat      string   `json:"picFormat"`
-	UserId         int64    `json:"userId"`
-	SpaceId        int64    `json:"spaceId"`
-	CreateTime     string   `json:"createTime"`
-	EditTime       string   `json:"editTime"`
-	UpdateTime     string   `json:"updateTime"`
-	ThumbnailUrl   string   `json:"thumbnailUrl"`
-	PicColor       string   `json:"picColor"`
-	User           *UserVO  `json:"user,omitempty"`
-	PermissionList []string `json:"permissionList,omitempty"`
+	Id              int64          `json:"id"`
+	Url             string         `json:"url"`
+	Name            string         `json:"name"`
+	Introduction    string         `json:"introduction"`
+	Category        string         `json:"category"`
+	Tags            []string       `json:"tags"`
+	PicSize         int64          `json:"picSize"`
+	PicWidth        int            `json:"picWidth"`
+	PicHeight       int            `json:"picHeight"`
+	PicScale        float64        `json:"picScale"`
+	PicFormat       string         `json:"picFormat"`
+	UserId          int64          `json:"userId"`
+	SpaceId         int64          `json:"spaceId"`
+	CreateTime      string         `json:"createTime"`
+	EditTime        string         `json:"editTime"`
+	UpdateTime      string         `json:"updateTime"`
+	ThumbnailUrl    string         `json:"thumbnailUrl"`
+	PicColor        string         `json:"picColor"`
+	SourcePictureId int64          `json:"sourcePictureId,omitempty"` // AI生成结果的来源图片ID
+	User            *UserVO        `json:"user,omitempty"`
+	PermissionList  []string       `json:"permissionList,omitempty"`
+	Exif            *PictureExifVO `json:"exif,omitempty"`
+}
+
+// PictureExifVO 图片EXIF信息
+type PictureExifVO struct {
+	Make         string   `json:"make"`                // 相机厂商
+	Model        string   `json:"model"`               // 相机型号
+	LensModel    string   `json:"lensModel"`           // 镜头型号
+	ExposureTime string   `json:"exposureTime"`        // 曝光时间
+	FNumber      float64  `json:"fNumber"`             // 光圈值
+	Iso          int      `json:"iso"`                 // ISO 感光度
+	FocalLength  float64  `json:"focalLength"`         // 焦距（mm）
+	CaptureTime  string   `json:"captureTime"`         // 拍摄时间
+	Orientation  int      `json:"orientation"`         // 方向
+	Latitude     *float64 `json:"latitude,omitempty"`  // 纬度
+	Longitude    *float64 `json:"longitude,omitempty"` // 经度
 }
 
 // Picture 图片实体对象（管理员视图，包含审核信息）
 type Picture struct {
-	Id            int64   `json:"id"`
-	Url           string  `json:"url"`
-	Name          string  `json:"name"`
-	Introduction  string  `json:"introduction"`
-	Category      string  `json:"category"`
-	Tags          string  `json:"tags"` // 注意：前端期望JSON字符串格式 "[\"标签1\",\"标签2\"]"
-	PicSize       int64   `json:"picSize"`
-	PicWidth      int     `json:"picWidth"`
-	PicHeight     int     `json:"picHeight"`
-	PicScale      float64 `json:"picScale"`
-	PicFormat     string  `json:"picFormat"`
-	UserId        int64   `json:"userId"`
-	SpaceId       int64   `json:"spaceId"`
-	CreateTime    string  `json:"createTime"`
-	EditTime      string  `json:"editTime"`
-	UpdateTime    string  `json:"updateTime"`
-	ThumbnailUrl  string  `json:"thumbnailUrl"`
-	PicColor      string  `json:"picColor"`
-	IsDelete      int     `json:"isDelete"`      // 删除状态
-	ReviewStatus  int     `json:"reviewStatus"`  // 审核状态：0-待审核，1-通过，2-拒绝
-	ReviewMessage string  `json:"reviewMessage"` // 审核信息
-	ReviewerId    int64   `json:"reviewerId"`    // 审核人ID
-	ReviewTime    string  `json:"reviewTime"`    // 审核时间
+	Id             int64   `json:"id"`
+	Url            string  `json:"url"`
+	Name           string  `json:"name"`
+	Introduction   string  `json:"introduction"`
+	Category       string  `json:"category"`
+	Tags           string  `json:"tags"` // 注意：前端期望JSON字符串格式 "[\"标签1\",\"标签2\"]"
+	PicSize        int64   `json:"picSize"`
+	PicWidth       int     `json:"picWidth"`
+	PicHeight      int     `json:"picHeight"`
+	PicScale       float64 `json:"picScale"`
+	PicFormat      string  `json:"picFormat"`
+	UserId         int64   `json:"userId"`
+	SpaceId        int64   `json:"spaceId"`
+	CreateTime     string  `json:"createTime"`
+	EditTime       string  `json:"editTime"`
+	UpdateTime     string  `json:"updateTime"`
+	ThumbnailUrl   string  `json:"thumbnailUrl"`
+	PicColor       string  `json:"picColor"`
+	IsDelete       int     `json:"isDelete"`       // 删除状态
+	ReviewStatus   int     `json:"reviewStatus"`   // 审核状态：0-待审核，1-通过，2-拒绝
+	ReviewMessage  string  `json:"reviewMessage"`  // 审核信息
+	ReviewerId     int64   `json:"reviewerId"`     // 审核人ID
+	ReviewTime     string  `json:"reviewTime"`     // 审核时间
+	RiskScore      int     `json:"riskScore"`      // 风险分（0-100，自动审核给出）
+	ModerationRule string  `json:"moderationRule"` // 决定审核结果的规则：auto-自动通过 manual-人工审核 none-待人工审核 keyword.reject、file.format、blocklist.exact 等-命中的规则
 }
 
 // PictureUploadRes 图片上传响应
@@ -97,6 +116,71 @@ type PictureUploadByUrlRes struct {
 	*PictureVO
 }
 
+// PictureUploadInitReq 初始化分片上传请求
+type PictureUploadInitReq struct {
+	FileName string `json:"fileName" v:"required#文件名不能为空"`
+	FileSize int64  `json:"fileSize" v:"required|min:1#文件大小不能为空|文件大小必须大于0"`
+	Checksum string `json:"checksum" v:"required|length:64,64#文件校验值不能为空|文件校验值必须为SHA-256十六进制字符串" dc:"完整文件的SHA-256"`
+	SpaceId  int64  `json:"spaceId"`
+}
+
+// PictureUploadInitRes 初始化分片上传响应
+type PictureUploadInitRes struct {
+	UploadId   string `json:"uploadId"`
+	ChunkSize  int64  `json:"chunkSize"`  // 分片大小（最后一片可以更小）
+	ChunkCount int    `json:"chunkCount"` // 分片数量
+	ExpireTime string `json:"expireTime"` // 会话过期时间，上传分片时自动续期
+}
+
+// PictureUploadChunkReq 上传分片请求（分片内容通过表单文件 chunk 提交）
+type PictureUploadChunkReq struct {
+	UploadId string `json:"uploadId" v:"required#上传ID不能为空"`
+	Index    int    `json:"index" v:"min:0#分片序号不能小于0" dc:"分片序号，从0开始"`
+}
+
+// PictureUploadChunkRes 上传分片响应
+type PictureUploadChunkRes struct {
+	*PictureUploadStatusRes
+}
+
+// PictureUploadSessionReq 分片上传会话请求（查询进度、完成、取消共用）
+type PictureUploadSessionReq struct {
+	UploadId string `json:"uploadId" v:"required#上传ID不能为空"`
+}
+
+// PictureUploadStatusRes 分片上传进度响应
+type PictureUploadStatusRes struct {
+	UploadId   string `json:"uploadId"`
+	ChunkSize  int64  `json:"chunkSize"`
+	ChunkCount int    `json:"chunkCount"`
+	Uploaded   []int  `json:"uploaded"` // 已上传的分片序号
+	ExpireTime string `json:"expireTime"`
+}
+
+// PictureUploadAbortRes 取消分片上传响应
+type PictureUploadAbortRes struct {
+	Success bool `json:"success"`
+}
+
+// PictureUploadPresignReq 申请直传地址请求
+type PictureUploadPresignReq struct {
+	FileName string `json:"fileName" v:"required#文件名不能为空"`
+	FileSize int64  `json:"fileSize" dc:"文件大小（字节），传入时提前检查空间额度"`
+	SpaceId  int64  `json:"spaceId"`
+}
+
+// PictureUploadPresignRes 申请直传地址响应
+type PictureUploadPresignRes struct {
+	UploadId   string `json:"uploadId"`
+	UploadUrl  string `json:"uploadUrl"`  // 预签名上传地址，使用 PUT 方法上传文件内容
+	ExpireTime string `json:"expireTime"` // 上传地址过期时间
+}
+
+// PictureUploadFinalizeReq 完成直传请求
+type PictureUploadFinalizeReq struct {
+	UploadId string `json:"uploadId" v:"required#上传ID不能为空"`
+}
+
 // DeleteReq 删除请求
 type DeleteReq struct {
 	Id int64 `json:"id" v:"required#ID不能为空"`
@@ -122,6 +206,23 @@ type PictureAdminGetRes struct {
 	*Picture
 }
 
+// PictureRenderReq 图片实时转换请求
+type PictureRenderReq struct {
+	Id      int64  `json:"id" v:"required#图片ID不能为空" dc:"图片ID"`
+	Width   int    `json:"w" v:"min:0|max:4096#宽度不能小于0|宽度不能超过4096" dc:"目标宽度，0表示按高度等比缩放"`
+	Height  int    `json:"h" v:"min:0|max:4096#高度不能小于0|高度不能超过4096" dc:"目标高度，0表示按宽度等比缩放"`
+	Fit     string `json:"fit" v:"in:contain,cover,fill#缩放方式只能是contain、cover或fill" dc:"缩放方式：contain 等比缩放到框内（默认），cover 等比缩放后居中裁剪，fill 拉伸填满"`
+	Format  string `json:"fmt" v:"in:jpeg,jpg,png,gif#输出格式只能是jpeg、png或gif" dc:"输出格式，默认与原图相同（原图格式无法输出时使用jpeg）"`
+	Quality int    `json:"q" v:"min:0|max:100#质量不能小于0|质量不能超过100" dc:"JPEG质量（1-100），默认80"`
+}
+
+// PictureRenderRes 图片实时转换响应（由控制器直接输出图片内容）
+type PictureRenderRes struct {
+	Data        []byte `json:"-"`
+	ContentType string `json:"-"`
+	Private     bool   `json:"-"` // 私有/团队空间图片或未过审的图片，不允许公共缓存
+}
+
 // PictureQueryReq 图片查询请求
 type PictureQueryReq struct {
 	Current      int      `json:"current" p:"current" v:"min:1#页码最小为1"`
@@ -133,6 +234,11 @@ type PictureQueryReq struct {
 	Category     string   `json:"category" p:"category"`
 	SortField    string   `json:"sortField" p:"sortField"`
 	SortOrder    string   `json:"sortOrder" p:"sortOrder"`
+	// EXIF过滤条件
+	CaptureTimeStart string `json:"captureTimeStart" p:"captureTimeStart" dc:"拍摄时间起，如 2024-01-01 或 2024-01-01 08:00:00"`
+	CaptureTimeEnd   string `json:"captureTimeEnd" p:"captureTimeEnd" dc:"拍摄时间止，只传日期时包含当天"`
+	CameraMake       string `json:"cameraMake" p:"cameraMake" dc:"相机厂商（模糊匹配）"`
+	CameraModel      string `json:"cameraModel" p:"cameraModel" dc:"相机型号（模糊匹配）"`
 }
 
 type PageInfo struct {
@@ -180,6 +286,99 @@ type PictureReviewRes struct {
 	Success bool `json:"success"`
 }
 
+// PictureReviewBatchReq 批量审核图片请求（管理员），所有图片使用相同的审核结论与审核信息
+type PictureReviewBatchReq struct {
+	IdList        []int64 `json:"idList" v:"required#图片ID列表不能为空"`
+	ReviewStatus  int     `json:"reviewStatus" v:"required|in:1,2#审核状态不能为空且必须为1,2"`
+	ReviewMessage string  `json:"reviewMessage" v:"required|max-length:500#审核信息不能为空|审核信息不能超过500字"`
+}
+
+// PictureReviewBatchRes 批量审核图片响应
+type PictureReviewBatchRes struct {
+	SuccessCount int     `json:"successCount"`
+	FailedIdList []int64 `json:"failedIdList"` // 不存在或已删除的图片
+}
+
+// PictureReviewLogListReq 审核记录查询请求（管理员），可按图片、审核人、来源与时间筛选
+type PictureReviewLogListReq struct {
+	Current    int    `json:"current" p:"current" d:"1" v:"min:1#页码最小为1"`
+	PageSize   int    `json:"pageSize" p:"pageSize" d:"10" v:"between:1,100#页面大小为1-100"`
+	PictureId  int64  `json:"pictureId" p:"pictureId"`
+	ReviewerId *int64 `json:"reviewerId" p:"reviewerId" dc:"审核人ID，0为自动审核"`
+	Source     string `json:"source" p:"source" v:"in:manual,batch,auto,blocklist,edit" dc:"manual-人工审核 batch-批量审核 auto-自动审核 blocklist-黑名单 edit-编辑后重新审核"`
+	StartTime  string `json:"startTime" p:"startTime" dc:"起始时间，如 2024-01-01 或 2024-01-01 08:00:00"`
+	EndTime    string `json:"endTime" p:"endTime" dc:"截止时间，只传日期时包含当天"`
+}
+
+// PictureReviewLogListRes 审核记录查询响应
+type PictureReviewLogListRes struct {
+	Records []PictureReviewLogVO `json:"records"`
+	*PageInfo
+}
+
+// PictureReviewLogVO 审核记录视图对象
+type PictureReviewLogVO struct {
+	Id           int64  `json:"id"`
+	PictureId    int64  `json:"pictureId"`
+	ReviewerId   int64  `json:"reviewerId"`   // 0为自动审核
+	ReviewerName string `json:"reviewerName"` // 审核人昵称
+	OldStatus    int    `json:"oldStatus"`
+	NewStatus    int    `json:"newStatus"`
+	Message      string `json:"message"`
+	Source       string `json:"source"`
+	Rule         string `json:"rule"` // 自动审核命中的规则
+	RiskScore    int    `json:"riskScore"`
+	CreateTime   string `json:"createTime"`
+}
+
+// PictureReviewPendingReq 待审核队列请求（管理员），按进入待审核的时间从早到晚排列
+type PictureReviewPendingReq struct {
+	Current        int    `json:"current" p:"current" d:"1" v:"min:1#页码最小为1"`
+	PageSize       int    `json:"pageSize" p:"pageSize" d:"10" v:"between:1,100#页面大小为1-100"`
+	SpaceId        *int64 `json:"spaceId" p:"spaceId" dc:"空间ID，0为公共图库"`
+	UserId         int64  `json:"userId" p:"userId" dc:"上传用户ID"`
+	Category       string `json:"category" p:"category"`
+	MinRiskScore   int    `json:"minRiskScore" p:"minRiskScore" v:"between:0,100#风险分为0-100"`
+	ModerationRule string `json:"moderationRule" p:"moderationRule" dc:"自动审核规则，如 keyword.review、blocklist.similar"`
+}
+
+// PictureReviewPendingRes 待审核队列响应
+type PictureReviewPendingRes struct {
+	Records []PictureReviewPendingVO `json:"records"`
+	*PageInfo
+}
+
+// PictureReviewPendingVO 待审核图片
+type PictureReviewPendingVO struct {
+	Picture
+	WaitMinutes int64 `json:"waitMinutes"` // 已等待审核的分钟数
+}
+
+// PictureReviewStatsReq 审核人工作量统计请求（管理员），默认统计最近7天
+type PictureReviewStatsReq struct {
+	StartTime string `json:"startTime" p:"startTime" dc:"起始时间，如 2024-01-01 或 2024-01-01 08:00:00"`
+	EndTime   string `json:"endTime" p:"endTime" dc:"截止时间，只传日期时包含当天"`
+}
+
+// PictureReviewStatsRes 审核人工作量统计响应
+type PictureReviewStatsRes struct {
+	StartTime    string            `json:"startTime"`
+	EndTime      string            `json:"endTime"`
+	PendingCount int               `json:"pendingCount"` // 当前待审核图片数
+	Reviewers    []ReviewerStatsVO `json:"reviewers"`    // 按审核量从高到低排列，自动审核的审核人ID为0
+}
+
+// ReviewerStatsVO 审核人工作量
+type ReviewerStatsVO struct {
+	ReviewerId     int64   `json:"reviewerId"`
+	ReviewerName   string  `json:"reviewerName"`
+	Total          int     `json:"total"`        // 审核总数
+	Approved       int     `json:"approved"`     // 通过数
+	Rejected       int     `json:"rejected"`     // 拒绝数
+	DailyAverage   float64 `json:"dailyAverage"` // 日均审核数
+	LastReviewTime string  `json:"lastReviewTime"`
+}
+
 // PictureUploadByBatchReq 批量上传图片请求
 type PictureUploadByBatchReq struct {
 	SearchText string `json:"searchText" v:"required#搜索关键词不能为空"`
@@ -190,7 +389,10 @@ type PictureUploadByBatchReq struct {
 
 // PictureUploadByBatchRes 批量上传图片响应
 type PictureUploadByBatchRes struct {
-	SuccessCount int `json:"successCount"`
+	SuccessCount  int    `json:"successCount"`
+	FailCount     int    `json:"failCount"`         // 上传失败数量
+	QuotaExceeded bool   `json:"quotaExceeded"`     // 是否因空间额度不足提前结束
+	Message       string `json:"message,omitempty"` // 提示信息
 }
 
 // PictureEditByBatchReq 批量编辑图片请求
@@ -214,8 +416,10 @@ type SearchPictureByPictureReq struct {
 
 // SearchPictureByPictureRes 以图搜图响应
 type SearchPictureByPictureRes struct {
-	FromUrl  string `json:"fromUrl"`  // 图片来源URL
-	ThumbUrl string `json:"thumbUrl"` // 缩略图URL
+	*PictureVO
+	FromUrl    string  `json:"fromUrl"`    // 图片来源URL
+	ThumbUrl   string  `json:"thumbUrl"`   // 缩略图URL
+	Similarity float64 `json:"similarity"` // 相似度（0-1）
 }
 
 // SearchPictureByColorReq 按颜色搜索图片请求
@@ -234,25 +438,30 @@ type CreatePictureOutPaintingReq struct {
 	PictureId  int64                  `json:"pictureId" v:"required#图片ID不能为空"`
 	Prompt     string                 `json:"prompt" v:"required#扩图描述不能为空"`
 	Parameters *OutPaintingParameters `json:"parameters"`
+	SaveMode   string                 `json:"saveMode" v:"in:new,version#保存方式只能是new或version" dc:"结果保存方式：为空不保存，new-保存为同一空间的新图片，version-作为原图的新版本"`
 }
 
-// OutPaintingParameters 扩图参数
+// OutPaintingParameters 扩图参数，比例为扩图后与原图的宽高之比，不小于1，上限由空间级别决定
 type OutPaintingParameters struct {
-	XScale int `json:"xScale"`
-	YScale int `json:"yScale"`
+	XScale float64 `json:"xScale" dc:"水平扩展比例，为空时为1"`
+	YScale float64 `json:"yScale" dc:"垂直扩展比例，为空时为1"`
 }
 
-// CreatePictureOutPaintingRes 创建图片扩图响应
+// CreatePictureOutPaintingRes 创建图片扩图响应（扩图异步执行，通过任务ID查询结果）
 type CreatePictureOutPaintingRes struct {
-	OutputImageUrl string `json:"outputImageUrl"` // 输出图片URL
-	Success        bool   `json:"success"`        // 是否成功
-	Message        string `json:"message"`        // 消息
+	TaskId       string `json:"taskId"`       // 任务ID
+	TaskStatus   string `json:"taskStatus"`   // 任务状态
+	OutputWidth  int    `json:"outputWidth"`  // 扩图后的宽度
+	OutputHeight int    `json:"outputHeight"` // 扩图后的高度
+	Success      bool   `json:"success"`      // 是否成功
+	Message      string `json:"message"`      // 消息
 }
 
 // CreatePictureAIEditingTaskReq AI编辑图片任务创建请求
 type CreatePictureAIEditingTaskReq struct {
 	PictureId int64  `json:"pictureId" v:"required#图片ID不能为空"`
 	Prompt    string `json:"prompt" v:"required#编辑描述不能为空"`
+	SaveMode  string `json:"saveMode" v:"in:new,version#保存方式只能是new或version" dc:"结果保存方式：为空不保存，new-保存为同一空间的新图片，version-作为原图的新版本"`
 }
 
 // CreatePictureAIEditingTaskRes AI编辑图片任务创建响应
@@ -263,7 +472,8 @@ type CreatePictureAIEditingTaskRes struct {
 
 // CreateAIEditingTaskResponse AI编辑任务响应
 type CreateAIEditingTaskResponse struct {
-	TaskId string `json:"taskId"`
+	TaskId     string `json:"taskId"`
+	TaskStatus string `json:"taskStatus"` // 任务状态
 }
 
 // GetPictureAIEditingTaskReq 获取AI编辑任务请求
@@ -277,10 +487,217 @@ type GetPictureAIEditingTaskRes struct {
 	RequestId string                    `json:"requestId"`
 }
 
-// GetAIEditingTaskResponse 获取AI编辑任务响应
+// GetAIEditingTaskResponse 获取AI编辑任务响应（扩图任务共用）
 type GetAIEditingTaskResponse struct {
-	TaskId         string `json:"taskId"`
-	TaskStatus     string `json:"taskStatus"`     // SUCCEEDED, FAILED, RUNNING
-	OutputImageUrl string `json:"outputImageUrl"` // 输出图片URL
-	ErrorMessage   string `json:"errorMessage"`   // 错误信息
+	TaskId         string     `json:"taskId"`
+	TaskType       string     `json:"taskType"`               // outPainting-扩图, editing-AI编辑
+	PictureId      int64      `json:"pictureId"`              // 原图ID
+	TaskStatus     string     `json:"taskStatus"`             // PENDING, RUNNING, SUCCEEDED, FAILED
+	Attempts       int        `json:"attempts"`               // 已执行次数
+	OutputImageUrl string     `json:"outputImageUrl"`         // 输出图片URL（AI服务托管，会过期）
+	OutputWidth    int        `json:"outputWidth,omitempty"`  // 扩图后的宽度（扩图任务）
+	OutputHeight   int        `json:"outputHeight,omitempty"` // 扩图后的高度（扩图任务）
+	Picture        *PictureVO `json:"picture,omitempty"`      // 保存到图库后的图片
+	ErrorMessage   string     `json:"errorMessage"`           // 错误信息
+	CreateTime     string     `json:"createTime"`
+	FinishTime     string     `json:"finishTime,omitempty"`
+}
+
+// PictureRecycleQueryReq 回收站图片查询请求（spaceId为空时查询自己在公共图库删除的图片）
+type PictureRecycleQueryReq struct {
+	Current  int   `json:"current" p:"current" v:"min:1#页码最小为1"`
+	PageSize int   `json:"pageSize" p:"pageSize" v:"between:1,100#页面大小为1-100"`
+	SpaceId  int64 `json:"spaceId" p:"spaceId"`
+}
+
+// PictureRecycleQueryRes 回收站图片查询响应
+type PictureRecycleQueryRes struct {
+	Records []PictureRecycleVO `json:"records"`
+	*PageInfo
+}
+
+// PictureRecycleVO 回收站图片视图对象
+type PictureRecycleVO struct {
+	*PictureVO
+	DeleteTime string `json:"deleteTime"` // 删除时间
+	PurgeTime  string `json:"purgeTime"`  // 预计彻底删除时间
+}
+
+// PictureRestoreReq 恢复图片请求
+type PictureRestoreReq struct {
+	Id int64 `json:"id" v:"required#图片ID不能为空"`
+}
+
+// PictureRestoreRes 恢复图片响应
+type PictureRestoreRes struct {
+	Success bool `json:"success"`
+}
+
+// PictureDuplicateClusterReq 近似重复图片聚类请求（管理员）
+type PictureDuplicateClusterReq struct {
+	SpaceId     *int64 `json:"spaceId" dc:"空间ID，为空时统计全部图片，0表示公共图库"`
+	MaxDistance int    `json:"maxDistance" v:"between:0,20#相似阈值范围为0-20" dc:"感知哈希最大汉明距离，默认使用配置值"`
+}
+
+// PictureDuplicateCluster 近似重复图片簇
+type PictureDuplicateCluster struct {
+	Size     int         `json:"size"`
+	Pictures []PictureVO `json:"pictures"`
+}
+
+// PictureDuplicateClusterRes 近似重复图片聚类响应
+type PictureDuplicateClusterRes struct {
+	Clusters []PictureDuplicateCluster `json:"clusters"`
+	Scanned  int                       `json:"scanned"` // 参与比较的图片数量
+}
+
+// PictureVersionListReq 图片版本列表请求
+type PictureVersionListReq struct {
+	PictureId int64 `json:"pictureId" v:"required#图片ID不能为空"`
+	Current   int   `json:"current" p:"current" d:"1" v:"min:1#页码最小为1"`
+	PageSize  int   `json:"pageSize" p:"pageSize" d:"10" v:"between:1,100#页面大小为1-100"`
+}
+
+// PictureVersionListRes 图片版本列表响应
+type PictureVersionListRes struct {
+	Records []PictureVersionVO `json:"records"`
+	*PageInfo
+}
+
+// PictureVersionVO 图片版本视图对象（图片信息为该版本的快照）
+type PictureVersionVO struct {
+	Id            int64    `json:"id"`
+	PictureId     int64    `json:"pictureId"`
+	Version       int      `json:"version"`       // 版本号
+	Action        string   `json:"action"`        // 变更类型：create-初始版本 edit-编辑 update-更新 batchEdit-批量编辑 fileEdit-协同编辑保存 rollback-回滚 ai-保存AI结果 suggest-采纳智能标注建议
+	ChangedFields []string `json:"changedFields"` // 变更的字段
+	Remark        string   `json:"remark"`
+	Current       bool     `json:"current"` // 是否与图片当前状态一致
+	Name          string   `json:"name"`
+	Introduction  string   `json:"introduction"`
+	Category      string   `json:"category"`
+	Tags          []string `json:"tags"`
+	Url           string   `json:"url"`
+	ThumbnailUrl  string   `json:"thumbnailUrl"`
+	PicSize       int64    `json:"picSize"`
+	PicWidth      int      `json:"picWidth"`
+	PicHeight     int      `json:"picHeight"`
+	PicFormat     string   `json:"picFormat"`
+	UserId        int64    `json:"userId"` // 操作用户
+	User          *UserVO  `json:"user"`
+	CreateTime    string   `json:"createTime"`
+}
+
+// PictureVersionRollbackReq 回滚图片版本请求
+type PictureVersionRollbackReq struct {
+	PictureId int64 `json:"pictureId" v:"required#图片ID不能为空"`
+	VersionId int64 `json:"versionId" v:"required#版本ID不能为空"`
+}
+
+// PictureVersionRollbackRes 回滚图片版本响应
+type PictureVersionRollbackRes struct {
+	*PictureVO
+}
+
+// PictureSuggestionListReq 智能标注建议列表请求（查询自己图片的建议）
+type PictureSuggestionListReq struct {
+	Current  int   `json:"current" p:"current" d:"1" v:"min:1#页码最小为1"`
+	PageSize int   `json:"pageSize" p:"pageSize" d:"10" v:"between:1,100#页面大小为1-100"`
+	SpaceId  int64 `json:"spaceId" p:"spaceId" dc:"空间ID，为空时查询所有图片的建议"`
+	Status   int   `json:"status" p:"status" d:"0" v:"in:0,1,2#状态只能是0、1、2" dc:"状态：0-待处理 1-已采纳 2-已忽略"`
+}
+
+// PictureSuggestionListRes 智能标注建议列表响应
+type PictureSuggestionListRes struct {
+	Records []PictureSuggestionVO `json:"records"`
+	*PageInfo
+}
+
+// PictureSuggestionVO 智能标注建议视图对象
+type PictureSuggestionVO struct {
+	Id           int64      `json:"id"`
+	PictureId    int64      `json:"pictureId"`
+	Tags         []string   `json:"tags"`         // 建议的标签
+	Category     string     `json:"category"`     // 建议的分类
+	Introduction string     `json:"introduction"` // 建议的简介
+	Provider     string     `json:"provider"`     // 生成建议的服务：heuristic-本地推断 volcengine-火山引擎
+	Status       int        `json:"status"`       // 状态：0-待处理 1-已采纳 2-已忽略
+	Picture      *PictureVO `json:"picture,omitempty"`
+	CreateTime   string     `json:"createTime"`
+}
+
+// PictureSuggestionGenerateReq 重新生成智能标注建议请求（用于已有图片）
+type PictureSuggestionGenerateReq struct {
+	PictureIdList []int64 `json:"pictureIdList" v:"required#图片ID列表不能为空"`
+}
+
+// PictureSuggestionGenerateRes 重新生成智能标注建议响应
+type PictureSuggestionGenerateRes struct {
+	Records []PictureSuggestionVO `json:"records"`
+}
+
+// PictureSuggestionAcceptReq 批量采纳智能标注建议请求
+type PictureSuggestionAcceptReq struct {
+	IdList []int64  `json:"idList" v:"required#建议ID列表不能为空"`
+	Fields []string `json:"fields" dc:"采纳的字段，为空时全部采纳；标签与图片已有标签合并"`
+}
+
+// PictureSuggestionAcceptRes 批量采纳智能标注建议响应
+type PictureSuggestionAcceptRes struct {
+	AcceptedCount int `json:"acceptedCount"` // 成功采纳的数量
+}
+
+// PictureSuggestionDismissReq 批量忽略智能标注建议请求
+type PictureSuggestionDismissReq struct {
+	IdList []int64 `json:"idList" v:"required#建议ID列表不能为空"`
+}
+
+// PictureSuggestionDismissRes 批量忽略智能标注建议响应
+type PictureSuggestionDismissRes struct {
+	Success bool `json:"success"`
+}
+
+// PictureBlocklistAddReq 将图片加入黑名单请求（管理员），内容相同的图片自动拒绝，近似图片转人工审核
+type PictureBlocklistAddReq struct {
+	PictureId int64  `json:"pictureId" v:"required#图片ID不能为空"`
+	Reason    string `json:"reason" v:"required|max-length:200#拉黑原因不能为空|拉黑原因不能超过200字"`
+}
+
+// PictureBlocklistAddRes 将图片加入黑名单响应
+type PictureBlocklistAddRes struct {
+	Id            int64 `json:"id"`
+	RejectedCount int   `json:"rejectedCount"` // 同时被拒绝的内容相同的图片数
+}
+
+// PictureBlocklistListReq 图片黑名单列表请求（管理员）
+type PictureBlocklistListReq struct {
+	Current  int `json:"current" p:"current" d:"1" v:"min:1#页码最小为1"`
+	PageSize int `json:"pageSize" p:"pageSize" d:"10" v:"between:1,100#页面大小为1-100"`
+}
+
+// PictureBlocklistListRes 图片黑名单列表响应
+type PictureBlocklistListRes struct {
+	Records []PictureBlocklistVO `json:"records"`
+	*PageInfo
+}
+
+// PictureBlocklistVO 图片黑名单视图对象
+type PictureBlocklistVO struct {
+	Id          int64  `json:"id"`
+	ContentHash string `json:"contentHash"`
+	PicHash     string `json:"picHash"`
+	Reason      string `json:"reason"`
+	PictureId   int64  `json:"pictureId"` // 来源图片
+	UserId      int64  `json:"userId"`    // 操作管理员
+	CreateTime  string `json:"createTime"`
+}
+
+// PictureBlocklistDeleteReq 移出图片黑名单请求（管理员），已拒绝的图片不会自动恢复
+type PictureBlocklistDeleteReq struct {
+	Id int64 `json:"id" v:"required#黑名单ID不能为空"`
+}
+
+// PictureBlocklistDeleteRes 移出图片黑名单响应
+type PictureBlocklistDeleteRes struct {
+	Success bool `json:"success"`
 }
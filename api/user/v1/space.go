@@ -5,6 +5,8 @@ type SpaceAddReq struct {
 	SpaceName  string `json:"spaceName" v:"required#空间名称不能为空"`
 	SpaceLevel int    `json:"spaceLevel" v:"required|in:0,1,2#空间级别必须为0,1,2"`
 	SpaceType  int    `json:"spaceType" v:"required|in:0,1#空间类型必须为0,1"`
+	// StripMetadata 入库时是否去除GPS等隐私元数据，不传时使用系统默认配置
+	StripMetadata *int `json:"stripMetadata" v:"in:0,1#去除元数据设置必须为0,1"`
 }
 
 // SpaceAddRes 添加空间响应
@@ -16,6 +18,8 @@ type SpaceAddRes struct {
 type SpaceEditReq struct {
 	Id        int64  `json:"id" v:"required#空间ID不能为空"`
 	SpaceName string `json:"spaceName" v:"required#空间名称不能为空"`
+	// StripMetadata 入库时是否去除GPS等隐私元数据，不传时保持不变
+	StripMetadata *int `json:"stripMetadata" v:"in:0,1#去除元数据设置必须为0,1"`
 }
 
 // SpaceEditRes 编辑空间响应
@@ -96,6 +100,9 @@ type SpaceLevel struct {
 	Description string `json:"description"`
 	MaxSize     int64  `json:"maxSize"`
 	MaxCount    int64  `json:"maxCount"`
+	// 扩图限制：单方向最大扩展比例与扩图后的最大像素数
+	MaxOutPaintingScale  float64 `json:"maxOutPaintingScale"`
+	MaxOutPaintingPixels int64   `json:"maxOutPaintingPixels"`
 }
 
 // SpaceLevelListRes 获取空间级别列表响应
@@ -105,19 +112,20 @@ type SpaceLevelListRes struct {
 
 // Space 空间实体对象（管理员视图）
 type Space struct {
-	Id         int64  `json:"id"`
-	SpaceName  string `json:"spaceName"`
-	SpaceLevel int    `json:"spaceLevel"`
-	MaxSize    int64  `json:"maxSize"`
-	MaxCount   int64  `json:"maxCount"`
-	TotalSize  int64  `json:"totalSize"`
-	TotalCount int64  `json:"totalCount"`
-	UserId     int64  `json:"userId"`
-	CreateTime string `json:"createTime"`
-	EditTime   string `json:"editTime"`
-	UpdateTime string `json:"updateTime"`
-	IsDelete   int    `json:"isDelete"`
-	SpaceType  int    `json:"spaceType"`
+	Id            int64  `json:"id"`
+	SpaceName     string `json:"spaceName"`
+	SpaceLevel    int    `json:"spaceLevel"`
+	MaxSize       int64  `json:"maxSize"`
+	MaxCount      int64  `json:"maxCount"`
+	TotalSize     int64  `json:"totalSize"`
+	TotalCount    int64  `json:"totalCount"`
+	UserId        int64  `json:"userId"`
+	CreateTime    string `json:"createTime"`
+	EditTime      string `json:"editTime"`
+	UpdateTime    string `json:"updateTime"`
+	IsDelete      int    `json:"isDelete"`
+	SpaceType     int    `json:"spaceType"`
+	StripMetadata int    `json:"stripMetadata"`
 }
 
 // SpaceVO 空间视图对象（用户视图）
@@ -134,6 +142,7 @@ type SpaceVO struct {
 	EditTime       string   `json:"editTime"`
 	UpdateTime     string   `json:"updateTime"`
 	SpaceType      int      `json:"spaceType"`
+	StripMetadata  int      `json:"stripMetadata"`
 	PermissionList []string `json:"permissionList,omitempty"`
 	User           *UserVO  `json:"user,omitempty"`
 }
@@ -8,3 +8,11 @@ type WebSocketPictureEditReq struct {
 // WebSocketPictureEditRes 图片编辑响应
 type WebSocketPictureEditRes struct {
 }
+
+// WebSocketAITaskReq AI任务通知请求
+type WebSocketAITaskReq struct {
+}
+
+// WebSocketAITaskRes AI任务通知响应
+type WebSocketAITaskRes struct {
+}
@@ -17,6 +17,33 @@
 SET NAMES utf8mb4;
 SET FOREIGN_KEY_CHECKS = 0;
 
+-- ----------------------------
+-- Table structure for ai_task
+-- ----------------------------
+DROP TABLE IF EXISTS `ai_task`;
+CREATE TABLE `ai_task` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'id',
+  `taskType` varchar(32) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '任务类型：outPainting-扩图; editing-AI编辑',
+  `pictureId` bigint NOT NULL COMMENT '原图 id',
+  `userId` bigint NOT NULL COMMENT '创建用户 id',
+  `prompt` varchar(1024) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '描述',
+  `parameters` varchar(1024) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '任务参数（JSON）',
+  `saveMode` varchar(16) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '结果保存方式：空-不保存; new-新图片; version-新版本',
+  `status` varchar(16) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'PENDING' COMMENT '状态：PENDING; RUNNING; SUCCEEDED; FAILED',
+  `attempts` int NOT NULL DEFAULT '0' COMMENT '已执行次数',
+  `nextRunTime` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '下次可执行时间',
+  `outputUrl` varchar(1024) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT 'AI 服务返回的结果图片 url',
+  `resultPictureId` bigint DEFAULT NULL COMMENT '保存到图库后的图片 id',
+  `errorMessage` varchar(512) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '失败原因',
+  `startTime` datetime DEFAULT NULL COMMENT '最近一次开始执行时间',
+  `finishTime` datetime DEFAULT NULL COMMENT '完成时间',
+  `createTime` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updateTime` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_status_nextRunTime` (`status`,`nextRunTime`),
+  KEY `idx_userId_status` (`userId`,`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='AI 图片任务';
+
 -- ----------------------------
 -- Table structure for picture
 -- ----------------------------
@@ -42,19 +69,127 @@ CREATE TABLE `picture` (
   `reviewMessage` varchar(512) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '审核信息',
   `reviewerId` bigint DEFAULT NULL COMMENT '审核人 ID',
   `reviewTime` datetime DEFAULT NULL COMMENT '审核时间',
+  `riskScore` int NOT NULL DEFAULT '0' COMMENT '风险分（0-100，自动审核给出）',
+  `moderationRule` varchar(64) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '决定审核结果的规则',
   `thumbnailUrl` varchar(512) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '缩略图 url',
   `spaceId` bigint DEFAULT NULL COMMENT '空间 id（为空表示公共空间）',
   `picColor` varchar(16) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '图片主色调',
+  `contentHash` char(64) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '图片内容 SHA-256',
+  `picHash` char(16) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '图片感知哈希（pHash）',
+  `sourcePictureId` bigint DEFAULT NULL COMMENT '来源图片 id（AI 生成结果）',
   PRIMARY KEY (`id`),
   KEY `idx_name` (`name`),
   KEY `idx_introduction` (`introduction`),
   KEY `idx_category` (`category`),
   KEY `idx_tags` (`tags`),
   KEY `idx_userId` (`userId`),
-  KEY `idx_reviewStatus` (`reviewStatus`),
-  KEY `idx_spaceId` (`spaceId`)
+  KEY `idx_reviewStatus_riskScore` (`reviewStatus`,`riskScore`),
+  KEY `idx_spaceId` (`spaceId`),
+  KEY `idx_contentHash` (`contentHash`),
+  KEY `idx_sourcePictureId` (`sourcePictureId`)
 ) ENGINE=InnoDB AUTO_INCREMENT=39 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='图片';
 
+-- ----------------------------
+-- Table structure for picture_blocklist
+-- ----------------------------
+DROP TABLE IF EXISTS `picture_blocklist`;
+CREATE TABLE `picture_blocklist` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'id',
+  `contentHash` varchar(64) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '文件内容哈希（SHA-256）',
+  `picHash` varchar(16) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '感知哈希，用于识别近似图片',
+  `reason` varchar(256) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '拉黑原因',
+  `pictureId` bigint DEFAULT NULL COMMENT '来源图片 id',
+  `userId` bigint NOT NULL COMMENT '操作管理员 id',
+  `createTime` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_contentHash` (`contentHash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='图片黑名单';
+
+-- ----------------------------
+-- Table structure for picture_review_log
+-- ----------------------------
+DROP TABLE IF EXISTS `picture_review_log`;
+CREATE TABLE `picture_review_log` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'id',
+  `pictureId` bigint NOT NULL COMMENT '图片 id',
+  `reviewerId` bigint NOT NULL DEFAULT '0' COMMENT '审核人 id（0 为自动审核）',
+  `oldStatus` int NOT NULL COMMENT '原审核状态',
+  `newStatus` int NOT NULL COMMENT '新审核状态',
+  `message` varchar(512) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '审核信息',
+  `source` varchar(16) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '来源：manual/batch/auto/blocklist/edit',
+  `rule` varchar(64) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '自动审核规则',
+  `riskScore` int NOT NULL DEFAULT '0' COMMENT '风险分',
+  `createTime` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_pictureId` (`pictureId`),
+  KEY `idx_reviewerId_createTime` (`reviewerId`,`createTime`),
+  KEY `idx_createTime` (`createTime`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='图片审核记录';
+
+-- ----------------------------
+-- Table structure for picture_exif
+-- ----------------------------
+DROP TABLE IF EXISTS `picture_exif`;
+CREATE TABLE `picture_exif` (
+  `pictureId` bigint NOT NULL COMMENT '图片 id',
+  `make` varchar(128) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '相机厂商',
+  `model` varchar(128) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '相机型号',
+  `lensModel` varchar(128) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '镜头型号',
+  `exposureTime` varchar(32) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '曝光时间，如 1/125',
+  `fNumber` double DEFAULT NULL COMMENT '光圈值',
+  `iso` int DEFAULT NULL COMMENT 'ISO 感光度',
+  `focalLength` double DEFAULT NULL COMMENT '焦距（mm）',
+  `captureTime` datetime DEFAULT NULL COMMENT '拍摄时间',
+  `orientation` int DEFAULT NULL COMMENT '方向（EXIF Orientation 1-8）',
+  `latitude` double DEFAULT NULL COMMENT '纬度',
+  `longitude` double DEFAULT NULL COMMENT '经度',
+  `createTime` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`pictureId`),
+  KEY `idx_captureTime` (`captureTime`),
+  KEY `idx_make_model` (`make`,`model`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='图片EXIF信息';
+
+-- ----------------------------
+-- Table structure for picture_version
+-- ----------------------------
+DROP TABLE IF EXISTS `picture_version`;
+CREATE TABLE `picture_version` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'id',
+  `pictureId` bigint NOT NULL COMMENT '图片 id',
+  `version` int NOT NULL COMMENT '版本号（同一图片内递增）',
+  `action` varchar(32) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '变更类型',
+  `changedFields` varchar(512) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '变更的字段（JSON 数组）',
+  `snapshot` text COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '变更后的图片快照（JSON）',
+  `url` varchar(512) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '该版本的图片 url',
+  `remark` varchar(256) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '备注',
+  `userId` bigint NOT NULL COMMENT '操作用户 id',
+  `createTime` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_pictureId_version` (`pictureId`,`version`),
+  KEY `idx_url` (`url`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='图片版本历史';
+
+-- ----------------------------
+-- Table structure for picture_suggestion
+-- ----------------------------
+DROP TABLE IF EXISTS `picture_suggestion`;
+CREATE TABLE `picture_suggestion` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'id',
+  `pictureId` bigint NOT NULL COMMENT '图片 id',
+  `userId` bigint NOT NULL COMMENT '图片所有者 id',
+  `spaceId` bigint NOT NULL DEFAULT '0' COMMENT '空间 id',
+  `tags` varchar(512) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '建议的标签（JSON 数组）',
+  `category` varchar(64) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '建议的分类',
+  `introduction` varchar(512) COLLATE utf8mb4_unicode_ci DEFAULT NULL COMMENT '建议的简介',
+  `provider` varchar(32) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '生成建议的服务',
+  `status` tinyint NOT NULL DEFAULT '0' COMMENT '状态：0-待处理; 1-已采纳; 2-已忽略',
+  `createTime` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updateTime` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_pictureId` (`pictureId`),
+  KEY `idx_userId_status` (`userId`,`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='图片智能标注建议';
+
 -- ----------------------------
 -- Table structure for space
 -- ----------------------------
@@ -73,6 +208,7 @@ CREATE TABLE `space` (
   `updateTime` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
   `isDelete` tinyint NOT NULL DEFAULT '0' COMMENT '是否删除',
   `spaceType` int NOT NULL DEFAULT '0' COMMENT '空间类型：0-私有 1-团队',
+  `stripMetadata` tinyint NOT NULL DEFAULT '1' COMMENT '入库时是否去除GPS等隐私元数据：0-保留 1-去除',
   PRIMARY KEY (`id`),
   KEY `idx_userId` (`userId`),
   KEY `idx_spaceName` (`spaceName`),
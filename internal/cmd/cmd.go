@@ -3,11 +3,13 @@ package cmd
 import (
 	"cloud/internal/controller"
 	"cloud/internal/middleware"
+	"cloud/internal/service"
 	"context"
 
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/net/ghttp"
 	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/gogf/gf/v2/os/gcron"
 )
 
 var (
@@ -22,6 +24,12 @@ var (
 			// 统一响应中间件
 			// s.Use(middleware.Response)
 			s.Use(ghttp.MiddlewareHandlerResponse)
+			// 本地存储驱动：由服务器直接提供已上传文件的访问
+			if servePath, root := service.Bucket().LocalStaticPath(); servePath != "" {
+				s.AddStaticPath(servePath, root)
+				// 私有/团队空间的文件需校验签名后才能访问
+				s.BindHookHandler(servePath+"/*", ghttp.HookBeforeServe, middleware.SignedFile)
+			}
 
 			// API路由组
 			s.Group("/api", func(group *ghttp.RouterGroup) {
@@ -59,6 +67,15 @@ var (
 						group.POST("/", controller.Picture.Upload)
 						group.POST("/url", controller.Picture.UploadByUrl)
 						group.POST("/batch", controller.Picture.UploadByBatch)
+						// 分片上传（断点续传）
+						group.POST("/chunk/init", controller.Picture.InitUpload)
+						group.POST("/chunk", controller.Picture.UploadChunk)
+						group.GET("/chunk/status", controller.Picture.GetUploadStatus)
+						group.POST("/chunk/complete", controller.Picture.CompleteUpload)
+						group.POST("/chunk/abort", controller.Picture.AbortUpload)
+						// 预签名直传（S3、COS驱动）
+						group.POST("/presign", controller.Picture.PresignUpload)
+						group.POST("/presign/finalize", controller.Picture.FinalizeUpload)
 					})
 					// 获取图片标签分类
 					group.GET("/tag_category", controller.Picture.TagCategory)
@@ -72,6 +89,31 @@ var (
 					group.POST("/delete", controller.Picture.Delete)
 					group.GET("/get", controller.Picture.Get)
 					group.GET("/get/vo", controller.Picture.GetVO)
+					// 图片实时转换（缩放、裁剪、格式、质量）
+					group.Group("/", func(group *ghttp.RouterGroup) {
+						group.Middleware(middleware.Auth)
+						group.GET("/{id}/render", controller.Picture.Render)
+					})
+					// 版本历史
+					group.Group("/version", func(group *ghttp.RouterGroup) {
+						group.Middleware(middleware.Auth)
+						group.POST("/list", controller.Picture.ListVersions)
+						group.POST("/rollback", controller.Picture.RollbackVersion)
+					})
+					// 智能标注建议
+					group.Group("/suggestion", func(group *ghttp.RouterGroup) {
+						group.Middleware(middleware.Auth)
+						group.POST("/list", controller.Picture.ListSuggestions)
+						group.POST("/generate", controller.Picture.GenerateSuggestions)
+						group.POST("/accept", controller.Picture.AcceptSuggestions)
+						group.POST("/dismiss", controller.Picture.DismissSuggestions)
+					})
+					// 回收站
+					group.Group("/recycle", func(group *ghttp.RouterGroup) {
+						group.Middleware(middleware.Auth)
+						group.POST("/list/page", controller.Picture.ListRecycleByPage)
+						group.POST("/restore", controller.Picture.Restore)
+					})
 					// 分页查询
 					group.POST("/list/page", controller.Picture.ListByPage)
 					group.POST("/list/page/vo", controller.Picture.ListVOByPage)
@@ -89,6 +131,13 @@ var (
 					group.Group("/out_painting", func(group *ghttp.RouterGroup) {
 						group.Middleware(middleware.Auth)
 						group.POST("/create", controller.Picture.CreateOutPainting)
+						group.GET("/get", controller.Picture.GetAIEditingTask)
+					})
+					// AI编辑功能（与扩图共用任务查询）
+					group.Group("/ai_editing", func(group *ghttp.RouterGroup) {
+						group.Middleware(middleware.Auth)
+						group.POST("/create", controller.Picture.CreateAIEditingTask)
+						group.GET("/get", controller.Picture.GetAIEditingTask)
 					})
 
 					// 管理员功能
@@ -96,6 +145,16 @@ var (
 						group.Middleware(middleware.AdminAuth)
 						// 图片审核
 						group.POST("/review", controller.Picture.Review)
+						group.POST("/review/batch", controller.Picture.ReviewByBatch)
+						group.POST("/review/pending", controller.Picture.ListPendingReview)
+						group.POST("/review/log", controller.Picture.ListReviewLogs)
+						group.POST("/review/stats", controller.Picture.ReviewStats)
+						// 近似重复图片报告
+						group.POST("/duplicate/clusters", controller.Picture.DuplicateClusters)
+						// 图片黑名单（自动审核比对）
+						group.POST("/blocklist/add", controller.Picture.AddBlocklist)
+						group.POST("/blocklist/list", controller.Picture.ListBlocklist)
+						group.POST("/blocklist/delete", controller.Picture.DeleteBlocklist)
 					})
 				})
 
@@ -151,8 +210,43 @@ var (
 					group.Middleware(middleware.Auth)
 					// 图片协同编辑 WebSocket
 					group.GET("/picture/edit", controller.WebSocket.WebSocketPictureEdit)
+					// AI任务完成通知 WebSocket
+					group.GET("/ai/task", controller.WebSocket.WebSocketAITask)
 				})
 			})
+			// 定时重试删除失败的对象
+			if _, err = gcron.AddSingleton(ctx, "@every 5m", func(ctx context.Context) {
+				service.Bucket().RetryFailedDeletes(ctx)
+			}, "bucket-delete-retry"); err != nil {
+				return err
+			}
+			// 定时清理超过保留期的回收站图片
+			if _, err = gcron.AddSingleton(ctx, "@every 1h", func(ctx context.Context) {
+				service.Picture().PurgeRecycleBin(ctx)
+			}, "picture-recycle-purge"); err != nil {
+				return err
+			}
+			// 定时清理过期的分片上传临时文件与超时未完成的直传对象
+			if _, err = gcron.AddSingleton(ctx, "@every 30m", func(ctx context.Context) {
+				service.Picture().CleanupUploadSessions(ctx)
+			}, "picture-upload-cleanup"); err != nil {
+				return err
+			}
+			// 定时处理执行超时的AI任务
+			if _, err = gcron.AddSingleton(ctx, "@every 5m", func(ctx context.Context) {
+				service.Picture().RecoverAITasks(ctx)
+			}, "picture-ai-task-recover"); err != nil {
+				return err
+			}
+			// 定时删除本地AI服务替身生成的过期结果
+			if _, err = gcron.AddSingleton(ctx, "@every 1h", func(ctx context.Context) {
+				service.Picture().CleanupStubOutputs(ctx)
+			}, "picture-ai-stub-cleanup"); err != nil {
+				return err
+			}
+			// 启动AI任务处理协程
+			service.Picture().StartAITaskWorkers(ctx)
+
 			s.Run()
 			return nil
 		},
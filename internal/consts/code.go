@@ -0,0 +1,8 @@
+package consts
+
+import "github.com/gogf/gf/v2/errors/gcode"
+
+var (
+	// CodeSpaceQuotaExceeded 空间额度不足（容量或数量超出空间级别限制）
+	CodeSpaceQuotaExceeded = gcode.New(40010, "空间额度不足", nil)
+)
@@ -18,4 +18,19 @@ const (
 	SpaceRoleViewer  = "viewer"
 	SpaceRoleEditor  = "editor"
 	SpaceRoleAdmin   = "admin"
+
+	StorageDriver      = "storage.driver"
+	StorageDriverLocal = "local"
+	StorageDriverS3    = "s3"
+	StorageDriverCos   = "cos"
+
+	AiProvider           = "ai.provider"
+	AiProviderVolcengine = "volcengine"
+	AiProviderStub       = "stub"
+
+	AiVisionProvider  = "ai.vision.provider"
+	AiVisionHeuristic = "heuristic"
+
+	PictureRecycleRetention = "picture.recycleRetention"
+	PictureStripMetadata    = "picture.stripMetadata"
 )
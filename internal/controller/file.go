@@ -3,7 +3,9 @@ package controller
 import (
 	v1 "cloud/api/user/v1"
 	"cloud/internal/service"
+	"cloud/utility/safefetch"
 	"context"
+	"errors"
 	"io"
 	"net/http"
 
@@ -42,21 +44,27 @@ func (f *cFile) Download(ctx context.Context, in *v1.BucketDownloadlReq) (out *v
 		return nil, gerror.NewCode(gcode.CodeMissingParameter, "URL参数不能为空")
 	}
 
-	// 创建HTTP客户端请求
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", fileURL, nil)
+	// 使用防SSRF的下载客户端，禁止代理访问内网、回环等地址
+	client, err := safefetch.Default(ctx)
 	if err != nil {
-		glog.Error(r.Context(), "创建请求失败:", err)
+		glog.Error(r.Context(), "初始化下载客户端失败:", err)
 		return nil, gerror.NewCode(gcode.CodeInternalError, "创建请求失败")
 	}
 
 	// 设置请求头
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	header := http.Header{}
+	header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
 
 	// 发送请求
-	resp, err := client.Do(req)
+	resp, err := client.Get(ctx, fileURL, header)
 	if err != nil {
 		glog.Error(r.Context(), "请求远程文件失败:", err)
+		if errors.Is(err, safefetch.ErrBlocked) {
+			return nil, gerror.NewCode(gcode.CodeNotAuthorized, "不允许访问该地址")
+		}
+		if errors.Is(err, safefetch.ErrTooLarge) {
+			return nil, gerror.NewCode(gcode.CodeInvalidParameter, "远程文件过大")
+		}
 		return nil, gerror.NewCode(gcode.CodeInternalError, "无法获取远程文件")
 	}
 	defer resp.Body.Close()
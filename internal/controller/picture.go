@@ -38,6 +38,49 @@ func (c *cPicture) Upload(ctx context.Context, req *v1.PictureUploadReq) (res *v
 	return service.Picture().Upload(ctx, req, file)
 }
 
+// InitUpload 初始化分片上传
+func (c *cPicture) InitUpload(ctx context.Context, req *v1.PictureUploadInitReq) (res *v1.PictureUploadInitRes, err error) {
+	return service.Picture().InitUpload(ctx, req)
+}
+
+// UploadChunk 上传分片
+func (c *cPicture) UploadChunk(ctx context.Context, req *v1.PictureUploadChunkReq) (res *v1.PictureUploadChunkRes, err error) {
+	r := ghttp.RequestFromCtx(ctx)
+
+	// 获取上传的分片
+	file := r.GetUploadFile("chunk")
+	if file == nil {
+		return nil, gerror.NewCode(gcode.CodeMissingParameter, "分片不能为空")
+	}
+
+	return service.Picture().UploadChunk(ctx, req, file)
+}
+
+// GetUploadStatus 查询分片上传进度
+func (c *cPicture) GetUploadStatus(ctx context.Context, req *v1.PictureUploadSessionReq) (res *v1.PictureUploadStatusRes, err error) {
+	return service.Picture().GetUploadStatus(ctx, req)
+}
+
+// CompleteUpload 完成分片上传
+func (c *cPicture) CompleteUpload(ctx context.Context, req *v1.PictureUploadSessionReq) (res *v1.PictureUploadRes, err error) {
+	return service.Picture().CompleteUpload(ctx, req)
+}
+
+// AbortUpload 取消分片上传
+func (c *cPicture) AbortUpload(ctx context.Context, req *v1.PictureUploadSessionReq) (res *v1.PictureUploadAbortRes, err error) {
+	return service.Picture().AbortUpload(ctx, req)
+}
+
+// PresignUpload 申请直传地址
+func (c *cPicture) PresignUpload(ctx context.Context, req *v1.PictureUploadPresignReq) (res *v1.PictureUploadPresignRes, err error) {
+	return service.Picture().PresignUpload(ctx, req)
+}
+
+// FinalizeUpload 完成直传
+func (c *cPicture) FinalizeUpload(ctx context.Context, req *v1.PictureUploadFinalizeReq) (res *v1.PictureUploadRes, err error) {
+	return service.Picture().FinalizeUpload(ctx, req)
+}
+
 // UploadByUrl 通过URL上传图片
 func (c *cPicture) UploadByUrl(ctx context.Context, req *v1.PictureUploadByUrlReq) (res *v1.PictureUploadByUrlRes, err error) {
 	return service.Picture().UploadByUrl(ctx, req)
@@ -48,11 +91,103 @@ func (c *cPicture) Delete(ctx context.Context, req *v1.DeleteReq) (res *v1.Delet
 	return service.Picture().Delete(ctx, req)
 }
 
+// ListRecycleByPage 分页查询回收站图片
+func (c *cPicture) ListRecycleByPage(ctx context.Context, req *v1.PictureRecycleQueryReq) (res *v1.PictureRecycleQueryRes, err error) {
+	return service.Picture().ListRecycleByPage(ctx, req)
+}
+
+// Restore 从回收站恢复图片
+func (c *cPicture) Restore(ctx context.Context, req *v1.PictureRestoreReq) (res *v1.PictureRestoreRes, err error) {
+	return service.Picture().Restore(ctx, req)
+}
+
 // Get 获取图片详情
 func (c *cPicture) Get(ctx context.Context, req *v1.PictureGetReq) (res *v1.PictureAdminGetRes, err error) {
 	return service.Picture().Get(ctx, req)
 }
 
+// Render 图片实时转换，直接输出图片内容
+func (c *cPicture) Render(ctx context.Context, req *v1.PictureRenderReq) (res *v1.PictureRenderRes, err error) {
+	res, err = service.Picture().Render(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	r := ghttp.RequestFromCtx(ctx)
+	r.Response.Header().Set("Content-Type", res.ContentType)
+	if res.Private {
+		r.Response.Header().Set("Cache-Control", "private, max-age=3600")
+	} else {
+		r.Response.Header().Set("Cache-Control", "public, max-age=86400")
+	}
+	r.Response.Write(res.Data)
+	return nil, nil
+}
+
+// ListVersions 分页查询图片版本历史
+func (c *cPicture) ListVersions(ctx context.Context, req *v1.PictureVersionListReq) (res *v1.PictureVersionListRes, err error) {
+	return service.Picture().ListVersions(ctx, req)
+}
+
+// RollbackVersion 回滚图片到历史版本
+func (c *cPicture) RollbackVersion(ctx context.Context, req *v1.PictureVersionRollbackReq) (res *v1.PictureVersionRollbackRes, err error) {
+	return service.Picture().RollbackVersion(ctx, req)
+}
+
+// ListSuggestions 分页查询智能标注建议
+func (c *cPicture) ListSuggestions(ctx context.Context, req *v1.PictureSuggestionListReq) (res *v1.PictureSuggestionListRes, err error) {
+	return service.Picture().ListSuggestions(ctx, req)
+}
+
+// GenerateSuggestions 重新生成智能标注建议
+func (c *cPicture) GenerateSuggestions(ctx context.Context, req *v1.PictureSuggestionGenerateReq) (res *v1.PictureSuggestionGenerateRes, err error) {
+	return service.Picture().GenerateSuggestions(ctx, req)
+}
+
+// AcceptSuggestions 批量采纳智能标注建议
+func (c *cPicture) AcceptSuggestions(ctx context.Context, req *v1.PictureSuggestionAcceptReq) (res *v1.PictureSuggestionAcceptRes, err error) {
+	return service.Picture().AcceptSuggestions(ctx, req)
+}
+
+// DismissSuggestions 批量忽略智能标注建议
+func (c *cPicture) DismissSuggestions(ctx context.Context, req *v1.PictureSuggestionDismissReq) (res *v1.PictureSuggestionDismissRes, err error) {
+	return service.Picture().DismissSuggestions(ctx, req)
+}
+
+// AddBlocklist 将图片加入黑名单
+func (c *cPicture) AddBlocklist(ctx context.Context, req *v1.PictureBlocklistAddReq) (res *v1.PictureBlocklistAddRes, err error) {
+	return service.Picture().AddBlocklist(ctx, req)
+}
+
+// ListBlocklist 分页查询图片黑名单
+func (c *cPicture) ListBlocklist(ctx context.Context, req *v1.PictureBlocklistListReq) (res *v1.PictureBlocklistListRes, err error) {
+	return service.Picture().ListBlocklist(ctx, req)
+}
+
+// DeleteBlocklist 移出图片黑名单
+func (c *cPicture) DeleteBlocklist(ctx context.Context, req *v1.PictureBlocklistDeleteReq) (res *v1.PictureBlocklistDeleteRes, err error) {
+	return service.Picture().DeleteBlocklist(ctx, req)
+}
+
+// ReviewByBatch 批量审核图片
+func (c *cPicture) ReviewByBatch(ctx context.Context, req *v1.PictureReviewBatchReq) (res *v1.PictureReviewBatchRes, err error) {
+	return service.Picture().ReviewByBatch(ctx, req)
+}
+
+// ListReviewLogs 分页查询审核记录
+func (c *cPicture) ListReviewLogs(ctx context.Context, req *v1.PictureReviewLogListReq) (res *v1.PictureReviewLogListRes, err error) {
+	return service.Picture().ListReviewLogs(ctx, req)
+}
+
+// ListPendingReview 分页查询待审核队列
+func (c *cPicture) ListPendingReview(ctx context.Context, req *v1.PictureReviewPendingReq) (res *v1.PictureReviewPendingRes, err error) {
+	return service.Picture().ListPendingReview(ctx, req)
+}
+
+// ReviewStats 统计审核人工作量
+func (c *cPicture) ReviewStats(ctx context.Context, req *v1.PictureReviewStatsReq) (res *v1.PictureReviewStatsRes, err error) {
+	return service.Picture().ReviewStats(ctx, req)
+}
+
 // GetVO 获取图片详情VO
 func (c *cPicture) GetVO(ctx context.Context, req *v1.PictureGetReq) (res *v1.PictureGetRes, err error) {
 	return service.Picture().GetVO(ctx, req)
@@ -93,6 +228,11 @@ func (c *cPicture) SearchByPicture(ctx context.Context, req *v1.SearchPictureByP
 	return service.Picture().SearchByPicture(ctx, req)
 }
 
+// DuplicateClusters 近似重复图片聚类报告
+func (c *cPicture) DuplicateClusters(ctx context.Context, req *v1.PictureDuplicateClusterReq) (res *v1.PictureDuplicateClusterRes, err error) {
+	return service.Picture().DuplicateClusters(ctx, req)
+}
+
 // SearchByColor 按颜色搜索图片
 func (c *cPicture) SearchByColor(ctx context.Context, req *v1.SearchPictureByColorReq) (res []v1.SearchPictureByColorRes, err error) {
 	return service.Picture().SearchByColor(ctx, req)
@@ -21,3 +21,13 @@ func (c *cWebSocket) WebSocketPictureEdit(ctx context.Context, req *v1.WebSocket
 	service.WebSocket().PictureEdit(ctx, r, req)
 	return &v1.WebSocketPictureEditRes{}, nil
 }
+
+// WebSocketAITask AI任务完成通知
+func (c *cWebSocket) WebSocketAITask(ctx context.Context, req *v1.WebSocketAITaskReq) (res *v1.WebSocketAITaskRes, err error) {
+	r := g.RequestFromCtx(ctx)
+	if r == nil {
+		return nil, gerror.New("无法获取请求对象")
+	}
+	service.WebSocket().AITaskNotify(ctx, r)
+	return &v1.WebSocketAITaskRes{}, nil
+}
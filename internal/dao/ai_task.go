@@ -0,0 +1,22 @@
+// =================================================================================
+// This bucket is auto-generated by the GoFrame CLI tool. You may modify it as needed.
+// =================================================================================
+
+package dao
+
+import (
+	"cloud/internal/dao/internal"
+)
+
+// aiTaskDao is the data access object for the table ai_task.
+// You can define custom methods on it to extend its functionality as needed.
+type aiTaskDao struct {
+	*internal.AiTaskDao
+}
+
+var (
+	// AiTask is a globally accessible object for table ai_task operations.
+	AiTask = aiTaskDao{internal.NewAiTaskDao()}
+)
+
+// Add your custom methods and functionality below.
@@ -21,56 +21,66 @@ type PictureDao struct {
 
 // PictureColumns defines and stores column names for the table picture.
 type PictureColumns struct {
-	Id            string // id
-	Url           string // 图片 url
-	Name          string // 图片名称
-	Introduction  string // 简介
-	Category      string // 分类
-	Tags          string // 标签（JSON 数组）
-	PicSize       string // 图片体积
-	PicWidth      string // 图片宽度
-	PicHeight     string // 图片高度
-	PicScale      string // 图片宽高比例
-	PicFormat     string // 图片格式
-	UserId        string // 创建用户 id
-	CreateTime    string // 创建时间
-	EditTime      string // 编辑时间
-	UpdateTime    string // 更新时间
-	IsDelete      string // 是否删除
-	ReviewStatus  string // 审核状态：0-待审核; 1-通过; 2-拒绝
-	ReviewMessage string // 审核信息
-	ReviewerId    string // 审核人 ID
-	ReviewTime    string // 审核时间
-	ThumbnailUrl  string // 缩略图 url
-	SpaceId       string // 空间 id（为空表示公共空间）
-	PicColor      string // 图片主色调
+	Id              string // id
+	Url             string // 图片 url
+	Name            string // 图片名称
+	Introduction    string // 简介
+	Category        string // 分类
+	Tags            string // 标签（JSON 数组）
+	PicSize         string // 图片体积
+	PicWidth        string // 图片宽度
+	PicHeight       string // 图片高度
+	PicScale        string // 图片宽高比例
+	PicFormat       string // 图片格式
+	UserId          string // 创建用户 id
+	CreateTime      string // 创建时间
+	EditTime        string // 编辑时间
+	UpdateTime      string // 更新时间
+	IsDelete        string // 是否删除
+	ReviewStatus    string // 审核状态：0-待审核; 1-通过; 2-拒绝
+	ReviewMessage   string // 审核信息
+	ReviewerId      string // 审核人 ID
+	ReviewTime      string // 审核时间
+	RiskScore       string // 风险分（0-100，自动审核给出）
+	ModerationRule  string // 决定审核结果的规则
+	ThumbnailUrl    string // 缩略图 url
+	SpaceId         string // 空间 id（为空表示公共空间）
+	PicColor        string // 图片主色调
+	ContentHash     string // 图片内容 SHA-256
+	PicHash         string // 图片感知哈希（pHash）
+	SourcePictureId string // 来源图片 id（AI 生成结果）
 }
 
 // pictureColumns holds the columns for the table picture.
 var pictureColumns = PictureColumns{
-	Id:            "id",
-	Url:           "url",
-	Name:          "name",
-	Introduction:  "introduction",
-	Category:      "category",
-	Tags:          "tags",
-	PicSize:       "picSize",
-	PicWidth:      "picWidth",
-	PicHeight:     "picHeight",
-	PicScale:      "picScale",
-	PicFormat:     "picFormat",
-	UserId:        "userId",
-	CreateTime:    "createTime",
-	EditTime:      "editTime",
-	UpdateTime:    "updateTime",
-	IsDelete:      "isDelete",
-	ReviewStatus:  "reviewStatus",
-	ReviewMessage: "reviewMessage",
-	ReviewerId:    "reviewerId",
-	ReviewTime:    "reviewTime",
-	ThumbnailUrl:  "thumbnailUrl",
-	SpaceId:       "spaceId",
-	PicColor:      "picColor",
+	Id:              "id",
+	Url:             "url",
+	Name:            "name",
+	Introduction:    "introduction",
+	Category:        "category",
+	Tags:            "tags",
+	PicSize:         "picSize",
+	PicWidth:        "picWidth",
+	PicHeight:       "picHeight",
+	PicScale:        "picScale",
+	PicFormat:       "picFormat",
+	UserId:          "userId",
+	CreateTime:      "createTime",
+	EditTime:        "editTime",
+	UpdateTime:      "updateTime",
+	IsDelete:        "isDelete",
+	ReviewStatus:    "reviewStatus",
+	ReviewMessage:   "reviewMessage",
+	ReviewerId:      "reviewerId",
+	ReviewTime:      "reviewTime",
+	RiskScore:       "riskScore",
+	ModerationRule:  "moderationRule",
+	ThumbnailUrl:    "thumbnailUrl",
+	SpaceId:         "spaceId",
+	PicColor:        "picColor",
+	ContentHash:     "contentHash",
+	PicHash:         "picHash",
+	SourcePictureId: "sourcePictureId",
 }
 
 // NewPictureDao creates and returns a new DAO object for table data access.
@@ -21,36 +21,38 @@ type SpaceDao struct {
 
 // SpaceColumns defines and stores column names for the table space.
 type SpaceColumns struct {
-	Id         string // id
-	SpaceName  string // 空间名称
-	SpaceLevel string // 空间级别：0-普通版 1-专业版 2-旗舰版
-	MaxSize    string // 空间图片的最大总大小
-	MaxCount   string // 空间图片的最大数量
-	TotalSize  string // 当前空间下图片的总大小
-	TotalCount string // 当前空间下的图片数量
-	UserId     string // 创建用户 id
-	CreateTime string // 创建时间
-	EditTime   string // 编辑时间
-	UpdateTime string // 更新时间
-	IsDelete   string // 是否删除
-	SpaceType  string // 空间类型：0-私有 1-团队
+	Id            string // id
+	SpaceName     string // 空间名称
+	SpaceLevel    string // 空间级别：0-普通版 1-专业版 2-旗舰版
+	MaxSize       string // 空间图片的最大总大小
+	MaxCount      string // 空间图片的最大数量
+	TotalSize     string // 当前空间下图片的总大小
+	TotalCount    string // 当前空间下的图片数量
+	UserId        string // 创建用户 id
+	CreateTime    string // 创建时间
+	EditTime      string // 编辑时间
+	UpdateTime    string // 更新时间
+	IsDelete      string // 是否删除
+	SpaceType     string // 空间类型：0-私有 1-团队
+	StripMetadata string // 入库时是否去除GPS等隐私元数据：0-保留 1-去除
 }
 
 // spaceColumns holds the columns for the table space.
 var spaceColumns = SpaceColumns{
-	Id:         "id",
-	SpaceName:  "spaceName",
-	SpaceLevel: "spaceLevel",
-	MaxSize:    "maxSize",
-	MaxCount:   "maxCount",
-	TotalSize:  "totalSize",
-	TotalCount: "totalCount",
-	UserId:     "userId",
-	CreateTime: "createTime",
-	EditTime:   "editTime",
-	UpdateTime: "updateTime",
-	IsDelete:   "isDelete",
-	SpaceType:  "spaceType",
+	Id:            "id",
+	SpaceName:     "spaceName",
+	SpaceLevel:    "spaceLevel",
+	MaxSize:       "maxSize",
+	MaxCount:      "maxCount",
+	TotalSize:     "totalSize",
+	TotalCount:    "totalCount",
+	UserId:        "userId",
+	CreateTime:    "createTime",
+	EditTime:      "editTime",
+	UpdateTime:    "updateTime",
+	IsDelete:      "isDelete",
+	SpaceType:     "spaceType",
+	StripMetadata: "stripMetadata",
 }
 
 // NewSpaceDao creates and returns a new DAO object for table data access.
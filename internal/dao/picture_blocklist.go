@@ -0,0 +1,22 @@
+// =================================================================================
+// This bucket is auto-generated by the GoFrame CLI tool. You may modify it as needed.
+// =================================================================================
+
+package dao
+
+import (
+	"cloud/internal/dao/internal"
+)
+
+// pictureBlocklistDao is the data access object for the table picture_blocklist.
+// You can define custom methods on it to extend its functionality as needed.
+type pictureBlocklistDao struct {
+	*internal.PictureBlocklistDao
+}
+
+var (
+	// PictureBlocklist is a globally accessible object for table picture_blocklist operations.
+	PictureBlocklist = pictureBlocklistDao{internal.NewPictureBlocklistDao()}
+)
+
+// Add your custom methods and functionality below.
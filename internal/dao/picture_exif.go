@@ -0,0 +1,22 @@
+// =================================================================================
+// This bucket is auto-generated by the GoFrame CLI tool. You may modify it as needed.
+// =================================================================================
+
+package dao
+
+import (
+	"cloud/internal/dao/internal"
+)
+
+// pictureExifDao is the data access object for the table picture_exif.
+// You can define custom methods on it to extend its functionality as needed.
+type pictureExifDao struct {
+	*internal.PictureExifDao
+}
+
+var (
+	// PictureExif is a globally accessible object for table picture_exif operations.
+	PictureExif = pictureExifDao{internal.NewPictureExifDao()}
+)
+
+// Add your custom methods and functionality below.
@@ -0,0 +1,22 @@
+// =================================================================================
+// This bucket is auto-generated by the GoFrame CLI tool. You may modify it as needed.
+// =================================================================================
+
+package dao
+
+import (
+	"cloud/internal/dao/internal"
+)
+
+// pictureReviewLogDao is the data access object for the table picture_review_log.
+// You can define custom methods on it to extend its functionality as needed.
+type pictureReviewLogDao struct {
+	*internal.PictureReviewLogDao
+}
+
+var (
+	// PictureReviewLog is a globally accessible object for table picture_review_log operations.
+	PictureReviewLog = pictureReviewLogDao{internal.NewPictureReviewLogDao()}
+)
+
+// Add your custom methods and functionality below.
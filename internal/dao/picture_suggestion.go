@@ -0,0 +1,22 @@
+// =================================================================================
+// This bucket is auto-generated by the GoFrame CLI tool. You may modify it as needed.
+// =================================================================================
+
+package dao
+
+import (
+	"cloud/internal/dao/internal"
+)
+
+// pictureSuggestionDao is the data access object for the table picture_suggestion.
+// You can define custom methods on it to extend its functionality as needed.
+type pictureSuggestionDao struct {
+	*internal.PictureSuggestionDao
+}
+
+var (
+	// PictureSuggestion is a globally accessible object for table picture_suggestion operations.
+	PictureSuggestion = pictureSuggestionDao{internal.NewPictureSuggestionDao()}
+)
+
+// Add your custom methods and functionality below.
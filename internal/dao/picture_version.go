@@ -0,0 +1,22 @@
+// =================================================================================
+// This bucket is auto-generated by the GoFrame CLI tool. You may modify it as needed.
+// =================================================================================
+
+package dao
+
+import (
+	"cloud/internal/dao/internal"
+)
+
+// pictureVersionDao is the data access object for the table picture_version.
+// You can define custom methods on it to extend its functionality as needed.
+type pictureVersionDao struct {
+	*internal.PictureVersionDao
+}
+
+var (
+	// PictureVersion is a globally accessible object for table picture_version operations.
+	PictureVersion = pictureVersionDao{internal.NewPictureVersionDao()}
+)
+
+// Add your custom methods and functionality below.
@@ -1,44 +1,43 @@
 package bucket
 
 import (
+	"bytes"
 	v1 "cloud/api/user/v1"
-	"cloud/internal/consts"
 	"cloud/internal/service"
 	"context"
-	"net/http"
+	"io"
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gfile"
 	"github.com/gogf/gf/v2/os/gtime"
 	"github.com/gogf/gf/v2/util/gconv"
 	"github.com/gogf/gf/v2/util/guid"
-	"github.com/tencentyun/cos-go-sdk-v5"
 )
 
 func init() {
 	service.RegisterBucket(New())
 }
 
+// deleteRetryKey 删除失败待重试的对象key集合
+const deleteRetryKey = "bucket:delete:retry"
+
 type sBucket struct {
-	Cli *cos.Client
+	driver storageDriver
 }
 
 func New() *sBucket {
 	ctx := context.Background()
-	// 1. 检查文件是否存在
-	u, _ := url.Parse(consts.BucketURL)
-	b := &cos.BaseURL{BucketURL: u}
-	cli := cos.NewClient(b, &http.Client{
-		Transport: &cos.AuthorizationTransport{
-			SecretID:  g.Cfg().MustGet(ctx, consts.SecretId).String(),
-			SecretKey: g.Cfg().MustGet(ctx, consts.SecretKey).String(),
-		},
-	})
+	driver, err := newDriver(ctx)
+	if err != nil {
+		g.Log().Fatalf(ctx, "初始化对象存储驱动失败: %v", err)
+	}
 	return &sBucket{
-		Cli: cli,
+		driver: driver,
 	}
 }
 
@@ -50,16 +49,11 @@ func (s *sBucket) Upload(ctx context.Context, in *v1.BucketUploadReq) (res *v1.B
 		g.Log().Errorf(ctx, "打开上传文件失败: %v", err)
 		return nil, gerror.New("上传文件失败")
 	}
-	// 根据 spaceId 决定前缀：有 spaceId -> space/<id>/，否则 Public/
-	var prefix string
-	if in.SpaceId > 0 {
-		prefix = "space/" + strings.TrimSpace(gconv.String(in.SpaceId)) + "/"
-	} else {
-		prefix = "Public/"
-	}
-	objectKey := prefix + fileName
-	if _, err = s.Cli.Object.Put(ctx, objectKey, f, nil); err != nil {
-		g.Log().Errorf(ctx, "上传到COS失败: %v", err)
+	// 对象key使用 日期_UUID 生成，避免同名文件互相覆盖
+	objectKey := s.NewObjectKey(in.SpaceId, fileName)
+	defer f.Close()
+	if err = s.driver.Put(ctx, objectKey, f, in.File.Size, in.File.Header.Get("Content-Type")); err != nil {
+		g.Log().Errorf(ctx, "上传到对象存储失败: %v", err)
 		return nil, gerror.New("上传文件失败")
 	}
 	return &v1.BucketUploadRes{FileAddress: objectKey}, nil
@@ -69,74 +63,169 @@ func (s *sBucket) Upload(ctx context.Context, in *v1.BucketUploadReq) (res *v1.B
 func (s *sBucket) UploadByUrl(ctx context.Context, in *v1.BucketUploadByUrlReq) (res *v1.BucketUploadByUrlRes, err error) {
 	g.Log().Debugf(ctx, "URL上传: %s", in.FileUrl)
 
-	// 从URL下载文件
-	resp, err := http.Get(in.FileUrl)
+	// 从URL下载文件（限制大小，文件大小按实际读取的字节数计算）
+	data, contentType, err := s.Fetch(ctx, in.FileUrl)
 	if err != nil {
-		g.Log().Errorf(ctx, "下载文件失败: %v", err)
-		return nil, gerror.New("下载文件失败")
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		g.Log().Errorf(ctx, "下载文件失败，状态码: %d", resp.StatusCode)
-		return nil, gerror.New("下载文件失败")
+	// 对象key始终使用 日期_UUID 生成，避免同名文件互相覆盖；指定的文件名只作为展示名称
+	objectName := s.generateFileName(in.FileUrl)
+	fileName := in.FileName
+	if fileName == "" {
+		fileName = objectName
 	}
 
-	// 获取文件大小
-	fileSize := resp.ContentLength
-	if fileSize < 0 {
-		// 如果Content-Length头不存在或为负数，设为0
-		fileSize = 0
-		g.Log().Warningf(ctx, "无法从Content-Length获取文件大小")
+	// 根据 spaceId 决定前缀
+	prefix := s.objectPrefix(in.SpaceId)
+	// 上传到对象存储
+	if err = s.PutObject(ctx, prefix+objectName, data, contentType); err != nil {
+		return nil, err
 	}
 
-	var fileName string
-	if in.FileName == "" {
-		// 生成文件名：日期 + UUID + 文件扩展名
-		fileName = s.generateFileName(in.FileUrl)
-	} else {
-		fileName = in.FileName
+	return &v1.BucketUploadByUrlRes{
+		FileAddress: prefix + objectName,
+		FileName:    fileName,
+		FileSize:    int64(len(data)),
+	}, nil
+}
+
+// PutObject 按指定key写入对象（用于缩略图等服务端生成的文件）
+func (s *sBucket) PutObject(ctx context.Context, key string, data []byte, contentType string) error {
+	if err := s.driver.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		g.Log().Errorf(ctx, "写入对象失败 key=%s: %v", key, err)
+		return gerror.New("上传文件失败")
 	}
+	return nil
+}
 
-	// 根据 spaceId 决定前缀
-	var prefix string
-	if in.SpaceId > 0 {
-		prefix = "space/" + strings.TrimSpace(gconv.String(in.SpaceId)) + "/"
-	} else {
-		prefix = "Public/"
-	}
-	// 上传到COS
-	_, err = s.Cli.Object.Put(ctx, prefix+fileName, resp.Body, nil)
+// PresignPut 为指定key生成限时的预签名PUT上传地址，存储驱动不支持直传时返回错误
+func (s *sBucket) PresignPut(ctx context.Context, key string, expire time.Duration) (string, error) {
+	p, ok := s.driver.(presigner)
+	if !ok {
+		return "", gerror.New("当前存储驱动不支持直传，请使用普通上传")
+	}
+	uploadUrl, err := p.PresignPut(ctx, key, expire)
 	if err != nil {
-		g.Log().Errorf(ctx, "上传到COS失败: %v", err)
-		return nil, gerror.New("上传文件失败")
+		g.Log().Errorf(ctx, "生成预签名上传地址失败 key=%s: %v", key, err)
+		return "", gerror.New("生成上传地址失败")
 	}
+	return uploadUrl, nil
+}
 
-	return &v1.BucketUploadByUrlRes{
-		FileAddress: prefix + fileName,
-		FileName:    fileName,
-		FileSize:    fileSize,
-	}, nil
+// ReadObject 读取对象内容，超过大小上限时返回错误
+func (s *sBucket) ReadObject(ctx context.Context, key string, maxSize int64) ([]byte, error) {
+	r, err := s.driver.Get(ctx, key)
+	if err != nil {
+		g.Log().Warningf(ctx, "读取对象失败 key=%s: %v", key, err)
+		return nil, gerror.New("文件不存在")
+	}
+	defer r.Close()
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		g.Log().Errorf(ctx, "读取对象内容失败 key=%s: %v", key, err)
+		return nil, gerror.New("读取文件失败")
+	}
+	if int64(len(data)) > maxSize {
+		return nil, gerror.Newf("文件大小超过限制（最大 %s）", gfile.FormatSize(maxSize))
+	}
+	return data, nil
 }
 
 // Delete 删除
 func (s *sBucket) Delete(ctx context.Context, in *v1.BucketDeleteReq) (out *v1.BucketDeleteRes, err error) {
 	// 删除接口保持向后兼容：按传入的完整路径删除
-	_, err = s.Cli.Object.Delete(ctx, in.FileName, nil)
-	if err != nil {
+	if err = s.driver.Delete(ctx, in.FileName); err != nil {
+		g.Log().Errorf(ctx, "删除对象失败 key=%s: %v", in.FileName, err)
 		return nil, gerror.New("删除文件失败")
 	}
 	return &v1.BucketDeleteRes{}, nil
 }
 
+// DeleteObjects 批量删除对象，删除失败的key记录到重试集合中，由定时任务补偿删除
+func (s *sBucket) DeleteObjects(ctx context.Context, keys ...string) (failed int) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := s.driver.Delete(ctx, key); err != nil {
+			g.Log().Errorf(ctx, "删除对象失败，加入重试队列 key=%s: %v", key, err)
+			if _, rErr := g.Redis().SAdd(ctx, deleteRetryKey, key); rErr != nil {
+				g.Log().Errorf(ctx, "记录删除失败对象失败 key=%s: %v", key, rErr)
+			}
+			failed++
+		}
+	}
+	return failed
+}
+
+// RetryFailedDeletes 重试删除之前删除失败的对象
+func (s *sBucket) RetryFailedDeletes(ctx context.Context) {
+	keys, err := g.Redis().SMembers(ctx, deleteRetryKey)
+	if err != nil {
+		g.Log().Errorf(ctx, "读取删除重试队列失败: %v", err)
+		return
+	}
+	for _, key := range keys.Strings() {
+		if err = s.driver.Delete(ctx, key); err != nil {
+			g.Log().Warningf(ctx, "重试删除对象失败 key=%s: %v", key, err)
+			continue
+		}
+		if _, err = g.Redis().SRem(ctx, deleteRetryKey, key); err != nil {
+			g.Log().Errorf(ctx, "移除删除重试记录失败 key=%s: %v", key, err)
+		}
+	}
+}
+
+// GetFileUrl 根据对象key拼接访问地址（前缀由当前存储驱动决定）
+func (s *sBucket) GetFileUrl(key string) string {
+	return s.driver.UrlPrefix() + strings.TrimPrefix(key, "/")
+}
+
+// GetFileKey 根据访问地址解析对象key
+func (s *sBucket) GetFileKey(fileUrl string) string {
+	if fileUrl == "" {
+		return ""
+	}
+	if prefix := s.driver.UrlPrefix(); strings.HasPrefix(fileUrl, prefix) {
+		return strings.TrimPrefix(fileUrl, prefix)
+	}
+	// 兼容切换驱动前写入的地址（如COS域名地址）：取URL路径部分作为key
+	parsedUrl, err := url.Parse(fileUrl)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(parsedUrl.Path, "/")
+}
+
+// LocalStaticPath 本地存储驱动的静态访问路径与磁盘目录，其他驱动返回空
+func (s *sBucket) LocalStaticPath() (servePath string, root string) {
+	if d, ok := s.driver.(*localDriver); ok {
+		return d.servePath, d.root
+	}
+	return "", ""
+}
+
+// NewObjectKey 生成新的对象key：空间前缀 + 日期_UUID + 扩展名
+func (s *sBucket) NewObjectKey(spaceId int64, fileName string) string {
+	return s.objectPrefix(spaceId) + s.generateFileName(fileName)
+}
+
+// objectPrefix 根据 spaceId 决定前缀：有 spaceId -> space/<id>/，否则 Public/
+func (s *sBucket) objectPrefix(spaceId int64) string {
+	if spaceId > 0 {
+		return "space/" + strings.TrimSpace(gconv.String(spaceId)) + "/"
+	}
+	return "Public/"
+}
+
 // generateFileName 生成文件名：日期 + UUID + 文件扩展名
 func (s *sBucket) generateFileName(fileUrl string) string {
 	// 获取当前日期，格式：20240320time.Now().Format("20060102")
 	dateStr := gtime.Now().Format("Ymd")
 
-	// 生成UUID（去掉连字符）
-	uuid := strings.ReplaceAll(guid.S(), "-", "")
-	uuid = uuid[:8]
+	// 生成UUID（32位，保证对象key唯一）
+	uuid := guid.S()
 
 	// 从URL中提取文件扩展名
 	ext := s.GetFileExtFromUrl(fileUrl)
@@ -176,7 +265,7 @@ func (s *sBucket) GetFileExtFromUrl(fileUrl string) string {
 	ext = strings.ToLower(ext)
 
 	// 验证是否为支持的图片格式
-	supportedExts := []string{".jpg", ".jpeg", ".png", ".gif", ".bmp", ".webp", ".svg"}
+	supportedExts := []string{".jpg", ".jpeg", ".png", ".gif", ".bmp", ".webp", ".svg", ".tif", ".tiff", ".heic", ".heif", ".avif"}
 	for _, supportedExt := range supportedExts {
 		if ext == supportedExt {
 			return ext
@@ -0,0 +1,46 @@
+package bucket
+
+import (
+	"cloud/internal/consts"
+	"context"
+	"io"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// storageDriver 对象存储驱动
+type storageDriver interface {
+	// Put 写入对象
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get 读取对象
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete 删除对象
+	Delete(ctx context.Context, key string) error
+	// UrlPrefix 对象访问地址前缀
+	UrlPrefix() string
+	// SignGet 生成限时的签名访问地址
+	SignGet(ctx context.Context, key string, expire time.Duration) (string, error)
+}
+
+// presigner 支持预签名直传的存储驱动（本地存储不支持）
+type presigner interface {
+	// PresignPut 生成限时的预签名PUT上传地址
+	PresignPut(ctx context.Context, key string, expire time.Duration) (string, error)
+}
+
+// newDriver 根据配置创建存储驱动，未配置时默认使用腾讯云COS
+func newDriver(ctx context.Context) (storageDriver, error) {
+	driver := g.Cfg().MustGet(ctx, consts.StorageDriver, consts.StorageDriverCos).String()
+	switch driver {
+	case consts.StorageDriverLocal:
+		return newLocalDriver(ctx)
+	case consts.StorageDriverS3:
+		return newS3Driver(ctx)
+	case consts.StorageDriverCos:
+		return newCosDriver(ctx)
+	default:
+		return nil, gerror.Newf("不支持的存储驱动: %s", driver)
+	}
+}
@@ -0,0 +1,87 @@
+package bucket
+
+import (
+	"cloud/internal/consts"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// cosDriver 腾讯云COS存储
+type cosDriver struct {
+	cli       *cos.Client
+	urlPrefix string
+}
+
+func newCosDriver(ctx context.Context) (*cosDriver, error) {
+	bucketURL := g.Cfg().MustGet(ctx, "storage.cos.bucketURL", consts.BucketURL).String()
+	u, err := url.Parse(bucketURL)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "COS存储桶地址无效: %s", bucketURL)
+	}
+	cli := cos.NewClient(&cos.BaseURL{BucketURL: u}, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  g.Cfg().MustGet(ctx, consts.SecretId).String(),
+			SecretKey: g.Cfg().MustGet(ctx, consts.SecretKey).String(),
+		},
+	})
+	return &cosDriver{
+		cli:       cli,
+		urlPrefix: strings.TrimRight(bucketURL, "/") + "/",
+	}, nil
+}
+
+// Put 写入对象
+func (d *cosDriver) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	opt := &cos.ObjectPutOptions{ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{ContentType: contentType}}
+	if size > 0 {
+		opt.ContentLength = size
+	}
+	_, err := d.cli.Object.Put(ctx, key, r, opt)
+	return err
+}
+
+// Get 读取对象
+func (d *cosDriver) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := d.cli.Object.Get(ctx, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete 删除对象
+func (d *cosDriver) Delete(ctx context.Context, key string) error {
+	_, err := d.cli.Object.Delete(ctx, key, nil)
+	return err
+}
+
+// PresignPut 生成限时的预签名PUT上传地址（使用客户端配置的密钥签名）
+func (d *cosDriver) PresignPut(ctx context.Context, key string, expire time.Duration) (string, error) {
+	u, err := d.cli.Object.GetPresignedURL2(ctx, http.MethodPut, key, expire, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// SignGet 生成限时的预签名GET访问地址
+func (d *cosDriver) SignGet(ctx context.Context, key string, expire time.Duration) (string, error) {
+	u, err := d.cli.Object.GetPresignedURL2(ctx, http.MethodGet, key, expire, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// UrlPrefix 对象访问地址前缀
+func (d *cosDriver) UrlPrefix() string {
+	return d.urlPrefix
+}
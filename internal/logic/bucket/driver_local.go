@@ -0,0 +1,134 @@
+package bucket
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+// localDriver 本地磁盘存储，文件由GoFrame服务器以静态目录方式提供访问
+type localDriver struct {
+	root      string
+	servePath string
+	urlPrefix string
+	secret    []byte // 签名访问地址的密钥
+}
+
+func newLocalDriver(ctx context.Context) (*localDriver, error) {
+	root := g.Cfg().MustGet(ctx, "storage.local.root", "resource/public/upload").String()
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if err = gfile.Mkdir(root); err != nil {
+		return nil, gerror.Wrapf(err, "创建本地存储目录失败: %s", root)
+	}
+	servePath := "/" + strings.Trim(g.Cfg().MustGet(ctx, "storage.local.servePath", "/upload").String(), "/")
+	urlPrefix := g.Cfg().MustGet(ctx, "storage.local.urlPrefix").String()
+	if urlPrefix == "" {
+		// 未配置外部访问地址时使用相对路径，由前端按当前域名访问
+		urlPrefix = servePath
+	}
+	secret := g.Cfg().MustGet(ctx, "storage.local.signSecret").String()
+	if secret == "" {
+		// 未配置时使用随机密钥，服务重启后已签发的访问地址失效
+		g.Log().Warning(ctx, "未配置 storage.local.signSecret，使用随机密钥签名私有文件访问地址")
+		secret = guid.S()
+	}
+	return &localDriver{
+		root:      root,
+		servePath: servePath,
+		urlPrefix: strings.TrimRight(urlPrefix, "/") + "/",
+		secret:    []byte(secret),
+	}, nil
+}
+
+// Put 写入对象
+func (d *localDriver) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	filePath, err := d.filePath(key)
+	if err != nil {
+		return err
+	}
+	if err = gfile.Mkdir(filepath.Dir(filePath)); err != nil {
+		return err
+	}
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, r)
+	return err
+}
+
+// Get 读取对象
+func (d *localDriver) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	filePath, err := d.filePath(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(filePath)
+}
+
+// Delete 删除对象
+func (d *localDriver) Delete(ctx context.Context, key string) error {
+	filePath, err := d.filePath(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// UrlPrefix 对象访问地址前缀
+func (d *localDriver) UrlPrefix() string {
+	return d.urlPrefix
+}
+
+// SignGet 生成限时的签名访问地址：?expires=过期时间戳&sign=HMAC-SHA256(key, expires)
+func (d *localDriver) SignGet(ctx context.Context, key string, expire time.Duration) (string, error) {
+	expires := strconv.FormatInt(time.Now().Add(expire).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("sign", d.sign(key, expires))
+	return d.urlPrefix + strings.TrimPrefix(key, "/") + "?" + query.Encode(), nil
+}
+
+// verify 校验签名访问地址是否有效且未过期
+func (d *localDriver) verify(key, expires, sign string) bool {
+	expireAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expireAt {
+		return false
+	}
+	return hmac.Equal([]byte(d.sign(key, expires)), []byte(sign))
+}
+
+func (d *localDriver) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, d.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// filePath 将对象key转换为磁盘路径，禁止越出存储根目录
+func (d *localDriver) filePath(key string) (string, error) {
+	filePath := filepath.Join(d.root, filepath.FromSlash(key))
+	if !strings.HasPrefix(filePath, d.root+string(filepath.Separator)) {
+		return "", gerror.Newf("非法的对象路径: %s", key)
+	}
+	return filePath, nil
+}
@@ -0,0 +1,102 @@
+package bucket
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// s3Driver S3兼容存储（MinIO、AWS S3等）
+type s3Driver struct {
+	cli       *minio.Client
+	bucket    string
+	urlPrefix string
+}
+
+func newS3Driver(ctx context.Context) (*s3Driver, error) {
+	cfg := g.Cfg().MustGet(ctx, "storage.s3").MapStrVar()
+	endpoint := cfg["endpoint"].String()
+	bucket := cfg["bucket"].String()
+	if endpoint == "" || bucket == "" {
+		return nil, gerror.New("S3存储缺少endpoint或bucket配置")
+	}
+	cli, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg["accessKey"].String(), cfg["secretKey"].String(), ""),
+		Secure: cfg["useSSL"].Bool(),
+		Region: cfg["region"].String(),
+	})
+	if err != nil {
+		return nil, gerror.Wrap(err, "初始化S3客户端失败")
+	}
+	urlPrefix := cfg["urlPrefix"].String()
+	if urlPrefix == "" {
+		// 默认使用 path-style 访问地址
+		scheme := "http://"
+		if cfg["useSSL"].Bool() {
+			scheme = "https://"
+		}
+		urlPrefix = scheme + endpoint + "/" + bucket
+	}
+	return &s3Driver{
+		cli:       cli,
+		bucket:    bucket,
+		urlPrefix: strings.TrimRight(urlPrefix, "/") + "/",
+	}, nil
+}
+
+// Put 写入对象
+func (d *s3Driver) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if size <= 0 {
+		size = -1
+	}
+	_, err := d.cli.PutObject(ctx, d.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+// Get 读取对象
+func (d *s3Driver) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := d.cli.GetObject(ctx, d.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// GetObject 是惰性请求，先取一次元数据以便及时发现对象不存在
+	if _, err = obj.Stat(); err != nil {
+		obj.Close()
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Delete 删除对象
+func (d *s3Driver) Delete(ctx context.Context, key string) error {
+	return d.cli.RemoveObject(ctx, d.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// PresignPut 生成限时的预签名PUT上传地址
+func (d *s3Driver) PresignPut(ctx context.Context, key string, expire time.Duration) (string, error) {
+	u, err := d.cli.PresignedPutObject(ctx, d.bucket, key, expire)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// SignGet 生成限时的预签名GET访问地址
+func (d *s3Driver) SignGet(ctx context.Context, key string, expire time.Duration) (string, error) {
+	u, err := d.cli.PresignedGetObject(ctx, d.bucket, key, expire, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// UrlPrefix 对象访问地址前缀
+func (d *s3Driver) UrlPrefix() string {
+	return d.urlPrefix
+}
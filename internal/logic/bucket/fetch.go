@@ -0,0 +1,38 @@
+package bucket
+
+import (
+	"cloud/utility/safefetch"
+	"context"
+	"errors"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gfile"
+)
+
+// Fetch 下载远程文件到内存（只下载一次，超过大小上限时中止），返回文件内容与Content-Type
+// 下载经过 safefetch 校验，禁止访问内网、回环等地址
+func (s *sBucket) Fetch(ctx context.Context, fileUrl string) (data []byte, contentType string, err error) {
+	client, err := safefetch.Default(ctx)
+	if err != nil {
+		g.Log().Errorf(ctx, "初始化下载客户端失败: %v", err)
+		return nil, "", gerror.New("下载文件失败")
+	}
+
+	data, contentType, err = client.Fetch(ctx, fileUrl, nil)
+	if err != nil {
+		g.Log().Errorf(ctx, "下载文件失败 %s: %v", fileUrl, err)
+		switch {
+		case errors.Is(err, safefetch.ErrBlocked):
+			return nil, "", gerror.New("不允许访问该地址")
+		case errors.Is(err, safefetch.ErrTooLarge):
+			return nil, "", gerror.Newf("文件大小超过限制（最大 %s）", gfile.FormatSize(client.MaxSize()))
+		}
+		return nil, "", gerror.New("下载文件失败")
+	}
+	if len(data) == 0 {
+		return nil, "", gerror.New("下载的文件为空")
+	}
+	g.Log().Debugf(ctx, "下载文件完成: %s，大小: %d bytes，类型: %s", fileUrl, len(data), contentType)
+	return data, contentType, nil
+}
@@ -0,0 +1,51 @@
+package bucket
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+const (
+	privateKeyPrefix       = "space/"  // 私有/团队空间对象的key前缀，需签名访问
+	defaultSignedUrlExpire = time.Hour // 默认签名访问地址有效期
+)
+
+// SignedUrl 为私有/团队空间的对象生成限时签名访问地址，公共对象及其他驱动写入的地址原样返回
+func (s *sBucket) SignedUrl(ctx context.Context, fileUrl string) string {
+	prefix := s.driver.UrlPrefix()
+	if fileUrl == "" || !strings.HasPrefix(fileUrl, prefix) {
+		return fileUrl
+	}
+	key := strings.TrimPrefix(fileUrl, prefix)
+	if !strings.HasPrefix(key, privateKeyPrefix) {
+		return fileUrl
+	}
+	expire := g.Cfg().MustGet(ctx, "storage.signedUrlExpire", defaultSignedUrlExpire).Duration()
+	if expire <= 0 {
+		expire = defaultSignedUrlExpire
+	}
+	signedUrl, err := s.driver.SignGet(ctx, key, expire)
+	if err != nil {
+		g.Log().Errorf(ctx, "生成签名访问地址失败 key=%s: %v", key, err)
+		return fileUrl
+	}
+	return signedUrl
+}
+
+// VerifyLocalAccess 校验本地存储静态文件的访问：私有/团队空间的文件必须携带有效且未过期的签名
+func (s *sBucket) VerifyLocalAccess(ctx context.Context, urlPath, expires, sign string) bool {
+	d, ok := s.driver.(*localDriver)
+	if !ok {
+		return true
+	}
+	// 先规范化路径，避免通过 ../ 等绕过前缀判断
+	key := strings.TrimPrefix(path.Clean("/"+urlPath), d.servePath+"/")
+	if !strings.HasPrefix(key, privateKeyPrefix) {
+		return true
+	}
+	return d.verify(key, expires, sign)
+}
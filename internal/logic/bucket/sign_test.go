@@ -0,0 +1,52 @@
+package bucket
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_localSignedUrl(t *testing.T) {
+	ctx := context.Background()
+	d := &localDriver{root: t.TempDir(), servePath: "/upload", urlPrefix: "/upload/", secret: []byte("secret")}
+	s := &sBucket{driver: d}
+
+	// 公共对象保持原地址
+	if got := s.SignedUrl(ctx, "/upload/Public/a.jpg"); got != "/upload/Public/a.jpg" {
+		t.Errorf("公共对象不应签名, got=%s", got)
+	}
+
+	signed := s.SignedUrl(ctx, "/upload/space/1/a.jpg")
+	u, err := url.Parse(signed)
+	if err != nil || u.Path != "/upload/space/1/a.jpg" {
+		t.Fatalf("签名地址无效: %s", signed)
+	}
+	expires, sign := u.Query().Get("expires"), u.Query().Get("sign")
+	if !s.VerifyLocalAccess(ctx, u.Path, expires, sign) {
+		t.Error("有效签名应允许访问")
+	}
+	if s.VerifyLocalAccess(ctx, u.Path, "", "") {
+		t.Error("未签名的私有文件应拒绝访问")
+	}
+	if s.VerifyLocalAccess(ctx, "/upload/space/1/b.jpg", expires, sign) {
+		t.Error("签名不能用于其他文件")
+	}
+	if s.VerifyLocalAccess(ctx, "/upload/Public/../space/1/a.jpg", "", "") {
+		t.Error("不能通过 ../ 绕过签名校验")
+	}
+	if !s.VerifyLocalAccess(ctx, "/upload/Public/a.jpg", "", "") {
+		t.Error("公共文件无需签名")
+	}
+
+	// 已过期的签名
+	past := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	if d.verify("space/1/a.jpg", past, d.sign("space/1/a.jpg", past)) {
+		t.Error("过期签名应拒绝访问")
+	}
+	if !strings.HasPrefix(signed, d.urlPrefix) {
+		t.Errorf("签名地址前缀错误: %s", signed)
+	}
+}
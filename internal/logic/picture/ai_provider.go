@@ -0,0 +1,40 @@
+package picture
+
+import (
+	"cloud/internal/consts"
+	"context"
+	"image"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// aiImageRequest AI图片生成请求
+type aiImageRequest struct {
+	TaskType string             // 任务类型：outPainting、editing
+	Prompt   string             // 描述
+	ImageUrl string             // 原图访问地址（私有空间为签名地址，供远程服务访问）
+	ImageKey string             // 原图对象key
+	Canvas   *outPaintingCanvas // 扩图画布（扩图任务）
+	Padded   *image.NRGBA       // 原图居中放置到画布上的图片，扩展区域透明（扩图任务）
+	Mask     *image.Gray        // 扩图蒙版，白色为需要补全的区域（扩图任务）
+}
+
+// aiImageProvider AI图片生成服务
+type aiImageProvider interface {
+	// Generate 生成图片，返回结果图片地址（服务托管的地址或本系统存储的地址）
+	Generate(ctx context.Context, req *aiImageRequest) (string, error)
+}
+
+// newAIProvider 根据配置创建AI图片生成服务，未配置时默认使用火山引擎
+func newAIProvider(ctx context.Context) (aiImageProvider, error) {
+	provider := g.Cfg().MustGet(ctx, consts.AiProvider, consts.AiProviderVolcengine).String()
+	switch provider {
+	case consts.AiProviderVolcengine:
+		return newVolcengineProvider(ctx), nil
+	case consts.AiProviderStub:
+		return &stubProvider{}, nil
+	default:
+		return nil, gerror.Newf("不支持的AI服务: %s", provider)
+	}
+}
@@ -0,0 +1,118 @@
+package picture
+
+import (
+	"bytes"
+	"cloud/internal/service"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/draw"
+	"path"
+	"time"
+
+	"github.com/disintegration/imaging"
+	"github.com/gogf/gf/v2/database/gredis"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+const (
+	stubOutputKey           = "picture:ai:stub:output" // 本地生成的结果对象key（score为过期时间戳），过期后由定时任务删除
+	defaultStubOutputExpire = 24 * time.Hour           // 默认结果保留时间，与AI服务托管结果的有效期一致
+)
+
+// stubProvider 本地AI服务替身，供CI与离线开发使用：不访问网络，按任务类型对原图做确定性的变换
+// 结果写入原图所在目录（私有空间的结果同样需要签名访问），相同的原图与参数总是得到相同的结果地址与内容
+// 结果与AI服务托管的结果一样是临时的：保存到图库时会转存为新对象，原结果到期后删除
+type stubProvider struct{}
+
+func (p *stubProvider) Generate(ctx context.Context, req *aiImageRequest) (string, error) {
+	var result image.Image
+	if req.TaskType == aiTaskTypeOutPainting {
+		if req.Canvas == nil || req.Padded == nil || req.Mask == nil {
+			return "", &aiTaskAbortError{gerror.New("扩图画布不存在")}
+		}
+		result = stubOutPaint(req.Canvas, req.Padded, req.Mask)
+	} else {
+		if req.ImageKey == "" {
+			return "", &aiTaskAbortError{gerror.New("原图文件不存在")}
+		}
+		data, err := service.Bucket().ReadObject(ctx, req.ImageKey, pictureMaxSize(ctx))
+		if err != nil {
+			return "", err
+		}
+		img, err := imaging.Decode(bytes.NewReader(data))
+		if err != nil {
+			return "", &aiTaskAbortError{gerror.New("原图解码失败")}
+		}
+		result = stubEdit(img)
+	}
+
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, result, imaging.PNG); err != nil {
+		return "", err
+	}
+	key := stubResultKey(req)
+	if err := service.Bucket().PutObject(ctx, key, buf.Bytes(), "image/png"); err != nil {
+		return "", err
+	}
+	// 重试覆盖同一对象时顺延过期时间
+	expire := g.Cfg().MustGet(ctx, "picture.aiTask.stubOutputExpire", defaultStubOutputExpire).Duration()
+	if _, err := g.Redis().ZAdd(ctx, stubOutputKey, nil, gredis.ZAddMember{
+		Score:  float64(time.Now().Add(expire).Unix()),
+		Member: key,
+	}); err != nil {
+		// 无法登记过期时间的结果不能被清理，删除后由任务重试
+		service.Bucket().DeleteObjects(ctx, key)
+		return "", err
+	}
+	return service.Bucket().GetFileUrl(key), nil
+}
+
+// CleanupStubOutputs 删除本地AI服务替身生成的已过期结果
+func (s *sPicture) CleanupStubOutputs(ctx context.Context) {
+	value, err := g.Redis().Do(ctx, "ZRANGEBYSCORE", stubOutputKey, "-inf", time.Now().Unix())
+	if err != nil {
+		g.Log().Errorf(ctx, "查询过期的AI生成结果失败: %v", err)
+		return
+	}
+	cleaned := 0
+	for _, key := range value.Strings() {
+		// 认领成功才删除，避免与同时重新生成的任务并发
+		if claimed, remErr := g.Redis().ZRem(ctx, stubOutputKey, key); remErr != nil || claimed == 0 {
+			continue
+		}
+		s.deleteObjects(ctx, key)
+		cleaned++
+	}
+	if cleaned > 0 {
+		g.Log().Infof(ctx, "已清理 %d 个过期的AI生成结果", cleaned)
+	}
+}
+
+// stubOutPaint 按蒙版补全扩展区域：以模糊放大的原图填充蒙版中的白色区域，原图区域保持不变
+func stubOutPaint(canvas *outPaintingCanvas, padded *image.NRGBA, mask *image.Gray) image.Image {
+	offset := canvas.offset()
+	source := padded.SubImage(image.Rectangle{Min: offset, Max: offset.Add(image.Pt(canvas.SourceWidth, canvas.SourceHeight))})
+	fill := imaging.Blur(imaging.Resize(source, canvas.Width, canvas.Height, imaging.Linear), 8)
+	result := imaging.Clone(padded)
+	draw.DrawMask(result, result.Bounds(), fill, image.Point{}, mask, image.Point{}, draw.Src)
+	return result
+}
+
+// stubEdit AI编辑：转为灰度并增强对比度
+func stubEdit(img image.Image) image.Image {
+	return imaging.AdjustContrast(imaging.Grayscale(img), 20)
+}
+
+// stubResultKey 结果对象key：原图目录下以任务类型、描述、画布尺寸与原图key的摘要命名，重试时覆盖同一对象
+func stubResultKey(req *aiImageRequest) string {
+	params := ""
+	if req.Canvas != nil {
+		params = fmt.Sprintf("%dx%d", req.Canvas.Width, req.Canvas.Height)
+	}
+	sum := sha256.Sum256([]byte(req.TaskType + "\n" + req.Prompt + "\n" + params + "\n" + req.ImageKey))
+	return path.Join(path.Dir(req.ImageKey), "ai_"+hex.EncodeToString(sum[:8])+".png")
+}
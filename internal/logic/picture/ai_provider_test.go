@@ -0,0 +1,57 @@
+package picture
+
+import (
+	v1 "cloud/api/user/v1"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/disintegration/imaging"
+)
+
+func Test_stubOutPaint(t *testing.T) {
+	img := imaging.New(40, 20, color.NRGBA{R: 200, G: 50, B: 50, A: 255})
+	canvas, err := newOutPaintingCanvas(40, 20, &v1.OutPaintingParameters{XScale: 2, YScale: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	padded, mask := canvas.pad(img)
+
+	out := stubOutPaint(canvas, padded, mask)
+	if out.Bounds() != image.Rect(0, 0, 80, 60) {
+		t.Errorf("扩图尺寸错误, got=%v", out.Bounds())
+	}
+	// 原图居中保留，扩展区域被填充为不透明
+	if c := color.NRGBAModel.Convert(out.At(40, 30)).(color.NRGBA); c.R != 200 || c.G != 50 {
+		t.Errorf("扩图中心应为原图, got=%v", c)
+	}
+	if c := color.NRGBAModel.Convert(out.At(0, 0)).(color.NRGBA); c.A != 255 {
+		t.Errorf("扩展区域应被补全, got=%v", c)
+	}
+}
+
+func Test_stubEdit(t *testing.T) {
+	img := imaging.New(40, 20, color.NRGBA{R: 200, G: 50, B: 50, A: 255})
+	edited := stubEdit(img)
+	if edited.Bounds() != image.Rect(0, 0, 40, 20) {
+		t.Errorf("AI编辑不应改变尺寸, got=%v", edited.Bounds())
+	}
+	if c := color.NRGBAModel.Convert(edited.At(0, 0)).(color.NRGBA); c.R != c.G || c.G != c.B {
+		t.Errorf("AI编辑结果应为灰度, got=%v", c)
+	}
+}
+
+func Test_stubResultKey(t *testing.T) {
+	req := &aiImageRequest{TaskType: aiTaskTypeEditing, Prompt: "a", ImageKey: "space/1/a.jpg"}
+	key := stubResultKey(req)
+	if !strings.HasPrefix(key, "space/1/ai_") || !strings.HasSuffix(key, ".png") {
+		t.Errorf("结果应写入原图目录, got=%s", key)
+	}
+	if stubResultKey(req) != key {
+		t.Error("相同请求的结果key应相同")
+	}
+	if stubResultKey(&aiImageRequest{TaskType: aiTaskTypeEditing, Prompt: "b", ImageKey: "space/1/a.jpg"}) == key {
+		t.Error("不同描述的结果key应不同")
+	}
+}
@@ -0,0 +1,109 @@
+package picture
+
+import (
+	"bytes"
+	"cloud/internal/consts"
+	"cloud/internal/service"
+	"context"
+	"image"
+	"image/color"
+	"os"
+	"path"
+
+	"github.com/disintegration/imaging"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime"
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime/model"
+	"github.com/volcengine/volcengine-go-sdk/volcengine"
+)
+
+// volcengineProvider 火山引擎方舟图片生成（图生图）
+type volcengineProvider struct {
+	apiKey        string
+	model         string
+	seed          int64
+	guidanceScale float64
+	watermark     bool
+}
+
+// newVolcengineProvider 读取 ai.volcengine 配置
+func newVolcengineProvider(ctx context.Context) *volcengineProvider {
+	return &volcengineProvider{
+		apiKey:        volcengineApiKey(ctx),
+		model:         g.Cfg().MustGet(ctx, "ai.volcengine.model", "doubao-seededit-3-0-i2i-250628").String(),
+		seed:          g.Cfg().MustGet(ctx, "ai.volcengine.seed", 123).Int64(),
+		guidanceScale: g.Cfg().MustGet(ctx, "ai.volcengine.guidanceScale", 5.5).Float64(),
+		watermark:     g.Cfg().MustGet(ctx, "ai.volcengine.watermark", true).Bool(),
+	}
+}
+
+// volcengineApiKey 火山引擎方舟密钥：依次使用 ai.volcengine.apiKey、aiKey 与环境变量 ARK_API_KEY
+func volcengineApiKey(ctx context.Context) string {
+	apiKey := g.Cfg().MustGet(ctx, "ai.volcengine.apiKey").String()
+	if apiKey == "" {
+		apiKey = g.Cfg().MustGet(ctx, consts.AiKey).String()
+	}
+	if apiKey == "" {
+		apiKey = os.Getenv("ARK_API_KEY")
+	}
+	return apiKey
+}
+
+func (p *volcengineProvider) Generate(ctx context.Context, req *aiImageRequest) (string, error) {
+	if p.apiKey == "" {
+		return "", &aiTaskAbortError{gerror.New("AI服务未配置密钥")}
+	}
+	imageUrl, prompt := req.ImageUrl, req.Prompt
+	if req.TaskType == aiTaskTypeOutPainting {
+		// 图生图接口不支持蒙版：上传以白色填充扩展区域的画布作为输入，并在描述中说明需要补全的区域
+		key, err := p.putCanvas(ctx, req)
+		if err != nil {
+			return "", err
+		}
+		defer service.Bucket().DeleteObjects(ctx, key)
+		imageUrl = service.Bucket().SignedUrl(ctx, service.Bucket().GetFileUrl(key))
+		prompt = "将图片四周的白色空白区域自然地补全，保持中间原图内容不变。" + req.Prompt
+	}
+	client := arkruntime.NewClientWithApiKey(p.apiKey)
+
+	responseFormat := model.GenerateImagesResponseFormatURL
+	size := model.GenerateImagesSizeAdaptive
+	imagesResponse, err := client.GenerateImages(ctx, model.GenerateImagesRequest{
+		Model:          p.model,
+		Prompt:         prompt,
+		Image:          imageUrl,
+		ResponseFormat: &responseFormat,
+		Seed:           volcengine.Int64(p.seed),
+		GuidanceScale:  volcengine.Float64(p.guidanceScale),
+		Size:           &size,
+		Watermark:      volcengine.Bool(p.watermark),
+	})
+	if err != nil {
+		return "", gerror.Newf("AI服务调用失败: %v", err)
+	}
+	if len(imagesResponse.Data) == 0 || imagesResponse.Data[0].Url == nil {
+		return "", gerror.New("AI服务返回结果为空")
+	}
+	return *imagesResponse.Data[0].Url, nil
+}
+
+// putCanvas 将扩图画布（扩展区域填充白色）临时写入原图所在目录，供AI服务访问
+func (p *volcengineProvider) putCanvas(ctx context.Context, req *aiImageRequest) (string, error) {
+	if req.Padded == nil {
+		return "", &aiTaskAbortError{gerror.New("扩图画布不存在")}
+	}
+	canvas := imaging.New(req.Padded.Bounds().Dx(), req.Padded.Bounds().Dy(), color.White)
+	canvas = imaging.Overlay(canvas, req.Padded, image.Point{}, 1)
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, canvas, imaging.PNG); err != nil {
+		return "", err
+	}
+	key := path.Join(path.Dir(req.ImageKey), "ai_canvas_"+gconv.String(gtime.TimestampNano())+".png")
+	if err := service.Bucket().PutObject(ctx, key, buf.Bytes(), "image/png"); err != nil {
+		return "", err
+	}
+	return key, nil
+}
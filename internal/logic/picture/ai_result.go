@@ -0,0 +1,62 @@
+package picture
+
+import (
+	v1 "cloud/api/user/v1"
+	"cloud/internal/model/entity"
+	"cloud/internal/service"
+	"context"
+	"path"
+	"strings"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// AI生成结果的保存方式
+const (
+	aiSaveNew     = "new"     // 保存为同一空间的新图片
+	aiSaveVersion = "version" // 替换原图文件，记录为原图的新版本
+)
+
+// saveAIResult 将AI生成的结果图片保存到图库，服务托管的结果地址会过期，需转存到本系统的存储
+// new：通过URL上传的入库流程保存为原图所在空间的新图片，并关联来源图片；version：替换原图文件并记录版本
+// 两种方式都计入原图所在空间的额度
+func (s *sPicture) saveAIResult(ctx context.Context, picture *entity.Picture, userId int64, outputUrl string, saveMode string, remark string) (*v1.PictureVO, error) {
+	if saveMode != aiSaveNew && saveMode != aiSaveVersion {
+		return nil, gerror.New("不支持的保存方式")
+	}
+	data, err := s.readAIOutput(ctx, outputUrl)
+	if err != nil {
+		return nil, err
+	}
+	if saveMode == aiSaveNew {
+		name := strings.TrimSuffix(picture.Name, path.Ext(picture.Name)) + "_ai"
+		return s.uploadData(ctx, userId, picture.SpaceId, name, data, uploadOptions{
+			SourceUrl:       outputUrl,
+			SourcePictureId: picture.Id,
+		})
+	}
+
+	img, format, err := s.decodeImage(ctx, data)
+	if err != nil {
+		return nil, gerror.New("AI生成结果不是有效的图片")
+	}
+	// PNG保留透明通道，其余格式统一输出JPEG
+	if format != "png" && format != "jpeg" {
+		format = "jpeg"
+		if data, err = encodeImage(img, format); err != nil {
+			g.Log().Errorf(ctx, "编码AI生成结果失败 id=%d: %v", picture.Id, err)
+			return nil, gerror.New("保存AI生成结果失败")
+		}
+	}
+	return s.replaceFile(ctx, picture, img, data, format, userId, versionActionAI, remark)
+}
+
+// readAIOutput 读取AI生成的结果：本系统存储中的结果（本地生成）直接读取，服务托管的结果通过URL下载
+func (s *sPicture) readAIOutput(ctx context.Context, outputUrl string) ([]byte, error) {
+	if strings.HasPrefix(outputUrl, service.Bucket().GetFileUrl("")) {
+		return service.Bucket().ReadObject(ctx, service.Bucket().GetFileKey(outputUrl), pictureMaxSize(ctx))
+	}
+	data, _, err := service.Bucket().Fetch(ctx, outputUrl)
+	return data, err
+}
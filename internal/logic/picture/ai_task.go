@@ -0,0 +1,454 @@
+package picture
+
+import (
+	v1 "cloud/api/user/v1"
+	"cloud/internal/consts"
+	"cloud/internal/dao"
+	"cloud/internal/model/do"
+	"cloud/internal/model/entity"
+	wsmodel "cloud/internal/model/websocket"
+	"cloud/internal/service"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+// AI任务类型
+const (
+	aiTaskTypeOutPainting = "outPainting" // 扩图
+	aiTaskTypeEditing     = "editing"     // AI编辑
+)
+
+// AI任务状态
+const (
+	aiTaskPending   = "PENDING"
+	aiTaskRunning   = "RUNNING"
+	aiTaskSucceeded = "SUCCEEDED"
+	aiTaskFailed    = "FAILED"
+)
+
+const (
+	defaultAITaskWorkers     = 2
+	defaultAITaskPoll        = 2 * time.Second
+	defaultAITaskTimeout     = 2 * time.Minute
+	defaultAITaskBackoff     = 10 * time.Second
+	defaultAITaskMaxAttempts = 3
+	defaultAITaskUserLimit   = 2
+	maxAITaskBackoff         = 10 * time.Minute
+	aiTaskClaimBatch         = 10
+)
+
+// aiTaskAbortError 重试也无法成功的任务错误（原图已删除、空间额度不足等），直接标记失败
+type aiTaskAbortError struct {
+	err error
+}
+
+func (e *aiTaskAbortError) Error() string {
+	return e.err.Error()
+}
+
+// createAITask 创建AI任务并唤醒任务处理协程，每个用户同时进行中（排队或执行中）的任务数受限
+func (s *sPicture) createAITask(ctx context.Context, userId int64, picture *entity.Picture, taskType string, prompt string, parameters any, saveMode string) (*entity.AiTask, error) {
+	parametersJson, err := gjson.Encode(parameters)
+	if err != nil {
+		return nil, gerror.New("任务参数无效")
+	}
+	limit := g.Cfg().MustGet(ctx, "picture.aiTask.userLimit", defaultAITaskUserLimit).Int()
+
+	var id int64
+	at := dao.AiTask.Columns()
+	err = g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		// 锁定用户记录，串行化同一用户的任务创建，保证进行中任务数的检查准确
+		if _, lockErr := dao.User.Ctx(ctx).TX(tx).Where(dao.User.Columns().Id, userId).LockUpdate().Value(dao.User.Columns().Id); lockErr != nil {
+			return gerror.New("创建AI任务失败")
+		}
+		active, countErr := dao.AiTask.Ctx(ctx).TX(tx).Where(at.UserId, userId).
+			WhereIn(at.Status, []string{aiTaskPending, aiTaskRunning}).Count()
+		if countErr != nil {
+			return gerror.New("创建AI任务失败")
+		}
+		if active >= limit {
+			return gerror.Newf("进行中的AI任务已达上限（%d 个），请等待完成后再试", limit)
+		}
+		resp, inErr := dao.AiTask.Ctx(ctx).TX(tx).Data(do.AiTask{
+			TaskType:    taskType,
+			PictureId:   picture.Id,
+			UserId:      userId,
+			Prompt:      prompt,
+			Parameters:  string(parametersJson),
+			SaveMode:    saveMode,
+			Status:      aiTaskPending,
+			Attempts:    0,
+			NextRunTime: gtime.Now(),
+			CreateTime:  gtime.Now(),
+			UpdateTime:  gtime.Now(),
+		}).Insert()
+		if inErr != nil {
+			g.Log().Errorf(ctx, "保存AI任务失败: %v", inErr)
+			return gerror.New("创建AI任务失败")
+		}
+		id, inErr = resp.LastInsertId()
+		return inErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 唤醒空闲的处理协程，没有空闲协程时由轮询取走
+	select {
+	case s.aiTaskWake <- struct{}{}:
+	default:
+	}
+
+	var task *entity.AiTask
+	if err = dao.AiTask.Ctx(ctx).Where(at.Id, id).Scan(&task); err != nil || task == nil {
+		return nil, gerror.New("查询AI任务失败")
+	}
+	g.Log().Infof(ctx, "创建AI任务 id=%d，类型: %s，用户ID: %d，图片ID: %d", id, taskType, userId, picture.Id)
+	return task, nil
+}
+
+// GetAIEditingTask 获取AI编辑任务
+func (s *sPicture) GetAIEditingTask(ctx context.Context, req *v1.GetPictureAIEditingTaskReq) (res *v1.GetPictureAIEditingTaskRes, err error) {
+	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseInt(req.TaskId, 10, 64)
+	if err != nil {
+		return nil, gerror.New("任务不存在")
+	}
+
+	var task *entity.AiTask
+	if err = dao.AiTask.Ctx(ctx).Where(dao.AiTask.Columns().Id, id).Scan(&task); err != nil || task == nil {
+		return nil, gerror.New("任务不存在")
+	}
+	// 只有任务创建者或管理员可以查看
+	if task.UserId != user.Id && user.UserRole != consts.Admin {
+		return nil, gerror.New("任务不存在")
+	}
+
+	output := &v1.GetAIEditingTaskResponse{
+		TaskId:         req.TaskId,
+		TaskType:       task.TaskType,
+		PictureId:      task.PictureId,
+		TaskStatus:     task.Status,
+		Attempts:       task.Attempts,
+		OutputImageUrl: service.Bucket().SignedUrl(ctx, task.OutputUrl),
+		ErrorMessage:   task.ErrorMessage,
+		CreateTime:     task.CreateTime.Format(consts.Y_m_d_His),
+	}
+	if task.TaskType == aiTaskTypeOutPainting {
+		var canvas *outPaintingCanvas
+		if gjson.DecodeTo(task.Parameters, &canvas) == nil && canvas != nil {
+			output.OutputWidth, output.OutputHeight = canvas.Width, canvas.Height
+		}
+	}
+	if task.FinishTime != nil {
+		output.FinishTime = task.FinishTime.Format(consts.Y_m_d_His)
+	}
+	if task.Status == aiTaskSucceeded && task.ResultPictureId > 0 {
+		var picture *entity.Picture
+		pic := dao.Picture.Columns()
+		if err = dao.Picture.Ctx(ctx).Where(pic.Id, task.ResultPictureId).
+			Where(pic.IsDelete, 0).Scan(&picture); err == nil && picture != nil {
+			output.Picture = s.entityToVO(ctx, picture)
+		}
+	}
+	return &v1.GetPictureAIEditingTaskRes{
+		Output:    output,
+		RequestId: "req_" + req.TaskId,
+	}, nil
+}
+
+// StartAITaskWorkers 启动AI任务处理协程，从任务表中领取到期的任务执行
+func (s *sPicture) StartAITaskWorkers(ctx context.Context) {
+	workers := g.Cfg().MustGet(ctx, "picture.aiTask.workers", defaultAITaskWorkers).Int()
+	for i := 0; i < workers; i++ {
+		go s.runAITaskWorker(ctx)
+	}
+	g.Log().Infof(ctx, "AI任务处理协程已启动，数量: %d", workers)
+}
+
+// runAITaskWorker 任务处理循环：被唤醒或轮询到期时持续处理，直到没有可执行的任务
+func (s *sPicture) runAITaskWorker(ctx context.Context) {
+	interval := g.Cfg().MustGet(ctx, "picture.aiTask.pollInterval", defaultAITaskPoll).Duration()
+	if interval <= 0 {
+		interval = defaultAITaskPoll
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.aiTaskWake:
+		}
+		for s.processNextAITask(ctx) {
+		}
+	}
+}
+
+// processNextAITask 领取并执行一个任务，没有可执行的任务时返回false
+func (s *sPicture) processNextAITask(ctx context.Context) (processed bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			g.Log().Errorf(ctx, "AI任务处理异常: %v", r)
+			processed = false
+		}
+	}()
+	task := s.claimAITask(ctx)
+	if task == nil {
+		return false
+	}
+	s.executeAITask(ctx, task)
+	return true
+}
+
+// claimAITask 领取一个到期的排队任务：以状态为条件更新，多个协程或实例并发领取时只有一个成功
+func (s *sPicture) claimAITask(ctx context.Context) *entity.AiTask {
+	at := dao.AiTask.Columns()
+	var candidates []entity.AiTask
+	err := dao.AiTask.Ctx(ctx).Where(at.Status, aiTaskPending).
+		WhereLTE(at.NextRunTime, gtime.Now()).
+		OrderAsc(at.NextRunTime).OrderAsc(at.Id).
+		Limit(aiTaskClaimBatch).Scan(&candidates)
+	if err != nil {
+		g.Log().Errorf(ctx, "查询待执行的AI任务失败: %v", err)
+		return nil
+	}
+	for i := range candidates {
+		task := &candidates[i]
+		result, upErr := dao.AiTask.Ctx(ctx).Where(at.Id, task.Id).
+			Where(at.Status, aiTaskPending).Where(at.Attempts, task.Attempts).Data(do.AiTask{
+			Status:     aiTaskRunning,
+			Attempts:   gdb.Raw(at.Attempts + " + 1"),
+			StartTime:  gtime.Now(),
+			UpdateTime: gtime.Now(),
+		}).Update()
+		if upErr != nil {
+			g.Log().Errorf(ctx, "领取AI任务失败 id=%d: %v", task.Id, upErr)
+			continue
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			// 已被其他协程领取
+			continue
+		}
+		task.Status = aiTaskRunning
+		task.Attempts++
+		return task
+	}
+	return nil
+}
+
+// executeAITask 执行任务并保存结果；失败时按退避时间重新排队，超过最大次数或不可重试时标记失败
+func (s *sPicture) executeAITask(ctx context.Context, task *entity.AiTask) {
+	timeout := g.Cfg().MustGet(ctx, "picture.aiTask.timeout", defaultAITaskTimeout).Duration()
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	resultPictureId, err := s.runAITask(runCtx, task)
+	cancel()
+
+	at := dao.AiTask.Columns()
+	// 以领取时的执行次数为条件，超时被重新排队后旧的执行结果不再写入
+	db := dao.AiTask.Ctx(ctx).Where(at.Id, task.Id).
+		Where(at.Status, aiTaskRunning).Where(at.Attempts, task.Attempts)
+	if err == nil {
+		task.Status = aiTaskSucceeded
+		task.ResultPictureId = resultPictureId
+		_, err = db.Data(do.AiTask{
+			Status:          aiTaskSucceeded,
+			OutputUrl:       task.OutputUrl,
+			ResultPictureId: resultPictureId,
+			ErrorMessage:    "",
+			FinishTime:      gtime.Now(),
+			UpdateTime:      gtime.Now(),
+		}).Update()
+		if err != nil {
+			g.Log().Errorf(ctx, "更新AI任务状态失败 id=%d: %v", task.Id, err)
+		}
+		g.Log().Infof(ctx, "AI任务完成 id=%d，结果URL: %s", task.Id, task.OutputUrl)
+		s.notifyAITask(ctx, task)
+		return
+	}
+
+	task.ErrorMessage = err.Error()
+	maxAttempts := g.Cfg().MustGet(ctx, "picture.aiTask.maxAttempts", defaultAITaskMaxAttempts).Int()
+	var abortErr *aiTaskAbortError
+	if task.Attempts < maxAttempts && !errors.As(err, &abortErr) {
+		base := g.Cfg().MustGet(ctx, "picture.aiTask.retryBackoff", defaultAITaskBackoff).Duration()
+		delay := aiTaskBackoff(base, task.Attempts)
+		g.Log().Warningf(ctx, "AI任务执行失败 id=%d，第 %d 次，%s 后重试: %v", task.Id, task.Attempts, delay, err)
+		if _, upErr := db.Data(do.AiTask{
+			Status:       aiTaskPending,
+			OutputUrl:    task.OutputUrl,
+			ErrorMessage: task.ErrorMessage,
+			NextRunTime:  gtime.Now().Add(delay),
+			UpdateTime:   gtime.Now(),
+		}).Update(); upErr != nil {
+			g.Log().Errorf(ctx, "更新AI任务状态失败 id=%d: %v", task.Id, upErr)
+		}
+		return
+	}
+
+	g.Log().Errorf(ctx, "AI任务失败 id=%d，共执行 %d 次: %v", task.Id, task.Attempts, err)
+	task.Status = aiTaskFailed
+	if _, upErr := db.Data(do.AiTask{
+		Status:       aiTaskFailed,
+		OutputUrl:    task.OutputUrl,
+		ErrorMessage: task.ErrorMessage,
+		FinishTime:   gtime.Now(),
+		UpdateTime:   gtime.Now(),
+	}).Update(); upErr != nil {
+		g.Log().Errorf(ctx, "更新AI任务状态失败 id=%d: %v", task.Id, upErr)
+	}
+	s.notifyAITask(ctx, task)
+}
+
+// runAITask 调用AI服务生成结果，并按任务设置保存到图库，返回保存后的图片ID
+// 生成的结果地址先记录到任务上，保存失败重试时不再重复生成
+func (s *sPicture) runAITask(ctx context.Context, task *entity.AiTask) (int64, error) {
+	var picture *entity.Picture
+	pic := dao.Picture.Columns()
+	err := dao.Picture.Ctx(ctx).Where(pic.Id, task.PictureId).
+		Where(pic.IsDelete, 0).Scan(&picture)
+	if err != nil {
+		return 0, err
+	}
+	if picture == nil {
+		return 0, &aiTaskAbortError{gerror.New("原图不存在或已删除")}
+	}
+
+	if task.OutputUrl == "" {
+		provider, providerErr := newAIProvider(ctx)
+		if providerErr != nil {
+			return 0, &aiTaskAbortError{providerErr}
+		}
+		req := &aiImageRequest{
+			TaskType: task.TaskType,
+			Prompt:   task.Prompt,
+			ImageUrl: service.Bucket().SignedUrl(ctx, picture.Url), // 私有空间图片需签名后AI服务才能访问
+			ImageKey: service.Bucket().GetFileKey(picture.Url),
+		}
+		if task.TaskType == aiTaskTypeOutPainting {
+			if err = s.prepareOutPainting(ctx, picture, task.Parameters, req); err != nil {
+				return 0, err
+			}
+		}
+		if task.OutputUrl, err = provider.Generate(ctx, req); err != nil {
+			return 0, err
+		}
+		at := dao.AiTask.Columns()
+		if _, err = dao.AiTask.Ctx(ctx).Where(at.Id, task.Id).Data(do.AiTask{
+			OutputUrl:  task.OutputUrl,
+			UpdateTime: gtime.Now(),
+		}).Update(); err != nil {
+			g.Log().Warningf(ctx, "记录AI任务结果失败 id=%d: %v", task.Id, err)
+		}
+	}
+	if task.SaveMode == "" {
+		return 0, nil
+	}
+
+	remark := "AI编辑"
+	if task.TaskType == aiTaskTypeOutPainting {
+		remark = "AI扩图"
+	}
+	vo, err := s.saveAIResult(ctx, picture, task.UserId, task.OutputUrl, task.SaveMode, remark)
+	if err != nil {
+		if gerror.Code(err) == consts.CodeSpaceQuotaExceeded {
+			return 0, &aiTaskAbortError{err}
+		}
+		return 0, err
+	}
+	return vo.Id, nil
+}
+
+// notifyAITask 通过WebSocket向任务创建者推送任务结果，结果地址与查询任务接口一样签名后返回
+func (s *sPicture) notifyAITask(ctx context.Context, task *entity.AiTask) {
+	service.WebSocket().NotifyUser(ctx, task.UserId, wsmodel.AITaskMessage{
+		Type:            wsmodel.MessageTypeAITask,
+		TaskId:          aiTaskId(task),
+		TaskType:        task.TaskType,
+		PictureId:       task.PictureId,
+		TaskStatus:      task.Status,
+		OutputImageUrl:  service.Bucket().SignedUrl(ctx, task.OutputUrl),
+		ResultPictureId: task.ResultPictureId,
+		ErrorMessage:    task.ErrorMessage,
+	})
+}
+
+// RecoverAITasks 处理执行超时的任务（如服务重启时正在执行）：未超过最大次数的重新排队，否则标记失败
+func (s *sPicture) RecoverAITasks(ctx context.Context) {
+	timeout := g.Cfg().MustGet(ctx, "picture.aiTask.timeout", defaultAITaskTimeout).Duration()
+	maxAttempts := g.Cfg().MustGet(ctx, "picture.aiTask.maxAttempts", defaultAITaskMaxAttempts).Int()
+	// 留出保存结果的时间，避免误判仍在执行的任务
+	deadline := gtime.Now().Add(-2 * timeout)
+
+	at := dao.AiTask.Columns()
+	result, err := dao.AiTask.Ctx(ctx).Where(at.Status, aiTaskRunning).
+		WhereLT(at.StartTime, deadline).WhereLT(at.Attempts, maxAttempts).
+		Data(do.AiTask{
+			Status:       aiTaskPending,
+			ErrorMessage: "任务执行超时",
+			NextRunTime:  gtime.Now(),
+			UpdateTime:   gtime.Now(),
+		}).Update()
+	if err != nil {
+		g.Log().Errorf(ctx, "重新排队超时的AI任务失败: %v", err)
+		return
+	}
+	requeued, _ := result.RowsAffected()
+
+	var expired []entity.AiTask
+	if err = dao.AiTask.Ctx(ctx).Where(at.Status, aiTaskRunning).
+		WhereLT(at.StartTime, deadline).Scan(&expired); err != nil {
+		g.Log().Errorf(ctx, "查询超时的AI任务失败: %v", err)
+		return
+	}
+	for i := range expired {
+		task := &expired[i]
+		result, err = dao.AiTask.Ctx(ctx).Where(at.Id, task.Id).
+			Where(at.Status, aiTaskRunning).Where(at.Attempts, task.Attempts).
+			Data(do.AiTask{
+				Status:       aiTaskFailed,
+				ErrorMessage: "任务执行超时",
+				FinishTime:   gtime.Now(),
+				UpdateTime:   gtime.Now(),
+			}).Update()
+		if err != nil {
+			continue
+		}
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			task.Status, task.ErrorMessage = aiTaskFailed, "任务执行超时"
+			s.notifyAITask(ctx, task)
+		}
+	}
+	if requeued > 0 || len(expired) > 0 {
+		g.Log().Infof(ctx, "处理超时的AI任务：重新排队 %d 个，标记失败 %d 个", requeued, len(expired))
+	}
+}
+
+// aiTaskBackoff 第 attempts 次失败后的重试间隔：按次数指数增长，不超过上限
+func aiTaskBackoff(base time.Duration, attempts int) time.Duration {
+	if base <= 0 {
+		base = defaultAITaskBackoff
+	}
+	delay := base
+	for i := 1; i < attempts && delay < maxAITaskBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxAITaskBackoff)
+}
+
+// aiTaskId 任务ID对外的字符串形式
+func aiTaskId(task *entity.AiTask) string {
+	return strconv.FormatInt(task.Id, 10)
+}
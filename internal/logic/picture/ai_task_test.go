@@ -0,0 +1,26 @@
+package picture
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_aiTaskBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{10, maxAITaskBackoff},
+	}
+	for _, tt := range tests {
+		if got := aiTaskBackoff(10*time.Second, tt.attempts); got != tt.want {
+			t.Errorf("aiTaskBackoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+	if got := aiTaskBackoff(0, 1); got != defaultAITaskBackoff {
+		t.Errorf("未配置时应使用默认间隔, got=%s", got)
+	}
+}
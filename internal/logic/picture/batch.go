@@ -7,12 +7,12 @@ import (
 	"cloud/internal/model/do"
 	"cloud/internal/model/entity"
 	"cloud/internal/service"
+	"cloud/utility/safefetch"
 	"context"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
-	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/gogf/gf/v2/database/gdb"
@@ -36,24 +36,21 @@ func (s *sPicture) UploadByBatch(ctx context.Context, req *v1.PictureUploadByBat
 
 	g.Log().Infof(ctx, "开始批量抓取图片，搜索关键词: %s, 数量: %d", req.SearchText, req.Count)
 
-	// 创建HTTP客户端
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
-	// 发送HTTP请求
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", fetchURL, nil)
+	// 使用防SSRF的下载客户端（抓取到的图片地址同样经过校验）
+	client, err := safefetch.Default(ctx)
 	if err != nil {
-		g.Log().Errorf(ctx, "创建HTTP请求失败: %v", err)
+		g.Log().Errorf(ctx, "初始化下载客户端失败: %v", err)
 		return nil, gerror.New("创建请求失败")
 	}
 
 	// 设置请求头，模拟浏览器
-	httpReq.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-	httpReq.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
-	httpReq.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
+	header := http.Header{}
+	header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+	header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
+	header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
 
-	resp, err := client.Do(httpReq)
+	// 发送HTTP请求
+	resp, err := client.Get(ctx, fetchURL, header)
 	if err != nil {
 		g.Log().Errorf(ctx, "获取页面失败: %v", err)
 		return nil, gerror.New("获取页面失败")
@@ -93,16 +90,18 @@ func (s *sPicture) UploadByBatch(ctx context.Context, req *v1.PictureUploadByBat
 	g.Log().Infof(ctx, "找到 %d 个图片元素", imgElements.Length())
 
 	uploadCount := 0
-	imgElements.Each(func(i int, selection *goquery.Selection) {
+	failCount := 0
+	quotaExceeded := false
+	imgElements.EachWithBreak(func(i int, selection *goquery.Selection) bool {
 		// 如果已经达到目标数量，停止处理
 		if uploadCount >= req.Count {
-			return // 停止整个Each循环
+			return false // 停止整个Each循环
 		}
 
 		fileURL, exists := selection.Attr("src")
 		if !exists || fileURL == "" {
 			g.Log().Infof(ctx, "当前链接为空，已跳过: %s", fileURL)
-			return // 跳过当前图片，继续下一张
+			return true // 跳过当前图片，继续下一张
 		}
 
 		// 处理图片地址，防止转义或者和对象存储冲突的问题
@@ -127,19 +126,33 @@ func (s *sPicture) UploadByBatch(ctx context.Context, req *v1.PictureUploadByBat
 
 		_, uploadErr := s.UploadByUrl(ctx, uploadReq)
 		if uploadErr != nil {
+			// 空间额度不足时后续图片也无法上传，直接结束并保留已成功的部分
+			if gerror.Code(uploadErr) == consts.CodeSpaceQuotaExceeded {
+				g.Log().Warningf(ctx, "空间额度不足，停止批量上传: %v", uploadErr)
+				quotaExceeded = true
+				return false
+			}
 			g.Log().Errorf(ctx, "图片上传失败: %v, URL: %s", uploadErr, fileURL)
-			return // 跳过当前图片，继续下一张
+			failCount++
+			return true // 跳过当前图片，继续下一张
 		}
 
 		g.Log().Infof(ctx, "图片上传成功，当前已上传: %d 张", uploadCount+1)
 		uploadCount++
+		return true
 	})
 
-	g.Log().Infof(ctx, "批量上传完成，成功上传 %d 张图片", uploadCount)
+	g.Log().Infof(ctx, "批量上传完成，成功上传 %d 张图片，失败 %d 张", uploadCount, failCount)
 
-	return &v1.PictureUploadByBatchRes{
-		SuccessCount: uploadCount,
-	}, nil
+	res = &v1.PictureUploadByBatchRes{
+		SuccessCount:  uploadCount,
+		FailCount:     failCount,
+		QuotaExceeded: quotaExceeded,
+	}
+	if quotaExceeded {
+		res.Message = fmt.Sprintf("空间额度不足，已成功上传 %d 张图片", uploadCount)
+	}
+	return res, nil
 }
 
 // EditByBatch 批量编辑图片
@@ -225,11 +238,17 @@ func (s *sPicture) EditByBatch(ctx context.Context, req *v1.PictureEditByBatchRe
 			}
 
 			// 更新图片信息
-			_, updateErr := dao.Picture.Ctx(ctx).Where(pic.Id, picture.Id).Data(updateData).Update()
+			_, updateErr := dao.Picture.Ctx(ctx).TX(tx).Where(pic.Id, picture.Id).Data(updateData).Update()
 			if updateErr != nil {
 				g.Log().Errorf(ctx, "更新图片失败，ID: %d, 错误: %v", picture.Id, updateErr)
 				return gerror.Newf("更新图片失败，ID: %d", picture.Id)
 			}
+			if logErr := s.recordReviewReset(ctx, tx, picture, user.Id); logErr != nil {
+				return logErr
+			}
+			if versionErr := s.recordVersion(ctx, tx, picture, versionActionBatch, user.Id, ""); versionErr != nil {
+				return versionErr
+			}
 
 			successCount++
 		}
@@ -241,6 +260,10 @@ func (s *sPicture) EditByBatch(ctx context.Context, req *v1.PictureEditByBatchRe
 	}
 
 	g.Log().Infof(ctx, "批量编辑完成，成功处理了 %d 张图片", successCount)
+	for _, picture := range pictures {
+		s.pruneVersions(ctx, picture.Id)
+		s.moderateAsync(ctx, picture.Id)
+	}
 
 	return &v1.PictureEditByBatchRes{
 		Success: true,
@@ -0,0 +1,163 @@
+package picture
+
+import (
+	v1 "cloud/api/user/v1"
+	"cloud/internal/consts"
+	"cloud/internal/dao"
+	"cloud/internal/model/do"
+	"cloud/internal/model/entity"
+	"cloud/internal/service"
+	"context"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+// AddBlocklist 将图片加入黑名单，并拒绝所有内容相同的图片
+func (s *sPicture) AddBlocklist(ctx context.Context, req *v1.PictureBlocklistAddReq) (res *v1.PictureBlocklistAddRes, err error) {
+	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
+	if err != nil {
+		return nil, err
+	}
+	if user.UserRole != consts.Admin {
+		return nil, gerror.New("无权限操作黑名单")
+	}
+
+	var picture *entity.Picture
+	pic := dao.Picture.Columns()
+	if err = dao.Picture.Ctx(ctx).Where(pic.Id, req.PictureId).Scan(&picture); err != nil || picture == nil {
+		return nil, gerror.New("图片不存在")
+	}
+	if picture.ContentHash == "" {
+		return nil, gerror.New("图片缺少内容哈希，无法加入黑名单")
+	}
+
+	var (
+		id       int64
+		rejected int64
+	)
+	reason := "图片已被列入黑名单：" + req.Reason
+	bl := dao.PictureBlocklist.Columns()
+	err = g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		var existing *entity.PictureBlocklist
+		if scanErr := dao.PictureBlocklist.Ctx(ctx).TX(tx).Where(bl.ContentHash, picture.ContentHash).Scan(&existing); scanErr != nil {
+			return gerror.New("查询黑名单失败")
+		}
+		if existing != nil {
+			id = existing.Id
+		} else {
+			result, inErr := dao.PictureBlocklist.Ctx(ctx).TX(tx).Data(do.PictureBlocklist{
+				ContentHash: picture.ContentHash,
+				PicHash:     picture.PicHash,
+				Reason:      req.Reason,
+				PictureId:   picture.Id,
+				UserId:      user.Id,
+				CreateTime:  gtime.Now(),
+			}).Insert()
+			if inErr != nil {
+				g.Log().Errorf(ctx, "加入黑名单失败: %v", inErr)
+				return gerror.New("加入黑名单失败")
+			}
+			if id, inErr = result.LastInsertId(); inErr != nil {
+				return inErr
+			}
+		}
+
+		var matched []entity.Picture
+		if scanErr := dao.Picture.Ctx(ctx).TX(tx).Fields(pic.Id, pic.ReviewStatus).
+			Where(pic.ContentHash, picture.ContentHash).Where(pic.IsDelete, 0).
+			LockUpdate().Scan(&matched); scanErr != nil {
+			return gerror.New("查询图片失败")
+		}
+		result, upErr := dao.Picture.Ctx(ctx).TX(tx).Where(pic.ContentHash, picture.ContentHash).
+			Where(pic.IsDelete, 0).Data(do.Picture{
+			ReviewStatus:   2,
+			ReviewMessage:  reason,
+			ReviewerId:     user.Id,
+			ReviewTime:     gtime.Now(),
+			RiskScore:      100,
+			ModerationRule: "blocklist.exact",
+			UpdateTime:     gtime.Now(),
+		}).Update()
+		if upErr != nil {
+			return gerror.New("拒绝图片失败")
+		}
+		rejected, _ = result.RowsAffected()
+		for _, item := range matched {
+			if logErr := recordReviewLog(ctx, tx, do.PictureReviewLog{
+				PictureId:  item.Id,
+				ReviewerId: user.Id,
+				OldStatus:  item.ReviewStatus,
+				NewStatus:  2,
+				Message:    reason,
+				Source:     reviewSourceBlocklist,
+				Rule:       "blocklist.exact",
+				RiskScore:  100,
+			}); logErr != nil {
+				return logErr
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g.Log().Infof(ctx, "图片 id=%d 加入黑名单，管理员ID: %d，拒绝图片数: %d", picture.Id, user.Id, rejected)
+	return &v1.PictureBlocklistAddRes{
+		Id:            id,
+		RejectedCount: int(rejected),
+	}, nil
+}
+
+// ListBlocklist 分页查询图片黑名单
+func (s *sPicture) ListBlocklist(ctx context.Context, req *v1.PictureBlocklistListReq) (res *v1.PictureBlocklistListRes, err error) {
+	bl := dao.PictureBlocklist.Columns()
+	db := dao.PictureBlocklist.Ctx(ctx)
+	total, err := db.Count()
+	if err != nil {
+		return nil, gerror.New("查询失败")
+	}
+	var entries []entity.PictureBlocklist
+	if err = db.Page(req.Current, req.PageSize).OrderDesc(bl.Id).Scan(&entries); err != nil {
+		return nil, gerror.New("查询失败")
+	}
+
+	records := make([]v1.PictureBlocklistVO, 0, len(entries))
+	for _, entry := range entries {
+		records = append(records, v1.PictureBlocklistVO{
+			Id:          entry.Id,
+			ContentHash: entry.ContentHash,
+			PicHash:     entry.PicHash,
+			Reason:      entry.Reason,
+			PictureId:   entry.PictureId,
+			UserId:      entry.UserId,
+			CreateTime:  entry.CreateTime.Format(consts.Y_m_d_His),
+		})
+	}
+	return &v1.PictureBlocklistListRes{
+		Records: records,
+		PageInfo: &v1.PageInfo{
+			Current: req.Current,
+			Size:    req.PageSize,
+			Total:   total,
+			Pages:   (total + req.PageSize - 1) / req.PageSize,
+		},
+	}, nil
+}
+
+// DeleteBlocklist 移出图片黑名单，已拒绝的图片不会自动恢复
+func (s *sPicture) DeleteBlocklist(ctx context.Context, req *v1.PictureBlocklistDeleteReq) (res *v1.PictureBlocklistDeleteRes, err error) {
+	result, err := dao.PictureBlocklist.Ctx(ctx).Where(dao.PictureBlocklist.Columns().Id, req.Id).Delete()
+	if err != nil {
+		return nil, gerror.New("移出黑名单失败")
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, gerror.New("黑名单记录不存在")
+	}
+	return &v1.PictureBlocklistDeleteRes{
+		Success: true,
+	}, nil
+}
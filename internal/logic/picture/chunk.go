@@ -0,0 +1,396 @@
+package picture
+
+import (
+	v1 "cloud/api/user/v1"
+	"cloud/internal/consts"
+	"cloud/internal/service"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gredis"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+const (
+	uploadSessionKey    = "picture:upload:session:"  // 分片上传会话信息
+	uploadChunksKey     = "picture:upload:chunks:"   // 已上传的分片序号集合
+	uploadCompleteKey   = "picture:upload:complete:" // 合并分片时的互斥锁
+	defaultChunkSize    = "5MB"                      // 默认分片大小
+	defaultChunkMaxSize = defaultPictureMaxSize      // 默认分片上传文件大小上限，合并后整个文件需在内存中解码
+	defaultChunkExpire  = 24 * time.Hour             // 默认会话有效期
+)
+
+// uploadSession 分片上传会话
+type uploadSession struct {
+	UploadId   string `json:"uploadId"`
+	UserId     int64  `json:"userId"`
+	SpaceId    int64  `json:"spaceId"`
+	FileName   string `json:"fileName"`
+	FileSize   int64  `json:"fileSize"`
+	Checksum   string `json:"checksum"`
+	ChunkSize  int64  `json:"chunkSize"`
+	ChunkCount int    `json:"chunkCount"`
+}
+
+// chunkLength 分片应有的大小：除最后一片外与会话约定的分片大小一致
+func (u *uploadSession) chunkLength(index int) int64 {
+	if index == u.ChunkCount-1 {
+		return u.FileSize - int64(index)*u.ChunkSize
+	}
+	return u.ChunkSize
+}
+
+// InitUpload 初始化分片上传会话
+func (s *sPicture) InitUpload(ctx context.Context, req *v1.PictureUploadInitReq) (res *v1.PictureUploadInitRes, err error) {
+	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
+	if err != nil {
+		return nil, gerror.New("用户未登录")
+	}
+
+	// 合并后的文件交由入库流程整体解码，不能超过图片文件大小上限
+	if maxSize := min(s.chunkConfigSize(ctx, "maxFileSize", defaultChunkMaxSize), pictureMaxSize(ctx)); req.FileSize > maxSize {
+		return nil, gerror.Newf("文件大小超过限制（最大 %s）", gfile.FormatSize(maxSize))
+	}
+	checksum := strings.ToLower(req.Checksum)
+	if _, decodeErr := hex.DecodeString(checksum); decodeErr != nil {
+		return nil, gerror.New("文件校验值必须为SHA-256十六进制字符串")
+	}
+	if req.SpaceId > 0 {
+		if !s.hasSpacePermission(ctx, req.SpaceId, user) {
+			return nil, gerror.New("无权限上传到该空间")
+		}
+		// 提前检查空间额度，避免上传完所有分片后才失败
+		if err = service.Space().CheckQuota(ctx, req.SpaceId, req.FileSize, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	chunkSize := s.chunkConfigSize(ctx, "chunkSize", defaultChunkSize)
+	session := &uploadSession{
+		UploadId:   guid.S(),
+		UserId:     user.Id,
+		SpaceId:    req.SpaceId,
+		FileName:   req.FileName,
+		FileSize:   req.FileSize,
+		Checksum:   checksum,
+		ChunkSize:  chunkSize,
+		ChunkCount: int((req.FileSize + chunkSize - 1) / chunkSize),
+	}
+	expire := s.chunkExpire(ctx)
+	_, err = g.Redis().Set(ctx, uploadSessionKey+session.UploadId, session, gredis.SetOption{
+		TTLOption: gredis.TTLOption{EX: gconv.PtrInt64(int64(expire.Seconds()))},
+	})
+	if err != nil {
+		g.Log().Errorf(ctx, "保存分片上传会话失败: %v", err)
+		return nil, gerror.New("初始化上传失败")
+	}
+	g.Log().Infof(ctx, "初始化分片上传 uploadId=%s，文件: %s，大小: %d，分片数: %d", session.UploadId, session.FileName, session.FileSize, session.ChunkCount)
+
+	return &v1.PictureUploadInitRes{
+		UploadId:   session.UploadId,
+		ChunkSize:  session.ChunkSize,
+		ChunkCount: session.ChunkCount,
+		ExpireTime: gtime.Now().Add(expire).Format(consts.Y_m_d_His),
+	}, nil
+}
+
+// UploadChunk 上传单个分片，重复上传同一分片时覆盖
+func (s *sPicture) UploadChunk(ctx context.Context, req *v1.PictureUploadChunkReq, file *ghttp.UploadFile) (res *v1.PictureUploadChunkRes, err error) {
+	session, err := s.getUploadSession(ctx, req.UploadId)
+	if err != nil {
+		return nil, err
+	}
+	if req.Index >= session.ChunkCount {
+		return nil, gerror.Newf("分片序号超出范围（0-%d）", session.ChunkCount-1)
+	}
+
+	data, err := s.readUploadFile(ctx, file)
+	if err != nil {
+		return nil, gerror.New("读取分片失败")
+	}
+	if expected := session.chunkLength(req.Index); int64(len(data)) != expected {
+		return nil, gerror.Newf("分片大小错误，应为 %d 字节，实际 %d 字节", expected, len(data))
+	}
+
+	// 先写临时文件再重命名，避免中断时留下不完整的分片
+	path := s.chunkPath(ctx, session.UploadId, req.Index)
+	if err = gfile.PutBytes(path+".tmp", data); err == nil {
+		err = os.Rename(path+".tmp", path)
+	}
+	if err != nil {
+		g.Log().Errorf(ctx, "保存分片失败 uploadId=%s index=%d: %v", session.UploadId, req.Index, err)
+		return nil, gerror.New("保存分片失败")
+	}
+	if _, err = g.Redis().SAdd(ctx, uploadChunksKey+session.UploadId, req.Index); err != nil {
+		g.Log().Errorf(ctx, "记录分片失败 uploadId=%s index=%d: %v", session.UploadId, req.Index, err)
+		return nil, gerror.New("保存分片失败")
+	}
+
+	// 有新分片上传时续期会话
+	expire := int64(s.chunkExpire(ctx).Seconds())
+	_, _ = g.Redis().Expire(ctx, uploadSessionKey+session.UploadId, expire)
+	_, _ = g.Redis().Expire(ctx, uploadChunksKey+session.UploadId, expire)
+
+	status, err := s.uploadStatus(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.PictureUploadChunkRes{PictureUploadStatusRes: status}, nil
+}
+
+// GetUploadStatus 查询分片上传进度（断点续传时获取已上传的分片）
+func (s *sPicture) GetUploadStatus(ctx context.Context, req *v1.PictureUploadSessionReq) (res *v1.PictureUploadStatusRes, err error) {
+	session, err := s.getUploadSession(ctx, req.UploadId)
+	if err != nil {
+		return nil, err
+	}
+	return s.uploadStatus(ctx, session)
+}
+
+// CompleteUpload 合并分片并校验文件，交由普通上传流程入库（含空间额度占用）
+func (s *sPicture) CompleteUpload(ctx context.Context, req *v1.PictureUploadSessionReq) (res *v1.PictureUploadRes, err error) {
+	session, err := s.getUploadSession(ctx, req.UploadId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 防止重复提交导致同一文件入库多次
+	lockKey := uploadCompleteKey + session.UploadId
+	locked, err := g.Redis().Set(ctx, lockKey, 1, gredis.SetOption{
+		TTLOption: gredis.TTLOption{EX: gconv.PtrInt64(60)},
+		NX:        true,
+	})
+	if err != nil || locked.IsNil() {
+		return nil, gerror.New("文件正在合并，请勿重复提交")
+	}
+	defer func() {
+		_, _ = g.Redis().Del(ctx, lockKey)
+	}()
+
+	uploaded, err := s.uploadedChunks(ctx, session.UploadId)
+	if err != nil {
+		return nil, err
+	}
+	if len(uploaded) != session.ChunkCount {
+		return nil, gerror.Newf("分片未上传完整（%d/%d）", len(uploaded), session.ChunkCount)
+	}
+
+	// 先按序流式校验大小与校验值，校验通过后才把分片合并到内存，校验失败的上传不占用整个文件的内存
+	paths := make([]string, 0, session.ChunkCount)
+	for i := 0; i < session.ChunkCount; i++ {
+		paths = append(paths, s.chunkPath(ctx, session.UploadId, i))
+	}
+	if err = verifyChunks(paths, session.FileSize, session.Checksum); err != nil {
+		g.Log().Warningf(ctx, "分片校验失败 uploadId=%s: %v", session.UploadId, err)
+		return nil, err
+	}
+	data, err := mergeChunks(paths, session.FileSize)
+	if err != nil {
+		g.Log().Errorf(ctx, "合并分片失败 uploadId=%s: %v", session.UploadId, err)
+		return nil, err
+	}
+
+	// 入库失败（如空间额度不足）时保留会话，便于处理后重试
+	pictureVO, err := s.uploadData(ctx, session.UserId, session.SpaceId, session.FileName, data, uploadOptions{})
+	if err != nil {
+		return nil, err
+	}
+	s.removeUploadSession(ctx, session.UploadId)
+	g.Log().Infof(ctx, "分片上传完成 uploadId=%s，图片ID: %d", session.UploadId, pictureVO.Id)
+
+	return &v1.PictureUploadRes{
+		PictureVO: pictureVO,
+	}, nil
+}
+
+// AbortUpload 取消分片上传，删除已上传的分片
+func (s *sPicture) AbortUpload(ctx context.Context, req *v1.PictureUploadSessionReq) (res *v1.PictureUploadAbortRes, err error) {
+	session, err := s.getUploadSession(ctx, req.UploadId)
+	if err != nil {
+		return nil, err
+	}
+	s.removeUploadSession(ctx, session.UploadId)
+	return &v1.PictureUploadAbortRes{Success: true}, nil
+}
+
+// CleanupUploadSessions 清理已过期会话遗留的分片临时文件与超时未完成的直传对象
+func (s *sPicture) CleanupUploadSessions(ctx context.Context) {
+	s.cleanupDirectUploads(ctx)
+
+	root := s.chunkTempDir(ctx)
+	dirs, err := gfile.ScanDir(root, "*", false)
+	if err != nil {
+		// 目录不存在说明还没有分片上传
+		return
+	}
+	cleaned := 0
+	for _, dir := range dirs {
+		if !gfile.IsDir(dir) {
+			continue
+		}
+		exists, existsErr := g.Redis().Exists(ctx, uploadSessionKey+filepath.Base(dir))
+		if existsErr != nil {
+			g.Log().Errorf(ctx, "查询分片上传会话失败: %v", existsErr)
+			return
+		}
+		if exists > 0 {
+			continue
+		}
+		if removeErr := gfile.Remove(dir); removeErr != nil {
+			g.Log().Errorf(ctx, "清理分片临时文件失败 %s: %v", dir, removeErr)
+			continue
+		}
+		cleaned++
+	}
+	if cleaned > 0 {
+		g.Log().Infof(ctx, "已清理 %d 个过期的分片上传会话", cleaned)
+	}
+}
+
+// getUploadSession 获取当前用户的分片上传会话
+func (s *sPicture) getUploadSession(ctx context.Context, uploadId string) (*uploadSession, error) {
+	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
+	if err != nil {
+		return nil, gerror.New("用户未登录")
+	}
+	value, err := g.Redis().Get(ctx, uploadSessionKey+uploadId)
+	if err != nil {
+		g.Log().Errorf(ctx, "查询分片上传会话失败: %v", err)
+		return nil, gerror.New("查询上传会话失败")
+	}
+	var session *uploadSession
+	if value.IsNil() || value.Scan(&session) != nil || session == nil {
+		return nil, gerror.New("上传会话不存在或已过期")
+	}
+	if session.UserId != user.Id {
+		return nil, gerror.New("无权操作该上传会话")
+	}
+	return session, nil
+}
+
+// uploadStatus 会话的上传进度
+func (s *sPicture) uploadStatus(ctx context.Context, session *uploadSession) (*v1.PictureUploadStatusRes, error) {
+	uploaded, err := s.uploadedChunks(ctx, session.UploadId)
+	if err != nil {
+		return nil, err
+	}
+	status := &v1.PictureUploadStatusRes{
+		UploadId:   session.UploadId,
+		ChunkSize:  session.ChunkSize,
+		ChunkCount: session.ChunkCount,
+		Uploaded:   uploaded,
+	}
+	if ttl, ttlErr := g.Redis().TTL(ctx, uploadSessionKey+session.UploadId); ttlErr == nil && ttl > 0 {
+		status.ExpireTime = gtime.Now().Add(time.Duration(ttl) * time.Second).Format(consts.Y_m_d_His)
+	}
+	return status, nil
+}
+
+// uploadedChunks 已上传的分片序号（升序）
+func (s *sPicture) uploadedChunks(ctx context.Context, uploadId string) ([]int, error) {
+	members, err := g.Redis().SMembers(ctx, uploadChunksKey+uploadId)
+	if err != nil {
+		g.Log().Errorf(ctx, "查询已上传分片失败 uploadId=%s: %v", uploadId, err)
+		return nil, gerror.New("查询上传进度失败")
+	}
+	uploaded := members.Ints()
+	sort.Ints(uploaded)
+	return uploaded, nil
+}
+
+// removeUploadSession 删除会话信息与分片临时文件
+func (s *sPicture) removeUploadSession(ctx context.Context, uploadId string) {
+	if _, err := g.Redis().Del(ctx, uploadSessionKey+uploadId, uploadChunksKey+uploadId); err != nil {
+		g.Log().Errorf(ctx, "删除分片上传会话失败 uploadId=%s: %v", uploadId, err)
+	}
+	if err := gfile.Remove(filepath.Join(s.chunkTempDir(ctx), uploadId)); err != nil {
+		// 残留文件由定时任务清理
+		g.Log().Warningf(ctx, "删除分片临时文件失败 uploadId=%s: %v", uploadId, err)
+	}
+}
+
+// chunkPath 分片临时文件路径
+func (s *sPicture) chunkPath(ctx context.Context, uploadId string, index int) string {
+	return filepath.Join(s.chunkTempDir(ctx), uploadId, fmt.Sprintf("%d.part", index))
+}
+
+// verifyChunks 按序流式计算分片的总大小与SHA-256，与会话约定不一致时返回错误
+func verifyChunks(paths []string, fileSize int64, checksum string) error {
+	hash := sha256.New()
+	var total int64
+	for i, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			return gerror.Newf("分片 %d 已丢失，请重新上传", i)
+		}
+		n, err := io.Copy(hash, f)
+		f.Close()
+		if err != nil {
+			return gerror.Newf("读取分片 %d 失败，请重新上传", i)
+		}
+		total += n
+	}
+	if total != fileSize {
+		return gerror.Newf("文件大小不一致，应为 %d 字节，实际 %d 字节", fileSize, total)
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != checksum {
+		return gerror.New("文件校验失败，请重新上传分片")
+	}
+	return nil
+}
+
+// mergeChunks 按序读取已校验的分片，合并为完整的文件内容
+func mergeChunks(paths []string, fileSize int64) ([]byte, error) {
+	data := make([]byte, 0, fileSize)
+	for i, p := range paths {
+		chunk, err := os.ReadFile(p)
+		if err != nil {
+			return nil, gerror.Newf("分片 %d 已丢失，请重新上传", i)
+		}
+		data = append(data, chunk...)
+	}
+	if int64(len(data)) != fileSize {
+		return nil, gerror.New("分片在合并期间被修改，请重新提交")
+	}
+	return data, nil
+}
+
+// chunkTempDir 分片临时文件目录
+func (s *sPicture) chunkTempDir(ctx context.Context) string {
+	if dir := g.Cfg().MustGet(ctx, "picture.chunkUpload.tempDir").String(); dir != "" {
+		return dir
+	}
+	return gfile.Temp("cloud-picture-upload")
+}
+
+// chunkConfigSize 读取分片上传的大小配置（支持 5MB 等写法）
+func (s *sPicture) chunkConfigSize(ctx context.Context, name string, def string) int64 {
+	size := gfile.StrToSize(g.Cfg().MustGet(ctx, "picture.chunkUpload."+name, def).String())
+	if size <= 0 {
+		size = gfile.StrToSize(def)
+	}
+	return size
+}
+
+// chunkExpire 分片上传会话有效期
+func (s *sPicture) chunkExpire(ctx context.Context) time.Duration {
+	expire := g.Cfg().MustGet(ctx, "picture.chunkUpload.expire", defaultChunkExpire).Duration()
+	if expire <= 0 {
+		expire = defaultChunkExpire
+	}
+	return expire
+}
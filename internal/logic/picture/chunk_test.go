@@ -0,0 +1,70 @@
+package picture
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_uploadSession_chunkLength(t *testing.T) {
+	session := &uploadSession{FileSize: 12, ChunkSize: 5, ChunkCount: 3}
+	for index, want := range []int64{5, 5, 2} {
+		if got := session.chunkLength(index); got != want {
+			t.Errorf("分片 %d 大小错误: %d, want %d", index, got, want)
+		}
+	}
+	// 文件大小恰好是分片大小的整数倍时，最后一片也是完整大小
+	session = &uploadSession{FileSize: 10, ChunkSize: 5, ChunkCount: 2}
+	if got := session.chunkLength(1); got != 5 {
+		t.Errorf("最后一片大小错误: %d", got)
+	}
+}
+
+func Test_verifyChunks(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("hello, chunked world")
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+	paths := writeChunks(t, dir, content, 8)
+
+	if err := verifyChunks(paths, int64(len(content)), checksum); err != nil {
+		t.Fatalf("分片校验失败: %v", err)
+	}
+	data, err := mergeChunks(paths, int64(len(content)))
+	if err != nil || string(data) != string(content) {
+		t.Fatalf("合并结果错误: %q %v", data, err)
+	}
+
+	// 分片顺序错乱时总大小不变，但校验值不一致
+	swapped := []string{paths[1], paths[0], paths[2]}
+	if err = verifyChunks(swapped, int64(len(content)), checksum); err == nil {
+		t.Error("分片顺序错乱应校验失败")
+	}
+	if err = verifyChunks(paths, int64(len(content))+1, checksum); err == nil {
+		t.Error("文件大小不一致应校验失败")
+	}
+	if err = os.Remove(paths[2]); err != nil {
+		t.Fatal(err)
+	}
+	if err = verifyChunks(paths, int64(len(content)), checksum); err == nil {
+		t.Error("分片丢失应校验失败")
+	}
+}
+
+// writeChunks 按分片大小将内容写入 0.part、1.part……，返回按序的分片路径
+func writeChunks(t *testing.T, dir string, content []byte, chunkSize int) []string {
+	t.Helper()
+	var paths []string
+	for i := 0; i*chunkSize < len(content); i++ {
+		end := min((i+1)*chunkSize, len(content))
+		p := filepath.Join(dir, fmt.Sprintf("%d.part", i))
+		if err := os.WriteFile(p, content[i*chunkSize:end], 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
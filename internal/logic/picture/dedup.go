@@ -0,0 +1,70 @@
+package picture
+
+import (
+	"cloud/internal/dao"
+	"cloud/internal/model/entity"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// contentHash 计算图片内容的SHA-256
+func (s *sPicture) contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// findDuplicate 在同一范围（同一空间，或公共图库）内查找内容相同的图片，优先返回当前用户自己的图片
+func (s *sPicture) findDuplicate(ctx context.Context, contentHash string, spaceId int64, userId int64) *entity.Picture {
+	if contentHash == "" {
+		return nil
+	}
+	pic := dao.Picture.Columns()
+	db := dao.Picture.Ctx(ctx).Where(pic.ContentHash, contentHash).
+		Where(pic.IsDelete, 0)
+	if spaceId > 0 {
+		db = db.Where(pic.SpaceId, spaceId)
+	} else {
+		db = s.wherePublic(db)
+	}
+
+	var pictures []entity.Picture
+	if err := db.OrderAsc(pic.Id).Scan(&pictures); err != nil {
+		g.Log().Errorf(ctx, "查询重复图片失败: %v", err)
+		return nil
+	}
+	return preferDuplicate(pictures, userId)
+}
+
+// preferDuplicate 从内容相同的图片中选出复用的一张：优先当前用户自己的图片，否则取最早上传的
+func preferDuplicate(pictures []entity.Picture, userId int64) *entity.Picture {
+	if len(pictures) == 0 {
+		return nil
+	}
+	for i := range pictures {
+		if pictures[i].UserId == userId {
+			return &pictures[i]
+		}
+	}
+	return &pictures[0]
+}
+
+// objectShared 判断图片的存储对象是否仍被其他图片记录或其他图片的历史版本引用（去重复用的对象不能随单条记录删除）
+func (s *sPicture) objectShared(ctx context.Context, picture *entity.Picture) bool {
+	pic := dao.Picture.Columns()
+	count, err := dao.Picture.Ctx(ctx).Where(pic.Url, picture.Url).
+		WhereNot(pic.Id, picture.Id).Count()
+	if err == nil && count == 0 {
+		pv := dao.PictureVersion.Columns()
+		count, err = dao.PictureVersion.Ctx(ctx).Where(pv.Url, picture.Url).
+			WhereNot(pv.PictureId, picture.Id).Count()
+	}
+	if err != nil {
+		// 查询失败时保守处理，保留对象
+		g.Log().Errorf(ctx, "查询对象引用失败 id=%d: %v", picture.Id, err)
+		return true
+	}
+	return count > 0
+}
@@ -0,0 +1,36 @@
+package picture
+
+import (
+	"cloud/internal/model/entity"
+	"testing"
+)
+
+func Test_contentHash(t *testing.T) {
+	s := &sPicture{}
+	if got := s.contentHash([]byte("abc")); got != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Errorf("内容哈希错误: %s", got)
+	}
+	if s.contentHash([]byte("abc")) == s.contentHash([]byte("abd")) {
+		t.Error("不同内容的哈希不应相同")
+	}
+}
+
+func Test_preferDuplicate(t *testing.T) {
+	if got := preferDuplicate(nil, 1); got != nil {
+		t.Errorf("没有重复图片时应返回nil, got=%+v", got)
+	}
+
+	pictures := []entity.Picture{
+		{Id: 1, UserId: 7, Url: "/upload/a.jpg"},
+		{Id: 2, UserId: 8, Url: "/upload/a.jpg"},
+		{Id: 3, UserId: 8, Url: "/upload/a.jpg"},
+	}
+	// 当前用户已上传过：直接返回自己最早的那张
+	if got := preferDuplicate(pictures, 8); got == nil || got.Id != 2 {
+		t.Errorf("应优先返回自己的图片, got=%+v", got)
+	}
+	// 其他用户上传过：复用最早上传的对象
+	if got := preferDuplicate(pictures, 9); got == nil || got.Id != 1 {
+		t.Errorf("应复用最早上传的图片, got=%+v", got)
+	}
+}
@@ -0,0 +1,182 @@
+package picture
+
+import (
+	v1 "cloud/api/user/v1"
+	"cloud/internal/consts"
+	"cloud/internal/service"
+	"context"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gredis"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+const (
+	directSessionKey     = "picture:upload:direct:"        // 直传会话信息
+	directPendingKey     = "picture:upload:direct:pending" // 未完成的直传对象key（score为过期时间戳）
+	defaultDirectExpire  = 15 * time.Minute                // 默认预签名地址有效期
+	directFinalizeWindow = time.Hour                       // 上传地址过期后仍允许完成直传的时间
+)
+
+// directSession 直传会话
+type directSession struct {
+	UploadId  string `json:"uploadId"`
+	UserId    int64  `json:"userId"`
+	SpaceId   int64  `json:"spaceId"`
+	FileName  string `json:"fileName"`
+	FileSize  int64  `json:"fileSize"` // 申请时声明的文件大小，0为未声明
+	ObjectKey string `json:"objectKey"`
+}
+
+// PresignUpload 申请直传地址：预留对象key并生成限时的预签名PUT地址（存储驱动不支持时返回错误）
+func (s *sPicture) PresignUpload(ctx context.Context, req *v1.PictureUploadPresignReq) (res *v1.PictureUploadPresignRes, err error) {
+	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
+	if err != nil {
+		return nil, gerror.New("用户未登录")
+	}
+	if req.SpaceId > 0 {
+		if !s.hasSpacePermission(ctx, req.SpaceId, user) {
+			return nil, gerror.New("无权限上传到该空间")
+		}
+		if req.FileSize > 0 {
+			if err = service.Space().CheckQuota(ctx, req.SpaceId, req.FileSize, 1); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if maxSize := pictureMaxSize(ctx); req.FileSize > maxSize {
+		return nil, gerror.New("文件过大，请使用分片上传")
+	}
+
+	expire := g.Cfg().MustGet(ctx, "picture.directUpload.expire", defaultDirectExpire).Duration()
+	if expire <= 0 {
+		expire = defaultDirectExpire
+	}
+	session := &directSession{
+		UploadId:  guid.S(),
+		UserId:    user.Id,
+		SpaceId:   req.SpaceId,
+		FileName:  req.FileName,
+		FileSize:  req.FileSize,
+		ObjectKey: service.Bucket().NewObjectKey(req.SpaceId, req.FileName),
+	}
+	uploadUrl, err := service.Bucket().PresignPut(ctx, session.ObjectKey, expire)
+	if err != nil {
+		return nil, err
+	}
+
+	// 会话与待清理记录在上传地址过期后再保留一段时间，超时未完成的对象由定时任务删除
+	deadline := time.Now().Add(expire + directFinalizeWindow)
+	_, err = g.Redis().Set(ctx, directSessionKey+session.UploadId, session, gredis.SetOption{
+		TTLOption: gredis.TTLOption{EX: gconv.PtrInt64(int64((expire + directFinalizeWindow).Seconds()))},
+	})
+	if err == nil {
+		_, err = g.Redis().ZAdd(ctx, directPendingKey, nil, gredis.ZAddMember{
+			Score:  float64(deadline.Unix()),
+			Member: session.ObjectKey,
+		})
+	}
+	if err != nil {
+		g.Log().Errorf(ctx, "保存直传会话失败: %v", err)
+		return nil, gerror.New("生成上传地址失败")
+	}
+	g.Log().Infof(ctx, "生成直传地址 uploadId=%s，key: %s", session.UploadId, session.ObjectKey)
+
+	return &v1.PictureUploadPresignRes{
+		UploadId:   session.UploadId,
+		UploadUrl:  uploadUrl,
+		ExpireTime: gtime.Now().Add(expire).Format(consts.Y_m_d_His),
+	}, nil
+}
+
+// FinalizeUpload 完成直传：校验对象已上传，解析图片信息后按普通上传的额度与审核规则入库
+func (s *sPicture) FinalizeUpload(ctx context.Context, req *v1.PictureUploadFinalizeReq) (res *v1.PictureUploadRes, err error) {
+	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
+	if err != nil {
+		return nil, gerror.New("用户未登录")
+	}
+	value, err := g.Redis().Get(ctx, directSessionKey+req.UploadId)
+	if err != nil {
+		g.Log().Errorf(ctx, "查询直传会话失败: %v", err)
+		return nil, gerror.New("查询上传会话失败")
+	}
+	var session *directSession
+	if value.IsNil() || value.Scan(&session) != nil || session == nil {
+		return nil, gerror.New("上传会话不存在或已过期")
+	}
+	if session.UserId != user.Id {
+		return nil, gerror.New("无权操作该上传会话")
+	}
+
+	// 读取已直传的对象，对象不存在说明客户端尚未上传完成，保留会话以便重试
+	maxSize := pictureMaxSize(ctx)
+	data, err := service.Bucket().ReadObject(ctx, session.ObjectKey, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	// 内容不符时同样保留会话，客户端可在地址有效期内重新上传覆盖
+	if err = checkDirectObject(data, session.FileSize, maxSize); err != nil {
+		return nil, err
+	}
+
+	// 从待清理记录中认领对象，防止与定时清理或重复提交并发处理
+	claimed, err := g.Redis().ZRem(ctx, directPendingKey, session.ObjectKey)
+	if err != nil || claimed == 0 {
+		return nil, gerror.New("上传会话不存在或已过期")
+	}
+	_, _ = g.Redis().Del(ctx, directSessionKey+session.UploadId)
+
+	pictureVO, err := s.uploadData(ctx, session.UserId, session.SpaceId, session.FileName, data, uploadOptions{StoredKey: session.ObjectKey})
+	if err != nil {
+		return nil, err
+	}
+	g.Log().Infof(ctx, "直传完成 uploadId=%s，图片ID: %d", session.UploadId, pictureVO.Id)
+
+	return &v1.PictureUploadRes{
+		PictureVO: pictureVO,
+	}, nil
+}
+
+// checkDirectObject 校验直传的对象：大小与申请时声明的一致（额度按声明的大小预检查）且不超过上限，内容为可识别的图片
+func checkDirectObject(data []byte, declaredSize int64, maxSize int64) error {
+	size := int64(len(data))
+	if size == 0 {
+		return gerror.New("上传的文件为空")
+	}
+	if size > maxSize {
+		return gerror.Newf("文件大小超过限制（最大 %s）", gfile.FormatSize(maxSize))
+	}
+	if declaredSize > 0 && size != declaredSize {
+		return gerror.Newf("文件大小不一致，应为 %d 字节，实际 %d 字节", declaredSize, size)
+	}
+	if sniffFormat(data) == "" {
+		return gerror.New("文件内容不是有效的图片")
+	}
+	return nil
+}
+
+// cleanupDirectUploads 删除超时未完成的直传对象
+func (s *sPicture) cleanupDirectUploads(ctx context.Context) {
+	value, err := g.Redis().Do(ctx, "ZRANGEBYSCORE", directPendingKey, "-inf", time.Now().Unix())
+	if err != nil {
+		g.Log().Errorf(ctx, "查询超时的直传对象失败: %v", err)
+		return
+	}
+	cleaned := 0
+	for _, key := range value.Strings() {
+		// 认领成功才删除，避免与完成直传并发
+		if claimed, remErr := g.Redis().ZRem(ctx, directPendingKey, key); remErr != nil || claimed == 0 {
+			continue
+		}
+		s.deleteObjects(ctx, key)
+		cleaned++
+	}
+	if cleaned > 0 {
+		g.Log().Infof(ctx, "已清理 %d 个超时未完成的直传对象", cleaned)
+	}
+}
@@ -0,0 +1,37 @@
+package picture
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func Test_checkDirectObject(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	size := int64(len(data))
+
+	cases := []struct {
+		name         string
+		data         []byte
+		declaredSize int64
+		maxSize      int64
+		wantErr      bool
+	}{
+		{"有效图片", data, size, size, false},
+		{"未声明大小", data, 0, size, false},
+		{"空文件", nil, 0, size, true},
+		{"超过上限", data, size, size - 1, true},
+		{"与声明的大小不一致", data, size + 1, size * 2, true},
+		{"不是图片", []byte("<html>not an image</html>"), 0, size, true},
+	}
+	for _, c := range cases {
+		if err := checkDirectObject(c.data, c.declaredSize, c.maxSize); (err != nil) != c.wantErr {
+			t.Errorf("%s: err=%v, wantErr=%v", c.name, err, c.wantErr)
+		}
+	}
+}
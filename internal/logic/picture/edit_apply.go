@@ -0,0 +1,178 @@
+package picture
+
+import (
+	v1 "cloud/api/user/v1"
+	"cloud/internal/consts"
+	"cloud/internal/dao"
+	"cloud/internal/model/do"
+	"cloud/internal/model/entity"
+	wsmodel "cloud/internal/model/websocket"
+	"cloud/internal/service"
+	"context"
+	"image"
+
+	"github.com/disintegration/imaging"
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+// ApplyEditActions 将协同编辑会话中累积的旋转、翻转、裁剪操作应用到原图，保存为图片的新文件
+func (s *sPicture) ApplyEditActions(ctx context.Context, pictureId int64, userId int64, steps []wsmodel.PictureEditStep) (*v1.PictureVO, error) {
+	var picture *entity.Picture
+	pic := dao.Picture.Columns()
+	err := dao.Picture.Ctx(ctx).Where(pic.Id, pictureId).
+		Where(pic.IsDelete, 0).Scan(&picture)
+	if err != nil || picture == nil {
+		return nil, gerror.New("图片不存在")
+	}
+
+	originalKey := service.Bucket().GetFileKey(picture.Url)
+	data, err := service.Bucket().ReadObject(ctx, originalKey, pictureMaxSize(ctx))
+	if err != nil {
+		return nil, err
+	}
+	img, format, err := s.decodeImage(ctx, data)
+	if err != nil {
+		return nil, gerror.New("图片解码失败，无法编辑")
+	}
+	if img, err = applyEditSteps(img, steps); err != nil {
+		return nil, err
+	}
+
+	// PNG保留透明通道，其余格式统一输出JPEG
+	if format != "png" {
+		format = "jpeg"
+	}
+	edited, err := encodeImage(img, format)
+	if err != nil {
+		g.Log().Errorf(ctx, "编码编辑后的图片失败 id=%d: %v", pictureId, err)
+		return nil, gerror.New("保存编辑结果失败")
+	}
+	vo, err := s.replaceFile(ctx, picture, img, edited, format, userId, versionActionFileEdit, "")
+	if err != nil {
+		return nil, err
+	}
+	g.Log().Infof(ctx, "用户 %d 保存图片编辑 id=%d，共 %d 步操作", userId, pictureId, len(steps))
+	return vo, nil
+}
+
+// replaceFile 用新的图片内容替换图片文件：写入新对象与缩略图、更新图片信息与空间用量，并记录为新版本
+// 旧文件保留供版本回滚，超出保留版本数或删除图片时清理；编辑后的图片需重新审核
+func (s *sPicture) replaceFile(ctx context.Context, picture *entity.Picture, img image.Image, data []byte, format string, userId int64, action string, remark string) (*v1.PictureVO, error) {
+	objectKey := service.Bucket().NewObjectKey(picture.SpaceId, "edit"+formatExt(format))
+	if err := service.Bucket().PutObject(ctx, objectKey, data, formatContentType(format, data)); err != nil {
+		return nil, err
+	}
+	fileUrl := service.Bucket().GetFileUrl(objectKey)
+	uploadedKeys := []string{objectKey}
+	thumbnailUrl := fileUrl
+	if thumbUrl, keys := s.generateThumbnails(ctx, img, objectKey); thumbUrl != "" {
+		thumbnailUrl = thumbUrl
+		uploadedKeys = append(uploadedKeys, keys...)
+	}
+
+	width, height, scale := s.imageSize(img)
+	picColor, colorErr := s.extractDominantColorOptimized(img, ctx)
+	if colorErr != nil {
+		g.Log().Warningf(ctx, "提取图片主色调失败，沿用原主色调: %v", colorErr)
+		picColor = picture.PicColor
+	}
+	fileSize := int64(len(data))
+	sizeDelta := fileSize - picture.PicSize
+
+	pic := dao.Picture.Columns()
+	err := g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		// 以原地址为条件更新，防止并发保存互相覆盖
+		result, upErr := dao.Picture.Ctx(ctx).TX(tx).
+			Where(pic.Id, picture.Id).
+			Where(pic.Url, picture.Url).
+			Data(do.Picture{
+				Url:          fileUrl,
+				ThumbnailUrl: thumbnailUrl,
+				PicSize:      fileSize,
+				PicWidth:     width,
+				PicHeight:    height,
+				PicScale:     scale,
+				PicFormat:    format,
+				PicColor:     picColor,
+				ContentHash:  s.contentHash(data),
+				PicHash:      formatPicHash(perceptualHash(img)),
+				ReviewStatus: consts.DefRwStatus,
+				EditTime:     gtime.Now(),
+				UpdateTime:   gtime.Now(),
+			}).Update()
+		if upErr != nil {
+			g.Log().Errorf(ctx, "更新图片文件失败 id=%d: %v", picture.Id, upErr)
+			return gerror.New("保存编辑结果失败")
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return gerror.New("图片已被修改，请刷新后重试")
+		}
+
+		// 按文件大小变化调整空间用量
+		if picture.SpaceId > 0 && sizeDelta > 0 {
+			if quotaErr := service.Space().ReserveQuota(ctx, tx, picture.SpaceId, sizeDelta, 0); quotaErr != nil {
+				return quotaErr
+			}
+		}
+		if picture.SpaceId > 0 && sizeDelta < 0 {
+			if quotaErr := service.Space().ReleaseQuota(ctx, tx, picture.SpaceId, -sizeDelta, 0); quotaErr != nil {
+				return quotaErr
+			}
+		}
+		if logErr := s.recordReviewReset(ctx, tx, picture, userId); logErr != nil {
+			return logErr
+		}
+		return s.recordVersion(ctx, tx, picture, action, userId, remark)
+	})
+	if err != nil {
+		s.deleteObjects(ctx, uploadedKeys...)
+		return nil, err
+	}
+	// 新的文件内容重新自动审核
+	s.moderateAsync(ctx, picture.Id)
+	s.pruneVersions(ctx, picture.Id)
+
+	var updated *entity.Picture
+	if err = dao.Picture.Ctx(ctx).Where(pic.Id, picture.Id).Scan(&updated); err != nil || updated == nil {
+		return nil, gerror.New("查询图片失败")
+	}
+	return s.entityToVO(ctx, updated), nil
+}
+
+// applyEditSteps 按顺序应用旋转、翻转、裁剪操作，缩放等仅影响预览的操作忽略
+func applyEditSteps(img image.Image, steps []wsmodel.PictureEditStep) (image.Image, error) {
+	for _, step := range steps {
+		switch step.Action {
+		case wsmodel.ActionRotateLeft:
+			img = imaging.Rotate90(img)
+		case wsmodel.ActionRotateRight:
+			img = imaging.Rotate270(img)
+		case wsmodel.ActionFlipH:
+			img = imaging.FlipH(img)
+		case wsmodel.ActionFlipV:
+			img = imaging.FlipV(img)
+		case wsmodel.ActionCrop:
+			if step.Crop == nil {
+				return nil, gerror.New("裁剪操作缺少裁剪区域")
+			}
+			area := image.Rect(step.Crop.X, step.Crop.Y, step.Crop.X+step.Crop.Width, step.Crop.Y+step.Crop.Height)
+			area = area.Intersect(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+			if area.Empty() {
+				return nil, gerror.New("裁剪区域超出图片范围")
+			}
+			img = imaging.Crop(img, area.Add(img.Bounds().Min))
+		}
+	}
+	return img, nil
+}
+
+// formatExt 图片格式对应的文件扩展名
+func formatExt(format string) string {
+	if format == "jpeg" {
+		return ".jpg"
+	}
+	return "." + format
+}
@@ -0,0 +1,29 @@
+package picture
+
+import (
+	wsmodel "cloud/internal/model/websocket"
+	"image"
+	"testing"
+)
+
+func Test_applyEditSteps(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	steps := []wsmodel.PictureEditStep{
+		{Action: wsmodel.ActionZoomIn},
+		{Action: wsmodel.ActionRotateRight},
+		{Action: wsmodel.ActionFlipH},
+		// 旋转后为20x40，裁剪区域超出部分被截断
+		{Action: wsmodel.ActionCrop, Crop: &wsmodel.CropArea{X: 5, Y: 10, Width: 100, Height: 20}},
+	}
+	out, err := applyEditSteps(img, steps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := out.Bounds(); b.Dx() != 15 || b.Dy() != 20 {
+		t.Errorf("尺寸错误: %dx%d", b.Dx(), b.Dy())
+	}
+
+	if _, err = applyEditSteps(img, []wsmodel.PictureEditStep{{Action: wsmodel.ActionCrop, Crop: &wsmodel.CropArea{X: 50, Y: 0, Width: 10, Height: 10}}}); err == nil {
+		t.Error("裁剪区域在图片外应返回错误")
+	}
+}
@@ -0,0 +1,175 @@
+package picture
+
+import (
+	"bytes"
+	v1 "cloud/api/user/v1"
+	"cloud/internal/consts"
+	"cloud/internal/dao"
+	"cloud/internal/model/entity"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// rawExifHeader HEIF等容器中Exif块的起始标记，其后紧跟TIFF头
+var rawExifHeader = []byte("Exif\x00\x00")
+
+// parseExif 从图片原始内容解析EXIF（JPEG、TIFF直接解析，HEIF等容器查找Exif块解析），没有EXIF时返回nil
+func parseExif(data []byte) *entity.PictureExif {
+	x, err := exif.Decode(bytes.NewReader(data))
+	if err != nil {
+		offset := bytes.Index(data, rawExifHeader)
+		if offset < 0 {
+			return nil
+		}
+		if x, err = exif.Decode(bytes.NewReader(data[offset:])); err != nil {
+			return nil
+		}
+	}
+
+	info := &entity.PictureExif{
+		Make:        exifString(x, exif.Make),
+		Model:       exifString(x, exif.Model),
+		LensModel:   exifString(x, exif.LensModel),
+		FNumber:     exifFloat(x, exif.FNumber),
+		FocalLength: exifFloat(x, exif.FocalLength),
+		Iso:         exifInt(x, exif.ISOSpeedRatings),
+		Orientation: exifInt(x, exif.Orientation),
+	}
+	if tag, err := x.Get(exif.ExposureTime); err == nil {
+		if num, den, err := tag.Rat2(0); err == nil && den != 0 {
+			if num < den && num != 0 && den%num == 0 {
+				info.ExposureTime = fmt.Sprintf("1/%d", den/num)
+			} else {
+				info.ExposureTime = fmt.Sprintf("%g", float64(num)/float64(den))
+			}
+		}
+	}
+	if t, err := x.DateTime(); err == nil {
+		info.CaptureTime = gtime.New(t)
+	}
+	if lat, long, err := x.LatLong(); err == nil {
+		info.Latitude, info.Longitude = lat, long
+	}
+	return info
+}
+
+// exifString 读取字符串类型的EXIF字段
+func exifString(x *exif.Exif, name exif.FieldName) string {
+	tag, err := x.Get(name)
+	if err != nil || tag.Format() != tiff.StringVal {
+		return ""
+	}
+	value, _ := tag.StringVal()
+	return strings.TrimSpace(strings.TrimRight(value, "\x00"))
+}
+
+// exifFloat 读取有理数类型的EXIF字段
+func exifFloat(x *exif.Exif, name exif.FieldName) float64 {
+	tag, err := x.Get(name)
+	if err != nil {
+		return 0
+	}
+	if num, den, err := tag.Rat2(0); err == nil && den != 0 {
+		return float64(num) / float64(den)
+	}
+	return 0
+}
+
+// exifInt 读取整数类型的EXIF字段
+func exifInt(x *exif.Exif, name exif.FieldName) int {
+	tag, err := x.Get(name)
+	if err != nil {
+		return 0
+	}
+	value, err := tag.Int(0)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// saveExif 在事务中保存图片的EXIF信息
+func (s *sPicture) saveExif(ctx context.Context, tx gdb.TX, pictureId int64, info *entity.PictureExif) error {
+	if info == nil {
+		return nil
+	}
+	info.PictureId = pictureId
+	_, err := dao.PictureExif.Ctx(ctx).TX(tx).Data(info).OmitEmptyData().Insert()
+	if err != nil {
+		g.Log().Errorf(ctx, "保存图片EXIF失败 pictureId=%d: %v", pictureId, err)
+	}
+	return err
+}
+
+// exifMapByIds 按图片ID批量查询EXIF信息
+func (s *sPicture) exifMapByIds(ctx context.Context, pictureIds []int64) map[int64]*v1.PictureExifVO {
+	exifMap := make(map[int64]*v1.PictureExifVO)
+	if len(pictureIds) == 0 {
+		return exifMap
+	}
+	var list []entity.PictureExif
+	err := dao.PictureExif.Ctx(ctx).WhereIn(dao.PictureExif.Columns().PictureId, pictureIds).Scan(&list)
+	if err != nil {
+		g.Log().Errorf(ctx, "查询图片EXIF失败: %v", err)
+		return exifMap
+	}
+	for i := range list {
+		exifMap[list[i].PictureId] = s.exifToVO(&list[i])
+	}
+	return exifMap
+}
+
+// exifToVO 将EXIF实体转换为视图对象
+func (s *sPicture) exifToVO(info *entity.PictureExif) *v1.PictureExifVO {
+	vo := &v1.PictureExifVO{
+		Make:         info.Make,
+		Model:        info.Model,
+		LensModel:    info.LensModel,
+		ExposureTime: info.ExposureTime,
+		FNumber:      info.FNumber,
+		Iso:          info.Iso,
+		FocalLength:  info.FocalLength,
+		Orientation:  info.Orientation,
+	}
+	if info.CaptureTime != nil {
+		vo.CaptureTime = info.CaptureTime.Format(consts.Y_m_d_His)
+	}
+	if info.Latitude != 0 || info.Longitude != 0 {
+		vo.Latitude, vo.Longitude = &info.Latitude, &info.Longitude
+	}
+	return vo
+}
+
+// whereExif 按EXIF信息过滤图片（拍摄时间范围、相机厂商、相机型号）
+func (s *sPicture) whereExif(ctx context.Context, db *gdb.Model, req *v1.PictureQueryReq) *gdb.Model {
+	if req.CaptureTimeStart == "" && req.CaptureTimeEnd == "" && req.CameraMake == "" && req.CameraModel == "" {
+		return db
+	}
+	col := dao.PictureExif.Columns()
+	sub := dao.PictureExif.Ctx(ctx).Fields(col.PictureId)
+	if req.CaptureTimeStart != "" {
+		sub = sub.WhereGTE(col.CaptureTime, req.CaptureTimeStart)
+	}
+	if req.CaptureTimeEnd != "" {
+		end := req.CaptureTimeEnd
+		// 只传日期时包含当天
+		if len(end) == len("2006-01-02") {
+			end += " 23:59:59"
+		}
+		sub = sub.WhereLTE(col.CaptureTime, end)
+	}
+	if req.CameraMake != "" {
+		sub = sub.WhereLike(col.Make, "%"+req.CameraMake+"%")
+	}
+	if req.CameraModel != "" {
+		sub = sub.WhereLike(col.Model, "%"+req.CameraModel+"%")
+	}
+	return db.Where(dao.Picture.Columns().Id+" IN (?)", sub)
+}
@@ -0,0 +1,64 @@
+package picture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"net/http"
+
+	"github.com/gen2brain/heic"
+)
+
+func init() {
+	// heic 包只注册了 heic 品牌，手机拍摄的 HEIF 图片还常见以下品牌
+	for _, brand := range []string{"heix", "hevc", "hevx", "heim", "heis"} {
+		image.RegisterFormat("heic", "????ftyp"+brand, heic.Decode, heic.DecodeConfig)
+	}
+}
+
+// sniffFormat 根据文件头识别图片格式，无法识别时返回空字符串
+func sniffFormat(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return "jpeg"
+	case bytes.HasPrefix(data, pngSignature):
+		return "png"
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return "gif"
+	case bytes.HasPrefix(data, []byte("BM")):
+		return "bmp"
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return "webp"
+	case bytes.HasPrefix(data, []byte("II*\x00")), bytes.HasPrefix(data, []byte("MM\x00*")):
+		return "tiff"
+	case len(data) >= 12 && string(data[4:8]) == "ftyp":
+		// ISO BMFF 容器，按主品牌区分 AVIF 与 HEIF
+		switch string(data[8:12]) {
+		case "avif", "avis":
+			return "avif"
+		case "heic", "heix", "hevc", "hevx", "heim", "heis", "mif1", "msf1":
+			return "heic"
+		}
+	}
+	return ""
+}
+
+// formatContentType 根据图片格式获取Content-Type，未知格式时按内容推断
+func formatContentType(format string, data []byte) string {
+	if format != "" {
+		return "image/" + format
+	}
+	return http.DetectContentType(data)
+}
+
+// decodableWebP 解码库只支持带透明通道的扩展格式（VP8X）WebP，去除元数据与色彩配置块后再解码；
+// 去除后没有其他扩展特性时转为简单格式
+func decodableWebP(data []byte) []byte {
+	data = rebuildWebP(data, map[string]bool{"EXIF": true, "XMP ": true, "ICCP": true})
+	if len(data) >= 30 && string(data[12:16]) == "VP8X" && data[20] == 0 {
+		out := append(append([]byte{}, data[:12]...), data[30:]...)
+		binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+		return out
+	}
+	return data
+}
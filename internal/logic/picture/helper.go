@@ -1,172 +1,174 @@
 package picture
 
 import (
+	"bytes"
 	v1 "cloud/api/user/v1"
 	"cloud/internal/consts"
+	"cloud/internal/dao"
 	"cloud/internal/model/entity"
+	"cloud/internal/service"
 	"context"
 	"fmt"
 	"image"
 	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
+	"io"
 	"math"
-	"net/http"
-	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/EdlinOrg/prominentcolor"
+	_ "github.com/gen2brain/avif"
+	_ "github.com/gen2brain/heic"
 	"github.com/gogf/gf/v2/encoding/gjson"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/os/gfile"
 	"github.com/lucasb-eyer/go-colorful"
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
 )
 
-// parseImageInfo 解析图片信息
-func (s *sPicture) parseImageInfo(ctx context.Context, file *ghttp.UploadFile) (width, height int, format string, scale float64, err error) {
-	// 打开文件
+const defaultPictureMaxSize = "50MB" // 默认图片文件大小上限
+
+// pictureMaxSize 图片文件大小上限（支持 50MB 等写法），直传及读取存储中的原图、转换结果、AI生成结果时均以此为限
+func pictureMaxSize(ctx context.Context) int64 {
+	size := gfile.StrToSize(g.Cfg().MustGet(ctx, "picture.maxFileSize", defaultPictureMaxSize).String())
+	if size <= 0 {
+		size = gfile.StrToSize(defaultPictureMaxSize)
+	}
+	return size
+}
+
+// readUploadFile 读取上传文件的全部内容（哈希、解码、EXIF解析共用）
+func (s *sPicture) readUploadFile(ctx context.Context, file *ghttp.UploadFile) ([]byte, error) {
 	f, err := file.Open()
 	if err != nil {
 		g.Log().Errorf(ctx, "打开文件失败: %v", err)
-		return 0, 0, "", 0, err
+		return nil, err
 	}
 	defer f.Close()
+	return io.ReadAll(f)
+}
 
-	// 解析图片配置信息
-	config, format, err := image.DecodeConfig(f)
+// decodeImage 解码图片内容（尺寸、主色调、缩略图共用一次解码结果），格式按文件内容识别
+// 解码失败时仍返回按文件头识别出的格式
+func (s *sPicture) decodeImage(ctx context.Context, data []byte) (img image.Image, format string, err error) {
+	img, format, err = image.Decode(bytes.NewReader(data))
+	if err != nil && sniffFormat(data) == "webp" {
+		// 解码库不支持带元数据的扩展格式WebP，去除元数据块后重试
+		img, format, err = image.Decode(bytes.NewReader(decodableWebP(data)))
+	}
 	if err != nil {
-		g.Log().Errorf(ctx, "解析图片配置失败: %v", err)
-		// 如果解析失败，尝试从文件扩展名获取格式
-		format = s.getFormatFromFilename(file.Filename)
-		return 0, 0, format, 0, err
+		g.Log().Errorf(ctx, "解码图片失败: %v", err)
+		return nil, sniffFormat(data), err
 	}
+	return img, format, nil
+}
 
-	width = config.Width
-	height = config.Height
-
+// imageSize 获取图片宽高与宽高比例
+func (s *sPicture) imageSize(img image.Image) (width, height int, scale float64) {
+	width = img.Bounds().Dx()
+	height = img.Bounds().Dy()
 	// 计算宽高比例
 	if height > 0 {
 		scale = float64(width) / float64(height)
 	}
-
-	g.Log().Infof(ctx, "解析图片信息成功: %dx%d, 格式: %s, 比例: %.2f", width, height, format, scale)
-
-	return width, height, format, scale, nil
+	return width, height, scale
 }
 
-// getFormatFromFilename 从文件名获取格式
-func (s *sPicture) getFormatFromFilename(filename string) string {
-	ext := strings.ToLower(filepath.Ext(filename))
-	switch ext {
-	case ".jpg", ".jpeg":
-		return "jpeg"
-	case ".png":
-		return "png"
-	case ".gif":
-		return "gif"
-	case ".bmp":
-		return "bmp"
-	case ".webp":
-		return "webp"
-	default:
-		return "jpeg" // 默认格式
+// deleteObjects 删除对象存储中的文件，失败的对象由bucket服务记录并定时重试
+func (s *sPicture) deleteObjects(ctx context.Context, keys ...string) {
+	if failed := service.Bucket().DeleteObjects(ctx, keys...); failed > 0 {
+		g.Log().Warningf(ctx, "有 %d 个对象删除失败，已加入重试队列", failed)
 	}
 }
 
-// parseImageInfoFromURL 从URL解析图片信息
-func (s *sPicture) parseImageInfoFromURL(ctx context.Context, url string) (width, height int, format string, scale float64, fileSize int64, err error) {
-	// 先从URL路径获取格式作为备用
-	format = s.getFormatFromFilename(url)
-
-	g.Log().Infof(ctx, "开始解析URL图片信息: %s", url)
-
-	// 创建HTTP客户端，设置超时时间
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	// 发送HTTP请求获取图片
-	resp, err := client.Get(url)
-	if err != nil {
-		g.Log().Errorf(ctx, "获取URL图片失败: %v", err)
-		return 0, 0, format, 0, 0, err
-	}
-	defer resp.Body.Close()
-
-	// 检查HTTP状态码
-	if resp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("HTTP状态码错误: %d", resp.StatusCode)
-		g.Log().Errorf(ctx, "HTTP请求失败: %v", err)
-		return 0, 0, format, 0, 0, err
-	}
-
-	// 获取文件大小
-	fileSize = resp.ContentLength
-	if fileSize < 0 {
-		// 如果Content-Length头不存在或为负数，尝试从响应体计算大小
-		// 注意：这会消耗响应体，需要重新请求来获取图片数据
-		g.Log().Warningf(ctx, "无法从Content-Length获取文件大小，将重新请求")
-		fileSize = 0 // 暂时设为0，后续可以通过其他方式获取
-	}
-
-	// 解析图片配置信息
-	config, detectedFormat, err := image.DecodeConfig(resp.Body)
-	if err != nil {
-		g.Log().Errorf(ctx, "解析图片配置失败: %v", err)
-		// 如果解析失败，返回从文件名获取的格式
-		return 0, 0, format, 0, fileSize, err
+// pictureObjectKeys 获取图片关联的全部对象key（原图、缩略图、各尺寸缩略图及实时转换结果），已去重
+func (s *sPicture) pictureObjectKeys(ctx context.Context, picture *entity.Picture) []string {
+	originalKey := service.Bucket().GetFileKey(picture.Url)
+	candidates := []string{originalKey, service.Bucket().GetFileKey(picture.ThumbnailUrl)}
+	if originalKey != "" {
+		for _, size := range s.thumbnailSizes(ctx) {
+			candidates = append(candidates, thumbnailKey(originalKey, size))
+		}
+		candidates = append(candidates, s.renditionKeys(ctx, originalKey)...)
 	}
 
-	width = config.Width
-	height = config.Height
-	format = detectedFormat
-
-	// 计算宽高比例
-	if height > 0 {
-		scale = float64(width) / float64(height)
+	keys := make([]string, 0, len(candidates))
+	seen := make(map[string]bool)
+	for _, key := range candidates {
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
 	}
-
-	g.Log().Infof(ctx, "成功解析URL图片信息: %dx%d, 格式: %s, 比例: %.2f, 大小: %d bytes", width, height, format, scale, fileSize)
-	return width, height, format, scale, fileSize, nil
+	return keys
 }
 
 // entityToVO 将entity转换为VO
 func (s *sPicture) entityToVO(ctx context.Context, picture *entity.Picture) *v1.PictureVO {
-	// 解析标签JSON
+	tags := parseTags(picture.Tags)
+
+	vo := &v1.PictureVO{
+		Id:              picture.Id,
+		Url:             picture.Url,
+		Name:            picture.Name,
+		Introduction:    picture.Introduction,
+		Category:        picture.Category,
+		Tags:            tags,
+		PicSize:         picture.PicSize,
+		PicWidth:        picture.PicWidth,
+		PicHeight:       picture.PicHeight,
+		PicScale:        picture.PicScale,
+		PicFormat:       picture.PicFormat,
+		UserId:          picture.UserId,
+		SpaceId:         picture.SpaceId,
+		CreateTime:      picture.CreateTime.Format(consts.Y_m_d_His),
+		EditTime:        picture.EditTime.Format(consts.Y_m_d_His),
+		UpdateTime:      picture.UpdateTime.Format(consts.Y_m_d_His),
+		ThumbnailUrl:    picture.ThumbnailUrl,
+		PicColor:        picture.PicColor,
+		SourcePictureId: picture.SourcePictureId,
+	}
+	s.signPictureUrls(ctx, picture.SpaceId, &vo.Url, &vo.ThumbnailUrl)
+	return vo
+}
+
+// parseTags 解析标签JSON，解析失败时按逗号分割
+func parseTags(value string) []string {
 	var tags []string
-	if picture.Tags != "" {
-		err := gjson.DecodeTo(picture.Tags, &tags)
-		if err != nil {
-			// 如果JSON解析失败，尝试按逗号分割
-			tags = strings.Split(picture.Tags, ",")
-			for i, tag := range tags {
-				tags[i] = strings.TrimSpace(tag)
-			}
+	if value == "" {
+		return tags
+	}
+	if err := gjson.DecodeTo(value, &tags); err != nil {
+		tags = strings.Split(value, ",")
+		for i, tag := range tags {
+			tags[i] = strings.TrimSpace(tag)
 		}
 	}
+	return tags
+}
 
-	return &v1.PictureVO{
-		Id:           picture.Id,
-		Url:          picture.Url,
-		Name:         picture.Name,
-		Introduction: picture.Introduction,
-		Category:     picture.Category,
-		Tags:         tags,
-		PicSize:      picture.PicSize,
-		PicWidth:     picture.PicWidth,
-		PicHeight:    picture.PicHeight,
-		PicScale:     picture.PicScale,
-		PicFormat:    picture.PicFormat,
-		UserId:       picture.UserId,
-		SpaceId:      picture.SpaceId,
-		CreateTime:   picture.CreateTime.Format(consts.Y_m_d_His),
-		EditTime:     picture.EditTime.Format(consts.Y_m_d_His),
-		UpdateTime:   picture.UpdateTime.Format(consts.Y_m_d_His),
-		ThumbnailUrl: picture.ThumbnailUrl,
-		PicColor:     picture.PicColor,
+// signPictureUrls 私有/团队空间图片的访问地址替换为限时签名地址，公共图片保持原地址
+func (s *sPicture) signPictureUrls(ctx context.Context, spaceId int64, urls ...*string) {
+	if spaceId <= 0 {
+		return
+	}
+	for _, u := range urls {
+		*u = service.Bucket().SignedUrl(ctx, *u)
+	}
+}
+
+// signPictures 批量替换图片列表的访问地址
+func (s *sPicture) signPictures(ctx context.Context, pictures []v1.Picture) {
+	for i := range pictures {
+		s.signPictureUrls(ctx, pictures[i].SpaceId, &pictures[i].Url, &pictures[i].ThumbnailUrl)
 	}
 }
 
@@ -198,85 +200,32 @@ func (s *sPicture) entityToPicture(ctx context.Context, picture *entity.Picture)
 	}
 
 	return &v1.Picture{
-		Id:            picture.Id,
-		Url:           picture.Url,
-		Name:          picture.Name,
-		Introduction:  picture.Introduction,
-		Category:      picture.Category,
-		Tags:          tagsJson, // 前端期望JSON字符串格式
-		PicSize:       picture.PicSize,
-		PicWidth:      picture.PicWidth,
-		PicHeight:     picture.PicHeight,
-		PicScale:      picture.PicScale,
-		PicFormat:     picture.PicFormat,
-		UserId:        picture.UserId,
-		SpaceId:       picture.SpaceId,
-		CreateTime:    createTime,
-		EditTime:      editTime,
-		UpdateTime:    updateTime,
-		ThumbnailUrl:  picture.ThumbnailUrl,
-		PicColor:      picture.PicColor,
-		IsDelete:      picture.IsDelete,
-		ReviewStatus:  picture.ReviewStatus,
-		ReviewMessage: picture.ReviewMessage,
-		ReviewerId:    picture.ReviewerId,
-		ReviewTime:    reviewTime,
-	}
-}
-
-// extractDominantColor 提取图片主色调
-func (s *sPicture) extractDominantColor(ctx context.Context, file *ghttp.UploadFile) (string, error) {
-	// 打开文件
-	f, err := file.Open()
-	if err != nil {
-		g.Log().Errorf(ctx, "打开文件失败: %v", err)
-		return "", err
-	}
-	defer f.Close()
-
-	// 解码图片
-	img, _, err := image.Decode(f)
-	if err != nil {
-		g.Log().Errorf(ctx, "解码图片失败: %v", err)
-		return "", err
-	}
-
-	// 使用优化的主色调提取算法
-	return s.extractDominantColorOptimized(img, ctx)
-}
-
-// extractDominantColorFromURL 从URL提取图片主色调
-func (s *sPicture) extractDominantColorFromURL(ctx context.Context, url string) (string, error) {
-
-	// 创建HTTP客户端，设置超时时间
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	// 发送HTTP请求获取图片
-	resp, err := client.Get(url)
-	if err != nil {
-		g.Log().Errorf(ctx, "获取URL图片失败: %v", err)
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	// 检查HTTP状态码
-	if resp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("HTTP状态码错误: %d", resp.StatusCode)
-		g.Log().Errorf(ctx, "HTTP请求失败: %v", err)
-		return "", err
+		Id:             picture.Id,
+		Url:            picture.Url,
+		Name:           picture.Name,
+		Introduction:   picture.Introduction,
+		Category:       picture.Category,
+		Tags:           tagsJson, // 前端期望JSON字符串格式
+		PicSize:        picture.PicSize,
+		PicWidth:       picture.PicWidth,
+		PicHeight:      picture.PicHeight,
+		PicScale:       picture.PicScale,
+		PicFormat:      picture.PicFormat,
+		UserId:         picture.UserId,
+		SpaceId:        picture.SpaceId,
+		CreateTime:     createTime,
+		EditTime:       editTime,
+		UpdateTime:     updateTime,
+		ThumbnailUrl:   picture.ThumbnailUrl,
+		PicColor:       picture.PicColor,
+		IsDelete:       picture.IsDelete,
+		ReviewStatus:   picture.ReviewStatus,
+		ReviewMessage:  picture.ReviewMessage,
+		ReviewerId:     picture.ReviewerId,
+		ReviewTime:     reviewTime,
+		RiskScore:      picture.RiskScore,
+		ModerationRule: picture.ModerationRule,
 	}
-
-	// 解码图片
-	img, _, err := image.Decode(resp.Body)
-	if err != nil {
-		g.Log().Errorf(ctx, "解码URL图片失败: %v", err)
-		return "", err
-	}
-
-	// 使用优化的主色调提取算法
-	return s.extractDominantColorOptimized(img, ctx)
 }
 
 // extractDominantColorOptimized 主色调提取核心算法（使用prominentcolor库）
@@ -476,3 +425,26 @@ func (s *sPicture) calculateColorSimilarity(ctx context.Context, pictures []enti
 
 	return items
 }
+
+// runBackground 在请求结束后继续执行图片的后台处理（上传后的智能标注、内容审核），并发数受限，图片已删除时跳过
+func (s *sPicture) runBackground(ctx context.Context, name string, pictureId int64, fn func(ctx context.Context, picture *entity.Picture) error) {
+	// 保留上下文中的链路信息，但不随请求结束而取消
+	ctx = gctx.NeverDone(ctx)
+	go func() {
+		s.bgSlots <- struct{}{}
+		defer func() {
+			<-s.bgSlots
+			if r := recover(); r != nil {
+				g.Log().Errorf(ctx, "%s异常 pictureId=%d: %v", name, pictureId, r)
+			}
+		}()
+		var picture *entity.Picture
+		pic := dao.Picture.Columns()
+		if err := dao.Picture.Ctx(ctx).Where(pic.Id, pictureId).Where(pic.IsDelete, 0).Scan(&picture); err != nil || picture == nil {
+			return
+		}
+		if err := fn(ctx, picture); err != nil {
+			g.Log().Warningf(ctx, "%s失败 pictureId=%d: %v", name, pictureId, err)
+		}
+	}()
+}
@@ -0,0 +1,268 @@
+package picture
+
+import (
+	"bytes"
+	"cloud/internal/consts"
+	"cloud/internal/dao"
+	"cloud/internal/model/entity"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"path"
+	"strings"
+
+	"github.com/disintegration/imaging"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// normalizedJPEGQuality 摆正方向后重新编码JPEG的质量
+const normalizedJPEGQuality = 92
+
+var (
+	// pngSignature PNG文件头
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+	// pngPrivateChunks PNG中可能携带拍摄信息、地理位置等隐私内容的块
+	pngPrivateChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+	// metadataFormats 可能携带EXIF、XMP等元数据但无法原地去除的格式
+	metadataFormats = map[string]bool{"tiff": true, "heic": true, "avif": true}
+	// webpPrivateChunks WebP中携带拍摄信息、地理位置等隐私内容的块
+	webpPrivateChunks = map[string]bool{"EXIF": true, "XMP ": true}
+)
+
+// normalizeImage 入库前处理：按EXIF方向摆正图片，并按空间设置去除GPS等隐私元数据
+// 返回摆正后的图片（用于尺寸、主色调、缩略图）、实际写入存储的内容及其格式；无法去除元数据时拒绝入库
+func (s *sPicture) normalizeImage(ctx context.Context, spaceId int64, data []byte, img image.Image, format string, exifInfo *entity.PictureExif) (image.Image, []byte, string, error) {
+	rotated := false
+	if img != nil && exifInfo != nil && exifInfo.Orientation > 1 && exifInfo.Orientation <= 8 {
+		img = orientImage(img, exifInfo.Orientation)
+		rotated = true
+	}
+	if !s.stripMetadataEnabled(ctx, spaceId) {
+		// 保留原始内容，浏览器按EXIF方向显示原图
+		return img, data, format, nil
+	}
+
+	stripped, strippedFormat, err := sanitizeImage(data, img, format, rotated)
+	if err != nil {
+		g.Log().Warningf(ctx, "去除图片元数据失败 format=%s: %v", format, err)
+		return nil, nil, "", gerror.New("无法去除图片中的隐私信息，请转换为JPEG或PNG后上传")
+	}
+	if exifInfo != nil {
+		exifInfo.Latitude, exifInfo.Longitude = 0, 0
+	}
+	return img, stripped, strippedFormat, nil
+}
+
+// sanitizeImage 去除图片中的隐私元数据，返回去除后的内容与格式
+// JPEG、PNG、WebP原地去除元数据段；HEIC、TIFF、AVIF等无法原地处理的格式，以及摆正方向后的图片（方向信息随元数据丢失），
+// 按像素重新编码为JPEG（带透明通道时为PNG）；GIF、BMP等不携带EXIF的格式原样保留
+func sanitizeImage(data []byte, img image.Image, format string, rotated bool) ([]byte, string, error) {
+	inPlace := format == "jpeg" || format == "png"
+	switch {
+	case format == "webp" && !rotated:
+		return stripMetadata(data, format), format, nil
+	case inPlace && !rotated:
+		return stripMetadata(data, format), format, nil
+	case !rotated && !metadataFormats[format]:
+		return data, format, nil
+	case img == nil:
+		return nil, "", fmt.Errorf("无法解码的%s图片", format)
+	}
+	if !inPlace {
+		format = "jpeg"
+		if opaque, ok := img.(interface{ Opaque() bool }); !ok || !opaque.Opaque() {
+			format = "png"
+		}
+	}
+	encoded, err := encodeImage(img, format)
+	if err != nil {
+		return nil, "", err
+	}
+	return encoded, format, nil
+}
+
+// formatFileName 图片被重新编码为其他格式后，按新格式修改文件扩展名
+func formatFileName(name string, format string) string {
+	ext := "." + format
+	if format == "jpeg" {
+		ext = ".jpg"
+	}
+	return strings.TrimSuffix(name, path.Ext(name)) + ext
+}
+
+// stripMetadataEnabled 入库时是否去除隐私元数据：空间图片按空间设置，公共图库按系统配置
+func (s *sPicture) stripMetadataEnabled(ctx context.Context, spaceId int64) bool {
+	if spaceId > 0 {
+		value, err := dao.Space.Ctx(ctx).Fields(dao.Space.Columns().StripMetadata).
+			Where(dao.Space.Columns().Id, spaceId).Value()
+		if err != nil {
+			g.Log().Errorf(ctx, "查询空间元数据设置失败 spaceId=%d: %v", spaceId, err)
+		} else if !value.IsNil() {
+			return value.Int() == 1
+		}
+	}
+	return g.Cfg().MustGet(ctx, consts.PictureStripMetadata, true).Bool()
+}
+
+// orientImage 按EXIF方向（1-8）将图片摆正
+func orientImage(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return imaging.FlipH(img)
+	case 3:
+		return imaging.Rotate180(img)
+	case 4:
+		return imaging.FlipV(img)
+	case 5:
+		return imaging.Transpose(img)
+	case 6:
+		return imaging.Rotate270(img)
+	case 7:
+		return imaging.Transverse(img)
+	case 8:
+		return imaging.Rotate90(img)
+	}
+	return img
+}
+
+// encodeImage 按原格式重新编码图片（不携带任何元数据）
+func encodeImage(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case "jpeg":
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: normalizedJPEGQuality}); err != nil {
+			return nil, err
+		}
+	case "png":
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("不支持重新编码的图片格式: %s", format)
+	}
+	return buf.Bytes(), nil
+}
+
+// stripMetadata 去除图片中的EXIF、XMP、IPTC及文本注释等元数据，不支持的格式或解析失败时原样返回
+func stripMetadata(data []byte, format string) []byte {
+	switch format {
+	case "jpeg":
+		return stripJPEGMetadata(data)
+	case "png":
+		return stripPNGMetadata(data)
+	case "webp":
+		return rebuildWebP(data, webpPrivateChunks)
+	}
+	return data
+}
+
+// stripJPEGMetadata 去除JPEG中的APP1-APP15段（保留ICC色彩配置APP2与Adobe APP14）和注释段
+func stripJPEGMetadata(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return data
+		}
+		marker := data[i+1]
+		if marker == 0xFF {
+			// 填充字节
+			i++
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 {
+			// 扫描数据开始，之后均为图像数据，原样保留
+			return append(out, data[i:]...)
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end <= i+3 || end > len(data) {
+			return data
+		}
+		private := marker == 0xFE || (marker >= 0xE1 && marker <= 0xEF && marker != 0xE2 && marker != 0xEE)
+		if !private {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return data
+}
+
+// stripPNGMetadata 去除PNG中的eXIf、文本及时间块
+func stripPNGMetadata(data []byte) []byte {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	for i := len(pngSignature); i < len(data); {
+		if i+12 > len(data) {
+			return data
+		}
+		// 块结构：长度(4) + 类型(4) + 数据 + CRC(4)
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:]))
+		if end < i+12 || end > len(data) {
+			return data
+		}
+		if !pngPrivateChunks[string(data[i+4:i+8])] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return out
+}
+
+// WebP扩展格式（VP8X）标志位
+const (
+	webpICCBit  = 1 << 5
+	webpEXIFBit = 1 << 3
+	webpXMPBit  = 1 << 2
+)
+
+// rebuildWebP 去除WebP中指定类型的块，并同步清除VP8X中对应的标志位；不是WebP或解析失败时原样返回
+func rebuildWebP(data []byte, drop map[string]bool) []byte {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+	vp8x := -1
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return data
+		}
+		// 块结构：类型(4) + 长度(4) + 数据，数据长度为奇数时补一个填充字节
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size&1
+		if end > len(data) && i+8+size == len(data) {
+			// 末尾的块缺少填充字节
+			end = len(data)
+		}
+		if size < 0 || end > len(data) {
+			return data
+		}
+		chunk := string(data[i : i+4])
+		if chunk == "VP8X" && size == 10 {
+			vp8x = len(out)
+		}
+		if !drop[chunk] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	if vp8x >= 0 {
+		for chunk, bit := range map[string]byte{"EXIF": webpEXIFBit, "XMP ": webpXMPBit, "ICCP": webpICCBit} {
+			if drop[chunk] {
+				out[vp8x+8] &^= bit
+			}
+		}
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out
+}
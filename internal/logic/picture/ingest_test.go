@@ -0,0 +1,181 @@
+package picture
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+func Test_orientImage(t *testing.T) {
+	// 左上角标记点，用于验证旋转方向
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	img.Set(0, 0, color.White)
+
+	for orientation, want := range map[int][2]int{1: {40, 20}, 3: {40, 20}, 6: {20, 40}, 8: {20, 40}} {
+		b := orientImage(img, orientation).Bounds()
+		if b.Dx() != want[0] || b.Dy() != want[1] {
+			t.Errorf("orientation=%d 尺寸错误: %dx%d", orientation, b.Dx(), b.Dy())
+		}
+	}
+	// 方向6需顺时针旋转90度，原左上角移动到右上角
+	if r, _, _, _ := orientImage(img, 6).At(19, 0).RGBA(); r == 0 {
+		t.Error("orientation=6 旋转方向错误")
+	}
+}
+
+func Test_stripJPEGMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+	// 在SOI之后插入APP1(Exif)段和注释段
+	exifSegment := append([]byte{0xFF, 0xE1, 0x00, 0x0C}, []byte("Exif\x00\x00GPS!")...)
+	comment := append([]byte{0xFF, 0xFE, 0x00, 0x06}, []byte("test")...)
+	raw := buf.Bytes()
+	data := append(append(append([]byte{}, raw[:2]...), append(exifSegment, comment...)...), raw[2:]...)
+
+	stripped := stripJPEGMetadata(data)
+	if !bytes.Equal(stripped, raw) {
+		t.Errorf("元数据未完全去除: 原始 %d 字节，去除后 %d 字节", len(raw), len(stripped))
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(stripped)); err != nil {
+		t.Errorf("去除元数据后无法解码: %v", err)
+	}
+}
+
+// gpsExif 构造只含GPS信息（北纬30.5°，东经120.25°）的Exif块：Exif头 + TIFF（小端）
+func gpsExif() []byte {
+	le := binary.LittleEndian
+	tiff := make([]byte, 128)
+	copy(tiff, "II*\x00")
+	le.PutUint32(tiff[4:], 8)
+	// IFD0：只有GPS IFD指针
+	le.PutUint16(tiff[8:], 1)
+	le.PutUint16(tiff[10:], 0x8825)
+	le.PutUint16(tiff[12:], 4)
+	le.PutUint32(tiff[14:], 1)
+	le.PutUint32(tiff[18:], 26)
+	// GPS IFD：纬度参考、纬度、经度参考、经度，有理数数据从偏移80开始
+	le.PutUint16(tiff[26:], 4)
+	entry := func(i int, tag, typ uint16, count, value uint32) {
+		offset := 28 + i*12
+		le.PutUint16(tiff[offset:], tag)
+		le.PutUint16(tiff[offset+2:], typ)
+		le.PutUint32(tiff[offset+4:], count)
+		le.PutUint32(tiff[offset+8:], value)
+	}
+	entry(0, 1, 2, 2, uint32('N'))
+	entry(1, 2, 5, 3, 80)
+	entry(2, 3, 2, 2, uint32('E'))
+	entry(3, 4, 5, 3, 104)
+	for i, v := range []uint32{30, 1, 30, 1, 0, 1, 120, 1, 15, 1, 0, 1} {
+		le.PutUint32(tiff[80+i*4:], v)
+	}
+	return append([]byte("Exif\x00\x00"), tiff...)
+}
+
+// webpWithGPS 构造带GPS信息的扩展格式WebP：VP8X + 1x1无损图像 + EXIF块
+func webpWithGPS(t *testing.T) []byte {
+	simple, err := base64.StdEncoding.DecodeString("UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA==")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk := func(name string, payload []byte) []byte {
+		out := append([]byte(name), 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(out[4:], uint32(len(payload)))
+		out = append(out, payload...)
+		if len(payload)%2 == 1 {
+			out = append(out, 0)
+		}
+		return out
+	}
+	data := []byte("RIFF\x00\x00\x00\x00WEBP")
+	data = append(data, chunk("VP8X", []byte{webpEXIFBit, 0, 0, 0, 0, 0, 0, 0, 0, 0})...)
+	data = append(data, simple[12:]...) // 原图的VP8L块
+	data = append(data, chunk("EXIF", gpsExif())...)
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(data)-8))
+	return data
+}
+
+func Test_sanitizeImage_webp(t *testing.T) {
+	data := webpWithGPS(t)
+	if info := parseExif(data); info == nil || info.Latitude == 0 || info.Longitude == 0 {
+		t.Fatalf("测试图片应包含GPS信息, got=%+v", info)
+	}
+	img, format, err := (&sPicture{}).decodeImage(context.Background(), data)
+	if err != nil || format != "webp" {
+		t.Fatalf("带EXIF的WebP应能解码: format=%s err=%v", format, err)
+	}
+
+	stripped, strippedFormat, err := sanitizeImage(data, img, format, false)
+	if err != nil || strippedFormat != "webp" {
+		t.Fatalf("WebP应原地去除元数据: format=%s err=%v", strippedFormat, err)
+	}
+	if info := parseExif(stripped); info != nil {
+		t.Errorf("去除后仍包含EXIF: %+v", info)
+	}
+	if bytes.Contains(stripped, []byte("EXIF")) || stripped[20]&webpEXIFBit != 0 {
+		t.Error("EXIF块或VP8X标志位未去除")
+	}
+	if binary.LittleEndian.Uint32(stripped[4:]) != uint32(len(stripped)-8) {
+		t.Error("RIFF长度未更新")
+	}
+	if _, _, err = (&sPicture{}).decodeImage(context.Background(), stripped); err != nil {
+		t.Errorf("去除元数据后无法解码: %v", err)
+	}
+}
+
+func Test_sanitizeImage(t *testing.T) {
+	opaque := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for i := range opaque.Pix {
+		opaque.Pix[i] = 0xFF
+	}
+	transparent := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+
+	// HEIC等无法原地去除元数据的格式重新编码：不透明为JPEG，带透明通道为PNG
+	heic := append([]byte("\x00\x00\x00\x18ftypheic"), gpsExif()...)
+	for _, tc := range []struct {
+		img  image.Image
+		want string
+	}{{opaque, "jpeg"}, {transparent, "png"}} {
+		out, format, err := sanitizeImage(heic, tc.img, "heic", false)
+		if err != nil || format != tc.want {
+			t.Fatalf("HEIC应重新编码为%s: format=%s err=%v", tc.want, format, err)
+		}
+		if parseExif(out) != nil {
+			t.Error("重新编码后仍包含EXIF")
+		}
+		if _, decoded, err := image.Decode(bytes.NewReader(out)); err != nil || decoded != tc.want {
+			t.Errorf("重新编码结果无法按%s解码: %s %v", tc.want, decoded, err)
+		}
+	}
+	// 无法解码时不能去除元数据，拒绝入库
+	if _, _, err := sanitizeImage(heic, nil, "heic", false); err == nil {
+		t.Error("无法解码的HEIC应返回错误")
+	}
+
+	// 摆正方向的JPEG按摆正后的像素重新编码，尺寸与摆正后的图片一致
+	rotated := orientImage(opaque, 6)
+	out, format, err := sanitizeImage([]byte("original"), rotated, "jpeg", true)
+	if err != nil || format != "jpeg" {
+		t.Fatalf("摆正的JPEG应重新编码: format=%s err=%v", format, err)
+	}
+	if cfg, err := jpeg.DecodeConfig(bytes.NewReader(out)); err != nil || cfg.Width != 2 || cfg.Height != 4 {
+		t.Errorf("重新编码的尺寸错误: %+v %v", cfg, err)
+	}
+
+	// 不携带EXIF的格式原样保留
+	gif := []byte("GIF89a...")
+	if out, format, err = sanitizeImage(gif, nil, "gif", false); err != nil || format != "gif" || !bytes.Equal(out, gif) {
+		t.Errorf("GIF应原样保留: format=%s err=%v", format, err)
+	}
+
+	if got := formatFileName("IMG_0001.HEIC", "jpeg"); got != "IMG_0001.jpg" {
+		t.Errorf("formatFileName = %s", got)
+	}
+}
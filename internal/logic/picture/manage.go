@@ -9,8 +9,10 @@ import (
 	"cloud/internal/service"
 	"context"
 
+	"github.com/gogf/gf/v2/database/gdb"
 	"github.com/gogf/gf/v2/encoding/gjson"
 	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/os/gtime"
 )
 
@@ -61,11 +63,22 @@ func (s *sPicture) Edit(ctx context.Context, req *v1.PictureEditReq) (res *v1.Pi
 		updateData.SpaceId = req.SpaceId
 	}
 
-	// 4. 更新图片信息到数据库
-	_, err = dao.Picture.Ctx(ctx).Where(pic.Id, req.Id).Data(updateData).Update()
+	// 4. 更新图片信息到数据库，并记录版本历史
+	err = g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		if _, upErr := dao.Picture.Ctx(ctx).TX(tx).Where(pic.Id, req.Id).Data(updateData).Update(); upErr != nil {
+			return gerror.New("更新图片失败")
+		}
+		if logErr := s.recordReviewReset(ctx, tx, picture, user.Id); logErr != nil {
+			return logErr
+		}
+		return s.recordVersion(ctx, tx, picture, versionActionEdit, user.Id, "")
+	})
 	if err != nil {
-		return nil, gerror.New("更新图片失败")
+		return nil, err
 	}
+	s.pruneVersions(ctx, picture.Id)
+	// 修改后的信息重新自动审核
+	s.moderateAsync(ctx, picture.Id)
 
 	return &v1.PictureEditRes{
 		Success: true,
@@ -93,21 +106,33 @@ func (s *sPicture) Delete(ctx context.Context, req *v1.DeleteReq) (res *v1.Delet
 		return nil, gerror.New("无权限删除此图片")
 	}
 
-	// 3. 软删除图片记录
-	_, err = dao.Picture.Ctx(ctx).Where(pic.Id, req.Id).Data(do.Picture{
-		IsDelete:   1,
-		UpdateTime: gtime.Now(),
-	}).Update()
+	// 3. 使用事务：软删除图片 + 释放空间额度
+	err = dao.Picture.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		result, delErr := dao.Picture.Ctx(ctx).TX(tx).Where(pic.Id, req.Id).
+			Where(pic.IsDelete, 0).Data(do.Picture{
+			IsDelete:   1,
+			UpdateTime: gtime.Now(),
+		}).Update()
+		if delErr != nil {
+			g.Log().Errorf(ctx, "删除图片失败 id=%d: %v", req.Id, delErr)
+			return gerror.New("删除图片失败")
+		}
+		// 并发删除时只有一个请求会真正命中，避免重复扣减空间统计
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return gerror.New("图片不存在")
+		}
+		if picture.SpaceId > 0 {
+			if releaseErr := service.Space().ReleaseQuota(ctx, tx, picture.SpaceId, picture.PicSize, 1); releaseErr != nil {
+				return gerror.New("删除图片失败")
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, gerror.New("删除图片失败")
+		return nil, err
 	}
 
-	// 4. 删除对象存储中的文件
-	if _, err = service.Bucket().Delete(ctx, &v1.BucketDeleteReq{
-		FileName: picture.Name,
-	}); err != nil {
-		return nil, gerror.New("删除图片失败")
-	}
+	// 4. 图片进入回收站，存储对象保留到回收站保留期结束后由定时任务彻底删除
 
 	return &v1.DeleteRes{
 		Success: true,
@@ -156,11 +181,20 @@ func (s *sPicture) Update(ctx context.Context, req *v1.PictureUpdateReq) (res *v
 		updateData.Tags = string(tagsJson)
 	}
 
-	// 4. 更新图片信息到数据库
-	_, err = dao.Picture.Ctx(ctx).Where(pic.Id, req.Id).Data(updateData).Update()
+	// 4. 更新图片信息到数据库，并记录版本历史
+	err = g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		if _, upErr := dao.Picture.Ctx(ctx).TX(tx).Where(pic.Id, req.Id).Data(updateData).Update(); upErr != nil {
+			return gerror.New("更新图片失败")
+		}
+		if logErr := s.recordReviewReset(ctx, tx, picture, user.Id); logErr != nil {
+			return logErr
+		}
+		return s.recordVersion(ctx, tx, picture, versionActionUpdate, user.Id, "")
+	})
 	if err != nil {
-		return nil, gerror.New("更新图片失败")
+		return nil, err
 	}
+	s.pruneVersions(ctx, picture.Id)
 
 	return &v1.PictureUpdateRes{
 		Success: true,
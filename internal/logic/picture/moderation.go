@@ -0,0 +1,161 @@
+package picture
+
+import (
+	"cloud/internal/consts"
+	"cloud/internal/dao"
+	"cloud/internal/model/do"
+	"cloud/internal/model/entity"
+	"context"
+	"strings"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+const (
+	defaultModerationReviewScore = 40
+	defaultModerationRejectScore = 90
+	defaultModerationMinSide     = 32
+	defaultBlocklistDistance     = 6
+)
+
+// moderateAsync 图片上传或信息变更后在后台执行自动审核，审核失败时图片保持待审核，由管理员人工处理
+func (s *sPicture) moderateAsync(ctx context.Context, pictureId int64) {
+	if !g.Cfg().MustGet(ctx, "picture.moderation.enabled", true).Bool() {
+		return
+	}
+	s.runBackground(ctx, "自动审核图片", pictureId, func(ctx context.Context, picture *entity.Picture) error {
+		_, err := s.moderatePicture(ctx, picture)
+		return err
+	})
+}
+
+// moderatePicture 依次执行各检查器并汇总结论：自动通过、自动拒绝并给出原因，或标记风险分转人工审核
+// 只处理待审核的图片；审核期间管理员已人工审核的，不覆盖人工结论
+func (s *sPicture) moderatePicture(ctx context.Context, picture *entity.Picture) (*moderationVerdict, error) {
+	if picture.ReviewStatus != consts.DefRwStatus {
+		return nil, nil
+	}
+	started := gtime.Now()
+	reviewScore := g.Cfg().MustGet(ctx, "picture.moderation.reviewScore", defaultModerationReviewScore).Int()
+	checkers, err := s.moderationCheckers(ctx, reviewScore)
+	if err != nil {
+		return nil, err
+	}
+
+	verdicts := make([]*moderationVerdict, 0, len(checkers))
+	contentChecked := false // 内容安全服务是否成功检查了图片内容
+	for _, checker := range checkers {
+		verdict, checkErr := checker.Check(ctx, picture)
+		if checkErr != nil {
+			// 检查器不可用时无法确认内容安全，转人工审核
+			g.Log().Warningf(ctx, "审核检查器 %s 执行失败 pictureId=%d: %v", checker.Name(), picture.Id, checkErr)
+			verdict = &moderationVerdict{Decision: moderationReview, RiskScore: reviewScore, Rule: checker.Name() + ".error",
+				Reason: "自动审核服务不可用"}
+		} else if checker.Name() == moderationProviderName {
+			contentChecked = true
+		}
+		verdicts = append(verdicts, verdict)
+		if verdict != nil && verdict.Decision == moderationReject {
+			break
+		}
+	}
+	// 其余检查器只检查文件与文字信息，未经内容安全服务检查的图片不自动通过
+	autoApprove := contentChecked && g.Cfg().MustGet(ctx, "picture.moderation.autoApprove", false).Bool()
+	final := decideModeration(verdicts, reviewScore, autoApprove)
+
+	data := do.Picture{
+		ReviewMessage:  final.Reason,
+		RiskScore:      final.RiskScore,
+		ModerationRule: final.Rule,
+		UpdateTime:     gtime.Now(),
+	}
+	newStatus := consts.DefRwStatus
+	switch final.Decision {
+	case moderationPass:
+		newStatus = 1
+	case moderationReject:
+		newStatus = 2
+	}
+	if newStatus != consts.DefRwStatus {
+		data.ReviewStatus, data.ReviewerId, data.ReviewTime = newStatus, 0, gtime.Now()
+	}
+	pic := dao.Picture.Columns()
+	err = g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		result, upErr := dao.Picture.Ctx(ctx).TX(tx).Where(pic.Id, picture.Id).
+			Where(pic.IsDelete, 0).Where(pic.ReviewStatus, consts.DefRwStatus).
+			Where("("+pic.ReviewTime+" IS NULL OR "+pic.ReviewTime+" < ?)", started).
+			Data(data).Update()
+		if upErr != nil {
+			g.Log().Errorf(ctx, "保存自动审核结果失败 pictureId=%d: %v", picture.Id, upErr)
+			return gerror.New("保存自动审核结果失败")
+		}
+		// 已被人工审核的图片未更新，不记录审核日志
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return nil
+		}
+		return recordReviewLog(ctx, tx, do.PictureReviewLog{
+			PictureId:  picture.Id,
+			ReviewerId: 0,
+			OldStatus:  consts.DefRwStatus,
+			NewStatus:  newStatus,
+			Message:    final.Reason,
+			Source:     reviewSourceAuto,
+			Rule:       final.Rule,
+			RiskScore:  final.RiskScore,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.Log().Infof(ctx, "自动审核图片 id=%d，结论: %s，规则: %s，风险分: %d", picture.Id, final.Decision, final.Rule, final.RiskScore)
+	return final, nil
+}
+
+// moderationCheckers 按配置创建检查器，依次为黑名单、文件规则、敏感词、内容安全服务（开销最大，放在最后）
+func (s *sPicture) moderationCheckers(ctx context.Context, reviewScore int) ([]moderationChecker, error) {
+	var entries []entity.PictureBlocklist
+	if err := dao.PictureBlocklist.Ctx(ctx).Scan(&entries); err != nil {
+		g.Log().Errorf(ctx, "查询图片黑名单失败: %v", err)
+		return nil, gerror.New("查询图片黑名单失败")
+	}
+	checkers := []moderationChecker{
+		&blocklistChecker{
+			entries:     entries,
+			maxDistance: g.Cfg().MustGet(ctx, "picture.moderation.blocklistDistance", defaultBlocklistDistance).Int(),
+		},
+		&fileRuleChecker{
+			formats: lowerAll(g.Cfg().MustGet(ctx, "picture.moderation.formats").Strings()),
+			maxSize: gfile.StrToSize(g.Cfg().MustGet(ctx, "picture.moderation.maxSize", "0").String()),
+			minSide: g.Cfg().MustGet(ctx, "picture.moderation.minSide", defaultModerationMinSide).Int(),
+		},
+		&keywordChecker{
+			reject: g.Cfg().MustGet(ctx, "picture.moderation.keywords.reject").Strings(),
+			review: g.Cfg().MustGet(ctx, "picture.moderation.keywords.review").Strings(),
+		},
+	}
+
+	switch provider := g.Cfg().MustGet(ctx, "picture.moderation.provider").String(); provider {
+	case "":
+	case consts.AiProviderVolcengine:
+		checkers = append(checkers, &volcengineModeration{
+			apiKey:      volcengineApiKey(ctx),
+			model:       g.Cfg().MustGet(ctx, "ai.vision.model", "doubao-1-5-vision-pro-32k-250115").String(),
+			reviewScore: reviewScore,
+			rejectScore: g.Cfg().MustGet(ctx, "picture.moderation.rejectScore", defaultModerationRejectScore).Int(),
+		})
+	default:
+		return nil, gerror.Newf("不支持的内容安全服务: %s", provider)
+	}
+	return checkers, nil
+}
+
+func lowerAll(values []string) []string {
+	for i := range values {
+		values[i] = strings.ToLower(strings.TrimSpace(values[i]))
+	}
+	return values
+}
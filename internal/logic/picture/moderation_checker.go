@@ -0,0 +1,161 @@
+package picture
+
+import (
+	"cloud/internal/model/entity"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// 审核结论
+const (
+	moderationPass   = "pass"   // 未发现问题
+	moderationReview = "review" // 交由人工审核
+	moderationReject = "reject" // 自动拒绝
+)
+
+// moderationProviderName 内容安全服务检查器的名称，只有该检查器会检查图片内容本身
+const moderationProviderName = "provider"
+
+// moderationVerdict 审核结论：检查器命中的规则，或整条流水线的最终结论
+type moderationVerdict struct {
+	Decision  string
+	RiskScore int    // 风险分 0-100
+	Rule      string // 决定结论的规则，如 keyword.reject、file.format、blocklist.exact
+	Reason    string // 原因，拒绝时作为审核信息返回给用户
+}
+
+// moderationChecker 内容审核检查器
+type moderationChecker interface {
+	// Name 检查器名称，作为规则前缀
+	Name() string
+	// Check 检查图片，未命中任何规则时返回nil
+	Check(ctx context.Context, picture *entity.Picture) (*moderationVerdict, error)
+}
+
+// keywordChecker 检查图片名称与简介中的敏感词，不区分大小写
+type keywordChecker struct {
+	reject []string // 命中即拒绝
+	review []string // 命中转人工审核
+}
+
+func (c *keywordChecker) Name() string {
+	return "keyword"
+}
+
+func (c *keywordChecker) Check(ctx context.Context, picture *entity.Picture) (*moderationVerdict, error) {
+	text := strings.ToLower(picture.Name + "\n" + picture.Introduction)
+	if keyword := matchKeyword(text, c.reject); keyword != "" {
+		return &moderationVerdict{Decision: moderationReject, RiskScore: 100, Rule: "keyword.reject",
+			Reason: fmt.Sprintf("图片名称或简介包含违规内容：%s", keyword)}, nil
+	}
+	if keyword := matchKeyword(text, c.review); keyword != "" {
+		return &moderationVerdict{Decision: moderationReview, RiskScore: 60, Rule: "keyword.review",
+			Reason: fmt.Sprintf("图片名称或简介包含敏感词：%s", keyword)}, nil
+	}
+	return nil, nil
+}
+
+func matchKeyword(text string, keywords []string) string {
+	for _, keyword := range keywords {
+		if keyword = strings.TrimSpace(keyword); keyword != "" && strings.Contains(text, strings.ToLower(keyword)) {
+			return keyword
+		}
+	}
+	return ""
+}
+
+// fileRuleChecker 检查文件格式、大小与尺寸
+type fileRuleChecker struct {
+	formats []string // 允许的格式，为空时不限制
+	maxSize int64    // 文件大小上限，超出拒绝，0为不限制
+	minSide int      // 最短边下限，过小的图片转人工审核，0为不限制
+}
+
+func (c *fileRuleChecker) Name() string {
+	return "file"
+}
+
+func (c *fileRuleChecker) Check(ctx context.Context, picture *entity.Picture) (*moderationVerdict, error) {
+	if len(c.formats) > 0 && !slices.Contains(c.formats, strings.ToLower(picture.PicFormat)) {
+		return &moderationVerdict{Decision: moderationReject, RiskScore: 100, Rule: "file.format",
+			Reason: fmt.Sprintf("不支持的图片格式：%s", picture.PicFormat)}, nil
+	}
+	if c.maxSize > 0 && picture.PicSize > c.maxSize {
+		return &moderationVerdict{Decision: moderationReject, RiskScore: 100, Rule: "file.size",
+			Reason: fmt.Sprintf("图片文件过大（%.2fMB）", float64(picture.PicSize)/1024/1024)}, nil
+	}
+	// 尺寸未知（无法解码）或过小的图片无法确认内容
+	if c.minSide > 0 && min(picture.PicWidth, picture.PicHeight) < c.minSide {
+		return &moderationVerdict{Decision: moderationReview, RiskScore: 50, Rule: "file.dimension",
+			Reason: fmt.Sprintf("图片尺寸过小（%dx%d）", picture.PicWidth, picture.PicHeight)}, nil
+	}
+	return nil, nil
+}
+
+// blocklistChecker 与黑名单比对：内容完全相同的拒绝，感知哈希相近的转人工审核
+type blocklistChecker struct {
+	entries     []entity.PictureBlocklist
+	maxDistance int // 判定为近似图片的最大汉明距离
+}
+
+func (c *blocklistChecker) Name() string {
+	return "blocklist"
+}
+
+func (c *blocklistChecker) Check(ctx context.Context, picture *entity.Picture) (*moderationVerdict, error) {
+	hash, hasHash := parsePicHash(picture.PicHash)
+	var similar *entity.PictureBlocklist
+	for i := range c.entries {
+		entry := &c.entries[i]
+		if picture.ContentHash != "" && entry.ContentHash == picture.ContentHash {
+			return &moderationVerdict{Decision: moderationReject, RiskScore: 100, Rule: "blocklist.exact",
+				Reason: "图片已被列入黑名单：" + entry.Reason}, nil
+		}
+		if similar == nil && hasHash {
+			if entryHash, ok := parsePicHash(entry.PicHash); ok && hammingDistance(hash, entryHash) <= c.maxDistance {
+				similar = entry
+			}
+		}
+	}
+	if similar != nil {
+		return &moderationVerdict{Decision: moderationReview, RiskScore: 80, Rule: "blocklist.similar",
+			Reason: "图片与黑名单中的图片相似：" + similar.Reason}, nil
+	}
+	return nil, nil
+}
+
+// decideModeration 汇总各检查器的结论：任一拒绝即拒绝；任一转人工或风险分达到人工审核阈值时转人工；
+// 否则开启自动通过时通过，未开启时保持待审核。结论记录决定结果的规则与最高风险分
+func decideModeration(verdicts []*moderationVerdict, reviewScore int, autoApprove bool) *moderationVerdict {
+	var top, review *moderationVerdict // 风险分最高的结论、风险分最高的转人工结论
+	for _, verdict := range verdicts {
+		if verdict == nil {
+			continue
+		}
+		if verdict.Decision == moderationReject {
+			return verdict
+		}
+		if top == nil || verdict.RiskScore > top.RiskScore {
+			top = verdict
+		}
+		if verdict.Decision == moderationReview && (review == nil || verdict.RiskScore > review.RiskScore) {
+			review = verdict
+		}
+	}
+	score := 0
+	if top != nil {
+		score = top.RiskScore
+	}
+	if review == nil && score >= reviewScore {
+		review = top
+	}
+	if review != nil {
+		return &moderationVerdict{Decision: moderationReview, RiskScore: score, Rule: review.Rule, Reason: review.Reason}
+	}
+	if !autoApprove {
+		return &moderationVerdict{Decision: moderationReview, RiskScore: score, Rule: "none", Reason: "等待人工审核"}
+	}
+	return &moderationVerdict{Decision: moderationPass, RiskScore: score, Rule: "auto", Reason: "自动审核通过"}
+}
@@ -0,0 +1,107 @@
+package picture
+
+import (
+	"cloud/internal/model/entity"
+	"context"
+	"testing"
+)
+
+func Test_keywordChecker(t *testing.T) {
+	checker := &keywordChecker{reject: []string{"Casino"}, review: []string{"抽奖"}}
+	ctx := context.Background()
+
+	verdict, _ := checker.Check(ctx, &entity.Picture{Name: "online CASINO banner"})
+	if verdict == nil || verdict.Decision != moderationReject || verdict.Rule != "keyword.reject" {
+		t.Errorf("命中拒绝词应拒绝, got=%+v", verdict)
+	}
+	verdict, _ = checker.Check(ctx, &entity.Picture{Name: "海报", Introduction: "转发抽奖活动"})
+	if verdict == nil || verdict.Decision != moderationReview || verdict.Rule != "keyword.review" {
+		t.Errorf("命中敏感词应转人工审核, got=%+v", verdict)
+	}
+	if verdict, _ = checker.Check(ctx, &entity.Picture{Name: "风景"}); verdict != nil {
+		t.Errorf("未命中时应返回nil, got=%+v", verdict)
+	}
+}
+
+func Test_fileRuleChecker(t *testing.T) {
+	checker := &fileRuleChecker{formats: []string{"jpeg", "png"}, maxSize: 1024, minSide: 32}
+	ctx := context.Background()
+	tests := []struct {
+		picture *entity.Picture
+		rule    string
+	}{
+		{&entity.Picture{PicFormat: "gif", PicSize: 10, PicWidth: 100, PicHeight: 100}, "file.format"},
+		{&entity.Picture{PicFormat: "PNG", PicSize: 2048, PicWidth: 100, PicHeight: 100}, "file.size"},
+		{&entity.Picture{PicFormat: "jpeg", PicSize: 10, PicWidth: 100, PicHeight: 16}, "file.dimension"},
+		{&entity.Picture{PicFormat: "jpeg", PicSize: 10, PicWidth: 100, PicHeight: 100}, ""},
+	}
+	for _, tt := range tests {
+		verdict, _ := checker.Check(ctx, tt.picture)
+		rule := ""
+		if verdict != nil {
+			rule = verdict.Rule
+		}
+		if rule != tt.rule {
+			t.Errorf("Check(%+v) rule = %q, want %q", tt.picture, rule, tt.rule)
+		}
+	}
+}
+
+func Test_blocklistChecker(t *testing.T) {
+	checker := &blocklistChecker{
+		entries:     []entity.PictureBlocklist{{ContentHash: "abc", PicHash: formatPicHash(0xFF00), Reason: "侵权"}},
+		maxDistance: 4,
+	}
+	ctx := context.Background()
+
+	verdict, _ := checker.Check(ctx, &entity.Picture{ContentHash: "abc"})
+	if verdict == nil || verdict.Decision != moderationReject || verdict.Rule != "blocklist.exact" {
+		t.Errorf("内容相同应拒绝, got=%+v", verdict)
+	}
+	verdict, _ = checker.Check(ctx, &entity.Picture{ContentHash: "def", PicHash: formatPicHash(0xFF03)})
+	if verdict == nil || verdict.Decision != moderationReview || verdict.Rule != "blocklist.similar" {
+		t.Errorf("近似图片应转人工审核, got=%+v", verdict)
+	}
+	if verdict, _ = checker.Check(ctx, &entity.Picture{ContentHash: "def", PicHash: formatPicHash(0x00FF)}); verdict != nil {
+		t.Errorf("不相似的图片应通过, got=%+v", verdict)
+	}
+}
+
+func Test_decideModeration(t *testing.T) {
+	reject := &moderationVerdict{Decision: moderationReject, RiskScore: 100, Rule: "keyword.reject"}
+	review := &moderationVerdict{Decision: moderationReview, RiskScore: 20, Rule: "file.dimension"}
+	lowRisk := &moderationVerdict{Decision: moderationPass, RiskScore: 30, Rule: "provider.volcengine"}
+	highRisk := &moderationVerdict{Decision: moderationPass, RiskScore: 50, Rule: "provider.volcengine"}
+
+	if got := decideModeration([]*moderationVerdict{review, reject}, 40, true); got.Rule != "keyword.reject" {
+		t.Errorf("任一拒绝应拒绝, got=%+v", got)
+	}
+	if got := decideModeration([]*moderationVerdict{review, nil, lowRisk}, 40, true); got.Decision != moderationReview || got.Rule != "file.dimension" || got.RiskScore != 30 {
+		t.Errorf("任一转人工应转人工，风险分取最高, got=%+v", got)
+	}
+	if got := decideModeration([]*moderationVerdict{highRisk}, 40, true); got.Decision != moderationReview {
+		t.Errorf("风险分达到阈值应转人工, got=%+v", got)
+	}
+	if got := decideModeration([]*moderationVerdict{nil, lowRisk}, 40, true); got.Decision != moderationPass || got.Rule != "auto" || got.RiskScore != 30 {
+		t.Errorf("低风险应自动通过, got=%+v", got)
+	}
+	if got := decideModeration(nil, 40, false); got.Decision != moderationReview || got.Rule != "none" {
+		t.Errorf("未开启自动通过应保持待审核, got=%+v", got)
+	}
+}
+
+func Test_parseModerationAnswer(t *testing.T) {
+	verdict, err := parseModerationAnswer(`{"riskScore":95,"labels":["暴力血腥"],"reason":"画面含血腥内容"}`, 40, 90)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verdict.Decision != moderationReject || verdict.RiskScore != 95 || verdict.Rule != "provider.volcengine" {
+		t.Errorf("高风险应拒绝, got=%+v", verdict)
+	}
+	if verdict, _ = parseModerationAnswer("```json\n{\"riskScore\":0}\n```", 40, 90); verdict.Decision != moderationPass {
+		t.Errorf("无风险应通过, got=%+v", verdict)
+	}
+	if _, err = parseModerationAnswer("无法判断", 40, 90); err == nil {
+		t.Error("非JSON回复应返回错误")
+	}
+}
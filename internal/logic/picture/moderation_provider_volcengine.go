@@ -0,0 +1,70 @@
+package picture
+
+import (
+	"cloud/internal/consts"
+	"cloud/internal/model/entity"
+	"cloud/internal/service"
+	"context"
+	"strings"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// volcengineModeration 使用火山引擎视觉理解模型评估图片风险（色情、暴力、政治敏感、违法等）
+type volcengineModeration struct {
+	apiKey      string
+	model       string
+	reviewScore int // 风险分达到该值转人工审核
+	rejectScore int // 风险分达到该值自动拒绝
+}
+
+func (c *volcengineModeration) Name() string {
+	return moderationProviderName
+}
+
+func (c *volcengineModeration) Check(ctx context.Context, picture *entity.Picture) (*moderationVerdict, error) {
+	if c.apiKey == "" {
+		return nil, gerror.New("AI服务未配置密钥")
+	}
+	prompt := "你是图片内容安全审核员。评估这张图片包含色情、暴力血腥、政治敏感、违法违规内容的风险，" +
+		"只返回JSON：{\"riskScore\":0到100的整数,\"labels\":[风险类型],\"reason\":\"不超过30字的中文说明\"}，没有风险时riskScore为0。"
+	answer, err := volcengineChat(ctx, c.apiKey, c.model, service.Bucket().SignedUrl(ctx, picture.Url), prompt)
+	if err != nil {
+		return nil, err
+	}
+	return parseModerationAnswer(answer, c.reviewScore, c.rejectScore)
+}
+
+// parseModerationAnswer 解析模型给出的风险评估，按阈值得出结论
+func parseModerationAnswer(answer string, reviewScore int, rejectScore int) (*moderationVerdict, error) {
+	content, ok := extractJSON(answer)
+	if !ok {
+		return nil, gerror.New("AI服务返回结果格式错误")
+	}
+	var result struct {
+		RiskScore int      `json:"riskScore"`
+		Labels    []string `json:"labels"`
+		Reason    string   `json:"reason"`
+	}
+	if err := gjson.DecodeTo(content, &result); err != nil {
+		return nil, gerror.New("AI服务返回结果格式错误")
+	}
+	verdict := &moderationVerdict{
+		Decision:  moderationPass,
+		RiskScore: min(max(result.RiskScore, 0), 100),
+		Rule:      "provider." + consts.AiProviderVolcengine,
+		Reason:    result.Reason,
+	}
+	if len(result.Labels) > 0 {
+		verdict.Reason = strings.Join(result.Labels, "、") + "：" + result.Reason
+	}
+	switch {
+	case verdict.RiskScore >= rejectScore:
+		verdict.Decision = moderationReject
+		verdict.Reason = "图片内容违规（" + verdict.Reason + "）"
+	case verdict.RiskScore >= reviewScore:
+		verdict.Decision = moderationReview
+	}
+	return verdict, nil
+}
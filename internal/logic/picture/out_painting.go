@@ -2,18 +2,18 @@ package picture
 
 import (
 	v1 "cloud/api/user/v1"
-	"cloud/internal/consts"
 	"cloud/internal/dao"
 	"cloud/internal/model/entity"
 	"cloud/internal/service"
 	"context"
-	"fmt"
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/disintegration/imaging"
+	"github.com/gogf/gf/v2/encoding/gjson"
 
 	"github.com/gogf/gf/v2/errors/gerror"
-	"github.com/gogf/gf/v2/frame/g"
-	"github.com/volcengine/volcengine-go-sdk/service/arkruntime"
-	"github.com/volcengine/volcengine-go-sdk/service/arkruntime/model"
-	"github.com/volcengine/volcengine-go-sdk/volcengine"
 )
 
 // CreateOutPainting 创建扩图
@@ -38,51 +38,131 @@ func (s *sPicture) CreateOutPainting(ctx context.Context, req *v1.CreatePictureO
 		return nil, gerror.New("无权限对此图片进行扩图")
 	}
 
-	// 4. 调用火山引擎扩图API
-	client := arkruntime.NewClientWithApiKey(g.Cfg().MustGet(ctx, consts.AiKey).String())
-	if client == nil {
-		return nil, gerror.New("AI服务初始化失败，请检查配置")
+	// 4. 按原图尺寸计算扩图画布，并校验空间级别的扩图限制
+	canvas, err := newOutPaintingCanvas(picture.PicWidth, picture.PicHeight, req.Parameters)
+	if err != nil {
+		return nil, err
 	}
-
-	// 构建扩图请求
-	responseFormat := model.GenerateImagesResponseFormatURL
-	size := model.GenerateImagesSizeAdaptive
-	generateReq := model.GenerateImagesRequest{
-		Model:          "doubao-seededit-3-0-i2i-250628",
-		Prompt:         req.Prompt,
-		Image:          picture.Url,
-		ResponseFormat: &responseFormat,
-		Seed:           volcengine.Int64(123),
-		GuidanceScale:  volcengine.Float64(5.5),
-		Size:           &size,
-		Watermark:      volcengine.Bool(true),
+	level, err := service.Space().GetLevel(ctx, picture.SpaceId)
+	if err != nil {
+		return nil, err
+	}
+	if err = canvas.checkLimit(level); err != nil {
+		return nil, err
 	}
 
-	g.Log().Infof(ctx, "开始扩图，用户ID: %d, 图片ID: %d, 描述: %s", user.Id, req.PictureId, req.Prompt)
-
-	// 调用扩图API
-	imagesResponse, err := client.GenerateImages(ctx, generateReq)
+	// 5. 创建扩图任务，由后台任务处理协程调用AI服务，完成后通过任务查询接口或WebSocket获取结果
+	task, err := s.createAITask(ctx, user.Id, picture, aiTaskTypeOutPainting, req.Prompt, canvas, req.SaveMode)
 	if err != nil {
-		g.Log().Errorf(ctx, "扩图失败: %v", err)
 		return &v1.CreatePictureOutPaintingRes{
 			Success: false,
-			Message: fmt.Sprintf("扩图失败: %v", err),
+			Message: err.Error(),
 		}, nil
 	}
 
-	if len(imagesResponse.Data) == 0 {
-		return &v1.CreatePictureOutPaintingRes{
-			Success: false,
-			Message: "扩图返回结果为空",
-		}, nil
-	}
+	return &v1.CreatePictureOutPaintingRes{
+		TaskId:       aiTaskId(task),
+		TaskStatus:   task.Status,
+		OutputWidth:  canvas.Width,
+		OutputHeight: canvas.Height,
+		Success:      true,
+		Message:      "扩图任务已提交",
+	}, nil
+}
 
-	outputImageUrl := *imagesResponse.Data[0].Url
-	g.Log().Infof(ctx, "扩图成功，用户ID: %d, 图片ID: %d, 结果URL: %s", user.Id, req.PictureId, outputImageUrl)
+// outPaintingCanvas 扩图画布：原图居中放置在按比例扩大后的画布上，四周为需要AI补全的区域
+type outPaintingCanvas struct {
+	XScale       float64 `json:"xScale"`
+	YScale       float64 `json:"yScale"`
+	SourceWidth  int     `json:"sourceWidth"`
+	SourceHeight int     `json:"sourceHeight"`
+	Width        int     `json:"width"`
+	Height       int     `json:"height"`
+}
 
-	return &v1.CreatePictureOutPaintingRes{
-		OutputImageUrl: outputImageUrl,
-		Success:        true,
-		Message:        "扩图成功",
+// newOutPaintingCanvas 根据原图尺寸与扩图比例计算画布尺寸，比例为空时按1处理
+func newOutPaintingCanvas(width, height int, params *v1.OutPaintingParameters) (*outPaintingCanvas, error) {
+	if width <= 0 || height <= 0 {
+		return nil, gerror.New("原图尺寸未知，无法扩图")
+	}
+	xScale, yScale := 1.0, 1.0
+	if params != nil {
+		if params.XScale != 0 {
+			xScale = params.XScale
+		}
+		if params.YScale != 0 {
+			yScale = params.YScale
+		}
+	}
+	if xScale < 1 || yScale < 1 {
+		return nil, gerror.New("扩图比例不能小于1")
+	}
+	if xScale == 1 && yScale == 1 {
+		return nil, gerror.New("至少一个方向的扩图比例需大于1")
+	}
+	return &outPaintingCanvas{
+		XScale:       xScale,
+		YScale:       yScale,
+		SourceWidth:  width,
+		SourceHeight: height,
+		Width:        int(math.Round(float64(width) * xScale)),
+		Height:       int(math.Round(float64(height) * yScale)),
 	}, nil
 }
+
+// checkLimit 校验扩图比例与扩图后的像素数不超过空间级别的限制
+func (c *outPaintingCanvas) checkLimit(level *v1.SpaceLevel) error {
+	if level.MaxOutPaintingScale > 0 && (c.XScale > level.MaxOutPaintingScale || c.YScale > level.MaxOutPaintingScale) {
+		return gerror.Newf("%s空间的扩图比例最大为 %g", level.Name, level.MaxOutPaintingScale)
+	}
+	if level.MaxOutPaintingPixels > 0 && int64(c.Width)*int64(c.Height) > level.MaxOutPaintingPixels {
+		return gerror.Newf("扩图后的尺寸 %dx%d 超过%s空间的上限（%d 万像素）", c.Width, c.Height, level.Name, level.MaxOutPaintingPixels/10000)
+	}
+	return nil
+}
+
+// offset 原图在画布上的位置
+func (c *outPaintingCanvas) offset() image.Point {
+	return image.Pt((c.Width-c.SourceWidth)/2, (c.Height-c.SourceHeight)/2)
+}
+
+// pad 将原图居中放置到画布上，扩展区域透明；mask中需要补全的区域为白色，原图区域为黑色
+func (c *outPaintingCanvas) pad(img image.Image) (*image.NRGBA, *image.Gray) {
+	padded := imaging.Paste(image.NewNRGBA(image.Rect(0, 0, c.Width, c.Height)), img, c.offset())
+	mask := image.NewGray(padded.Bounds())
+	draw.Draw(mask, mask.Bounds(), image.White, image.Point{}, draw.Src)
+	source := image.Rectangle{Min: c.offset(), Max: c.offset().Add(image.Pt(c.SourceWidth, c.SourceHeight))}
+	draw.Draw(mask, source, image.Black, image.Point{}, draw.Src)
+	return padded, mask
+}
+
+// prepareOutPainting 读取原图，按任务记录的比例生成扩图画布与蒙版
+// 原图文件可能在排队期间被新版本替换，画布按当前文件的实际尺寸重新计算并校验限制
+func (s *sPicture) prepareOutPainting(ctx context.Context, picture *entity.Picture, parameters string, req *aiImageRequest) error {
+	var params *v1.OutPaintingParameters
+	if err := gjson.DecodeTo(parameters, &params); err != nil {
+		return &aiTaskAbortError{gerror.New("扩图参数无效")}
+	}
+	data, err := service.Bucket().ReadObject(ctx, req.ImageKey, pictureMaxSize(ctx))
+	if err != nil {
+		return err
+	}
+	img, _, err := s.decodeImage(ctx, data)
+	if err != nil {
+		return &aiTaskAbortError{gerror.New("原图解码失败")}
+	}
+	canvas, err := newOutPaintingCanvas(img.Bounds().Dx(), img.Bounds().Dy(), params)
+	if err != nil {
+		return &aiTaskAbortError{err}
+	}
+	level, err := service.Space().GetLevel(ctx, picture.SpaceId)
+	if err != nil {
+		return &aiTaskAbortError{err}
+	}
+	if err = canvas.checkLimit(level); err != nil {
+		return &aiTaskAbortError{err}
+	}
+	req.Canvas = canvas
+	req.Padded, req.Mask = canvas.pad(img)
+	return nil
+}
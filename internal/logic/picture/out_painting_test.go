@@ -0,0 +1,63 @@
+package picture
+
+import (
+	v1 "cloud/api/user/v1"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/disintegration/imaging"
+)
+
+func Test_newOutPaintingCanvas(t *testing.T) {
+	canvas, err := newOutPaintingCanvas(100, 50, &v1.OutPaintingParameters{XScale: 1.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if canvas.Width != 150 || canvas.Height != 50 || canvas.YScale != 1 {
+		t.Errorf("画布尺寸错误, got=%+v", canvas)
+	}
+	if canvas.offset() != image.Pt(25, 0) {
+		t.Errorf("原图应居中, got=%v", canvas.offset())
+	}
+
+	for _, params := range []*v1.OutPaintingParameters{nil, {XScale: 1, YScale: 1}, {XScale: 0.5, YScale: 2}} {
+		if _, err = newOutPaintingCanvas(100, 50, params); err == nil {
+			t.Errorf("扩图比例 %+v 应校验失败", params)
+		}
+	}
+	if _, err = newOutPaintingCanvas(0, 0, &v1.OutPaintingParameters{XScale: 2}); err == nil {
+		t.Error("原图尺寸未知时应校验失败")
+	}
+}
+
+func Test_outPaintingCanvas_checkLimit(t *testing.T) {
+	level := &v1.SpaceLevel{Name: "普通版", MaxOutPaintingScale: 2, MaxOutPaintingPixels: 1000 * 1000}
+	canvas, _ := newOutPaintingCanvas(400, 300, &v1.OutPaintingParameters{XScale: 2, YScale: 2})
+	if err := canvas.checkLimit(level); err != nil {
+		t.Errorf("未超出限制, got=%v", err)
+	}
+	canvas, _ = newOutPaintingCanvas(400, 300, &v1.OutPaintingParameters{XScale: 2.5})
+	if err := canvas.checkLimit(level); err == nil {
+		t.Error("超出最大比例应校验失败")
+	}
+	canvas, _ = newOutPaintingCanvas(800, 600, &v1.OutPaintingParameters{XScale: 2, YScale: 2})
+	if err := canvas.checkLimit(level); err == nil {
+		t.Error("超出最大像素数应校验失败")
+	}
+}
+
+func Test_outPaintingCanvas_pad(t *testing.T) {
+	canvas, _ := newOutPaintingCanvas(4, 2, &v1.OutPaintingParameters{XScale: 2, YScale: 2})
+	padded, mask := canvas.pad(imaging.New(4, 2, color.NRGBA{R: 255, A: 255}))
+	if padded.Bounds() != image.Rect(0, 0, 8, 4) || mask.Bounds() != padded.Bounds() {
+		t.Fatalf("画布尺寸错误, got=%v %v", padded.Bounds(), mask.Bounds())
+	}
+	// 原图位于(2,1)-(6,3)，蒙版为黑色；扩展区域透明，蒙版为白色
+	if padded.NRGBAAt(2, 1).R != 255 || mask.GrayAt(2, 1).Y != 0 {
+		t.Errorf("原图区域错误, got=%v %v", padded.NRGBAAt(2, 1), mask.GrayAt(2, 1))
+	}
+	if padded.NRGBAAt(0, 0).A != 0 || mask.GrayAt(0, 0).Y != 255 || mask.GrayAt(6, 3).Y != 255 {
+		t.Errorf("扩展区域错误, got=%v %v", padded.NRGBAAt(0, 0), mask.GrayAt(0, 0))
+	}
+}
@@ -0,0 +1,88 @@
+package picture
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	phashSampleSize = 32 // 计算感知哈希前缩放的边长
+	phashDctSize    = 8  // 保留的低频DCT系数边长，8x8=64位
+)
+
+// phashCos 预计算的DCT余弦系数
+var phashCos = func() (table [phashDctSize][phashSampleSize]float64) {
+	for u := 0; u < phashDctSize; u++ {
+		for x := 0; x < phashSampleSize; x++ {
+			table[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * phashSampleSize))
+		}
+	}
+	return table
+}()
+
+// perceptualHash 计算图片的感知哈希（pHash）：缩放为32x32灰度图，取DCT左上8x8低频系数与中位数比较
+func perceptualHash(img image.Image) uint64 {
+	small := imaging.Resize(img, phashSampleSize, phashSampleSize, imaging.Lanczos)
+
+	// 1. 转为灰度矩阵
+	var gray [phashSampleSize][phashSampleSize]float64
+	for y := 0; y < phashSampleSize; y++ {
+		for x := 0; x < phashSampleSize; x++ {
+			i := small.PixOffset(x, y)
+			r, g, b := float64(small.Pix[i]), float64(small.Pix[i+1]), float64(small.Pix[i+2])
+			gray[y][x] = 0.299*r + 0.587*g + 0.114*b
+		}
+	}
+
+	// 2. 二维DCT，只计算低频部分
+	var coeffs [phashDctSize * phashDctSize]float64
+	for v := 0; v < phashDctSize; v++ {
+		for u := 0; u < phashDctSize; u++ {
+			var sum float64
+			for y := 0; y < phashSampleSize; y++ {
+				for x := 0; x < phashSampleSize; x++ {
+					sum += gray[y][x] * phashCos[u][x] * phashCos[v][y]
+				}
+			}
+			coeffs[v*phashDctSize+u] = sum
+		}
+	}
+
+	// 3. 以交流分量的中位数为阈值生成64位哈希（直流分量只反映整体亮度，不参与阈值计算）
+	sorted := append([]float64(nil), coeffs[1:]...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+
+	var hash uint64
+	for i, c := range coeffs {
+		if c > median {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash
+}
+
+// hammingDistance 两个感知哈希的汉明距离，越小越相似
+func hammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// formatPicHash 感知哈希转为16位十六进制字符串存储
+func formatPicHash(hash uint64) string {
+	return fmt.Sprintf("%016x", hash)
+}
+
+// parsePicHash 解析存储的感知哈希
+func parsePicHash(value string) (uint64, bool) {
+	if len(value) != 16 {
+		return 0, false
+	}
+	hash, err := strconv.ParseUint(value, 16, 64)
+	return hash, err == nil
+}
@@ -0,0 +1,52 @@
+package picture
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/disintegration/imaging"
+)
+
+func Test_perceptualHash(t *testing.T) {
+	// 构造带有明显结构的图片：缩放、调亮后仍应判定为相似
+	origin := image.NewNRGBA(image.Rect(0, 0, 400, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 400; x++ {
+			c := color.NRGBA{R: 30, G: 60, B: 120, A: 255}
+			if x > 60 && x < 220 && y > 40 && y < 180 {
+				c = color.NRGBA{R: 240, G: 200, B: 40, A: 255}
+			}
+			if (x-300)*(x-300)+(y-200)*(y-200) < 60*60 {
+				c = color.NRGBA{R: 220, G: 40, B: 40, A: 255}
+			}
+			origin.Set(x, y, c)
+		}
+	}
+	resized := imaging.AdjustBrightness(imaging.Resize(origin, 200, 150, imaging.Linear), 10)
+
+	// 棋盘图：与原图结构完全不同
+	checker := image.NewNRGBA(image.Rect(0, 0, 400, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 400; x++ {
+			if (x/50+y/50)%2 == 0 {
+				checker.Set(x, y, color.White)
+			} else {
+				checker.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	base := perceptualHash(origin)
+	if d := hammingDistance(base, perceptualHash(resized)); d > 6 {
+		t.Errorf("缩放后的图片距离过大: %d", d)
+	}
+	if d := hammingDistance(base, perceptualHash(checker)); d <= 10 {
+		t.Errorf("不同图片距离过小: %d", d)
+	}
+
+	hash, ok := parsePicHash(formatPicHash(base))
+	if !ok || hash != base {
+		t.Errorf("哈希格式化往返失败: %x -> %x", base, hash)
+	}
+}
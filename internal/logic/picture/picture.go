@@ -8,8 +8,16 @@ func init() {
 	service.RegisterPicture(New())
 }
 
-type sPicture struct{}
+const backgroundConcurrency = 4 // 上传后后台处理的最大并发数
+
+type sPicture struct {
+	aiTaskWake chan struct{} // 新建AI任务时唤醒空闲的任务处理协程
+	bgSlots    chan struct{} // 限制上传后后台处理（智能标注、内容审核）的并发数
+}
 
 func New() *sPicture {
-	return &sPicture{}
+	return &sPicture{
+		aiTaskWake: make(chan struct{}, 1),
+		bgSlots:    make(chan struct{}, backgroundConcurrency),
+	}
 }
@@ -0,0 +1,179 @@
+package picture
+
+import (
+	v1 "cloud/api/user/v1"
+	"cloud/internal/consts"
+	"cloud/internal/dao"
+	"cloud/internal/model/do"
+	"cloud/internal/model/entity"
+	"cloud/internal/service"
+	"context"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+// purgeBatchSize 回收站清理每批处理的图片数量
+const purgeBatchSize = 100
+
+// ListRecycleByPage 分页查询回收站中的图片
+func (s *sPicture) ListRecycleByPage(ctx context.Context, req *v1.PictureRecycleQueryReq) (res *v1.PictureRecycleQueryRes, err error) {
+	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 构建查询条件：空间回收站需要空间权限，否则只能查看自己在公共图库删除的图片
+	pic := dao.Picture.Columns()
+	db := dao.Picture.Ctx(ctx).Where(pic.IsDelete, 1)
+	if req.SpaceId > 0 {
+		if !s.hasSpacePermission(ctx, req.SpaceId, user) {
+			return nil, gerror.New("无权限查看此空间的回收站")
+		}
+		db = db.Where(pic.SpaceId, req.SpaceId)
+	} else {
+		db = db.Where(pic.SpaceId, 0).Where(pic.UserId, user.Id)
+	}
+
+	// 2. 查询总数与分页数据，按删除时间倒序
+	total, err := db.Count()
+	if err != nil {
+		return nil, gerror.New("查询失败")
+	}
+	var pictures []entity.Picture
+	err = db.Page(req.Current, req.PageSize).OrderDesc(pic.UpdateTime).Scan(&pictures)
+	if err != nil {
+		return nil, gerror.New("查询失败")
+	}
+
+	retention := s.recycleRetention(ctx)
+	records := make([]v1.PictureRecycleVO, 0, len(pictures))
+	for i := range pictures {
+		// 删除时会更新updateTime，已删除的图片不再允许编辑，因此updateTime即为删除时间
+		deleteTime := pictures[i].UpdateTime
+		records = append(records, v1.PictureRecycleVO{
+			PictureVO:  s.entityToVO(ctx, &pictures[i]),
+			DeleteTime: deleteTime.Format(consts.Y_m_d_His),
+			PurgeTime:  deleteTime.Add(retention).Format(consts.Y_m_d_His),
+		})
+	}
+
+	return &v1.PictureRecycleQueryRes{
+		Records: records,
+		PageInfo: &v1.PageInfo{
+			Current: req.Current,
+			Size:    req.PageSize,
+			Total:   total,
+			Pages:   (total + req.PageSize - 1) / req.PageSize,
+		},
+	}, nil
+}
+
+// Restore 从回收站恢复图片，恢复到空间时重新占用空间额度
+func (s *sPicture) Restore(ctx context.Context, req *v1.PictureRestoreReq) (res *v1.PictureRestoreRes, err error) {
+	var picture *entity.Picture
+	pic := dao.Picture.Columns()
+	err = dao.Picture.Ctx(ctx).Where(pic.Id, req.Id).
+		Where(pic.IsDelete, 1).Scan(&picture)
+	if err != nil || picture == nil {
+		return nil, gerror.New("回收站中不存在此图片")
+	}
+
+	// 1. 验证用户权限：图片创建者、空间管理者或管理员可以恢复
+	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
+	if err != nil {
+		return nil, err
+	}
+	if picture.UserId != user.Id && user.UserRole != consts.Admin &&
+		(picture.SpaceId == 0 || !s.hasSpacePermission(ctx, picture.SpaceId, user)) {
+		return nil, gerror.New("无权限恢复此图片")
+	}
+
+	// 2. 使用事务：恢复图片 + 重新占用空间额度
+	err = dao.Picture.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		result, updateErr := dao.Picture.Ctx(ctx).TX(tx).Where(pic.Id, req.Id).
+			Where(pic.IsDelete, 1).Data(do.Picture{
+			IsDelete:   0,
+			UpdateTime: gtime.Now(),
+		}).Update()
+		if updateErr != nil {
+			g.Log().Errorf(ctx, "恢复图片失败 id=%d: %v", req.Id, updateErr)
+			return gerror.New("恢复图片失败")
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return gerror.New("回收站中不存在此图片")
+		}
+		if picture.SpaceId > 0 {
+			return service.Space().ReserveQuota(ctx, tx, picture.SpaceId, picture.PicSize, 1)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.PictureRestoreRes{
+		Success: true,
+	}, nil
+}
+
+// PurgeRecycleBin 彻底删除超过保留期的回收站图片（数据库记录与存储对象）
+func (s *sPicture) PurgeRecycleBin(ctx context.Context) {
+	pic := dao.Picture.Columns()
+	deadline := gtime.Now().Add(-s.recycleRetention(ctx))
+	purged := 0
+	for {
+		var pictures []entity.Picture
+		err := dao.Picture.Ctx(ctx).Where(pic.IsDelete, 1).
+			WhereLT(pic.UpdateTime, deadline).
+			OrderAsc(pic.Id).Limit(purgeBatchSize).Scan(&pictures)
+		if err != nil {
+			g.Log().Errorf(ctx, "查询待清理的回收站图片失败: %v", err)
+			return
+		}
+		if len(pictures) == 0 {
+			break
+		}
+
+		for i := range pictures {
+			// 只删除仍处于回收站中的记录，避免与恢复操作并发时误删
+			result, err := dao.Picture.Ctx(ctx).Where(pic.Id, pictures[i].Id).
+				Where(pic.IsDelete, 1).Delete()
+			if err != nil {
+				g.Log().Errorf(ctx, "彻底删除图片失败 id=%d: %v", pictures[i].Id, err)
+				return
+			}
+			if affected, _ := result.RowsAffected(); affected == 0 {
+				continue
+			}
+			if _, err = dao.PictureExif.Ctx(ctx).Where(dao.PictureExif.Columns().PictureId, pictures[i].Id).Delete(); err != nil {
+				g.Log().Warningf(ctx, "删除图片EXIF失败 id=%d: %v", pictures[i].Id, err)
+			}
+			if _, err = dao.PictureSuggestion.Ctx(ctx).Where(dao.PictureSuggestion.Columns().PictureId, pictures[i].Id).Delete(); err != nil {
+				g.Log().Warningf(ctx, "删除智能标注建议失败 id=%d: %v", pictures[i].Id, err)
+			}
+			// 连同历史版本的文件一并删除，仍被其他图片引用的文件保留
+			s.deletePictureFiles(ctx, &pictures[i])
+			purged++
+		}
+
+		if len(pictures) < purgeBatchSize {
+			break
+		}
+	}
+	if purged > 0 {
+		g.Log().Infof(ctx, "回收站清理完成，彻底删除 %d 张图片", purged)
+	}
+}
+
+// recycleRetention 回收站保留期，默认30天
+func (s *sPicture) recycleRetention(ctx context.Context) time.Duration {
+	retention := g.Cfg().MustGet(ctx, consts.PictureRecycleRetention, "720h").Duration()
+	if retention <= 0 {
+		retention = 720 * time.Hour
+	}
+	return retention
+}
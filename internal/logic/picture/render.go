@@ -0,0 +1,247 @@
+package picture
+
+import (
+	"bytes"
+	v1 "cloud/api/user/v1"
+	"cloud/internal/service"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/disintegration/imaging"
+	"github.com/gogf/gf/v2/database/gredis"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+const (
+	renditionSetKey          = "picture:render:" // 原图已生成的转换结果key（有序集合，分值为最近访问时间），删除原图时一并删除
+	defaultRenderQuality     = 80                // 默认JPEG质量
+	defaultRenderMaxPerImage = 16                // 每张原图默认最多缓存的转换结果数
+)
+
+var (
+	// renderSizes 允许输出的宽高，请求的宽高向上取整到其中之一，避免任意参数组合产生大量缓存
+	renderSizes = []int{64, 128, 256, 320, 480, 640, 800, 1024, 1280, 1600, 1920, 2560, 3200, 4096}
+	// renderQualities 允许的JPEG质量，请求的质量取最接近的一档
+	renderQualities = []int{50, 65, 80, 95}
+)
+
+// renderOptions 图片转换参数（已规范化）
+type renderOptions struct {
+	width   int
+	height  int
+	fit     string
+	format  string
+	quality int
+}
+
+// Render 按参数实时转换图片（缩放、裁剪、格式、质量），权限与获取图片详情一致，转换结果缓存到对象存储
+func (s *sPicture) Render(ctx context.Context, req *v1.PictureRenderReq) (res *v1.PictureRenderRes, err error) {
+	picture, err := s.viewablePicture(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	originalKey := service.Bucket().GetFileKey(picture.Url)
+	if originalKey == "" {
+		return nil, gerror.New("图片文件不存在")
+	}
+
+	opts := newRenderOptions(req, picture.PicFormat)
+	key := renditionKey(originalKey, opts)
+	res = &v1.PictureRenderRes{
+		ContentType: "image/" + opts.format,
+		Private:     picture.SpaceId > 0 || picture.ReviewStatus != 1,
+	}
+	maxSize := pictureMaxSize(ctx)
+
+	// 优先使用已缓存的转换结果，并刷新最近访问时间
+	setKey := renditionSetKey + originalKey
+	if score, _ := g.Redis().ZScore(ctx, setKey, key); score > 0 {
+		if res.Data, err = service.Bucket().ReadObject(ctx, key, maxSize); err == nil {
+			_, _ = g.Redis().ZAdd(ctx, setKey, &gredis.ZAddOption{XX: true}, gredis.ZAddMember{Score: float64(time.Now().Unix()), Member: key})
+			return res, nil
+		}
+	}
+
+	data, err := service.Bucket().ReadObject(ctx, originalKey, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := s.decodeImage(ctx, data)
+	if err != nil {
+		return nil, gerror.New("图片解码失败")
+	}
+	if res.Data, err = renderImage(img, opts); err != nil {
+		g.Log().Errorf(ctx, "图片转换失败 id=%d: %v", picture.Id, err)
+		return nil, gerror.New("图片转换失败")
+	}
+
+	// 缓存失败不影响本次返回
+	if err = service.Bucket().PutObject(ctx, key, res.Data, res.ContentType); err != nil {
+		g.Log().Warningf(ctx, "缓存转换结果失败 key=%s: %v", key, err)
+		return res, nil
+	}
+	if _, err = g.Redis().ZAdd(ctx, setKey, nil, gredis.ZAddMember{Score: float64(time.Now().Unix()), Member: key}); err != nil {
+		// 未记录的结果无法随原图删除，直接删除
+		g.Log().Warningf(ctx, "记录转换结果失败 key=%s: %v", key, err)
+		s.deleteObjects(ctx, key)
+		return res, nil
+	}
+	g.Log().Infof(ctx, "生成图片转换结果 id=%d，key: %s", picture.Id, key)
+	s.evictRenditions(ctx, originalKey)
+	return res, nil
+}
+
+// evictRenditions 原图的转换结果超出上限时，删除最久未访问的结果
+func (s *sPicture) evictRenditions(ctx context.Context, originalKey string) {
+	limit := int64(g.Cfg().MustGet(ctx, "picture.render.maxPerImage", defaultRenderMaxPerImage).Int())
+	setKey := renditionSetKey + originalKey
+	count, err := g.Redis().ZCard(ctx, setKey)
+	if err != nil || limit <= 0 || count <= limit {
+		return
+	}
+	values, err := g.Redis().ZRange(ctx, setKey, 0, count-limit-1)
+	if err != nil {
+		g.Log().Warningf(ctx, "查询转换结果失败 key=%s: %v", originalKey, err)
+		return
+	}
+	keys := values.Strings()
+	if len(keys) == 0 {
+		return
+	}
+	members := make([]any, 0, len(keys))
+	for _, key := range keys {
+		members = append(members, key)
+	}
+	// 先移出集合再删除对象，删除失败的对象由bucket服务定时重试
+	if _, err = g.Redis().ZRem(ctx, setKey, members[0], members[1:]...); err != nil {
+		g.Log().Warningf(ctx, "移除转换结果失败 key=%s: %v", originalKey, err)
+		return
+	}
+	s.deleteObjects(ctx, keys...)
+}
+
+// renditionKeys 原图已生成的全部转换结果key
+func (s *sPicture) renditionKeys(ctx context.Context, originalKey string) []string {
+	values, err := g.Redis().ZRange(ctx, renditionSetKey+originalKey, 0, -1)
+	if err != nil {
+		g.Log().Warningf(ctx, "查询转换结果失败 key=%s: %v", originalKey, err)
+		return nil
+	}
+	return values.Strings()
+}
+
+// newRenderOptions 规范化转换参数：宽高向上取整到允许的尺寸，未指定格式时沿用原图格式（无法编码的格式输出jpeg），
+// 质量只对jpeg生效并取最接近的一档
+func newRenderOptions(req *v1.PictureRenderReq, picFormat string) renderOptions {
+	opts := renderOptions{
+		width:  snapRenderSize(req.Width),
+		height: snapRenderSize(req.Height),
+		fit:    req.Fit,
+		format: strings.ToLower(req.Format),
+	}
+	if opts.fit == "" || opts.width == 0 || opts.height == 0 {
+		// 只指定一边时按比例缩放，裁剪与拉伸没有意义
+		opts.fit = "contain"
+	}
+	if opts.format == "" {
+		opts.format = strings.ToLower(picFormat)
+	}
+	switch opts.format {
+	case "jpg":
+		opts.format = "jpeg"
+	case "jpeg", "png", "gif":
+	default:
+		opts.format = "jpeg"
+	}
+	if opts.format == "jpeg" {
+		opts.quality = defaultRenderQuality
+		if req.Quality > 0 {
+			opts.quality = snapRenderQuality(req.Quality)
+		}
+	}
+	return opts
+}
+
+// snapRenderSize 将宽高向上取整到允许的尺寸，0表示按比例补全保持不变
+func snapRenderSize(size int) int {
+	if size <= 0 {
+		return 0
+	}
+	for _, allowed := range renderSizes {
+		if size <= allowed {
+			return allowed
+		}
+	}
+	return renderSizes[len(renderSizes)-1]
+}
+
+// snapRenderQuality 取最接近的允许质量，距离相同时取较高的一档
+func snapRenderQuality(quality int) int {
+	best := renderQualities[0]
+	for _, allowed := range renderQualities {
+		if abs(quality-allowed) <= abs(quality-best) {
+			best = allowed
+		}
+	}
+	return best
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// renditionKey 转换结果对象key：与原图同目录，文件名追加参数后缀，如 Public/a.png -> Public/a_r800x0_contain_q80.jpeg
+func renditionKey(originalKey string, opts renderOptions) string {
+	return fmt.Sprintf("%s_r%dx%d_%s_q%d.%s", strings.TrimSuffix(originalKey, path.Ext(originalKey)),
+		opts.width, opts.height, opts.fit, opts.quality, opts.format)
+}
+
+// renderImage 按参数缩放、裁剪并编码图片，宽高均为0时只转换格式与质量
+func renderImage(img image.Image, opts renderOptions) ([]byte, error) {
+	if opts.width > 0 || opts.height > 0 {
+		switch opts.fit {
+		case "cover":
+			img = imaging.Fill(img, opts.width, opts.height, imaging.Center, imaging.Lanczos)
+		case "fill":
+			img = imaging.Resize(img, opts.width, opts.height, imaging.Lanczos)
+		default:
+			// 只指定一边时按原图比例补全另一边，等比缩放不放大原图
+			width, height := opts.width, opts.height
+			bounds := img.Bounds()
+			if width == 0 {
+				width = max(1, height*bounds.Dx()/bounds.Dy())
+			}
+			if height == 0 {
+				height = max(1, width*bounds.Dy()/bounds.Dx())
+			}
+			img = imaging.Fit(img, width, height, imaging.Lanczos)
+		}
+	}
+
+	var buf bytes.Buffer
+	var err error
+	switch opts.format {
+	case "png":
+		err = imaging.Encode(&buf, img, imaging.PNG)
+	case "gif":
+		err = imaging.Encode(&buf, img, imaging.GIF)
+	default:
+		// JPEG不支持透明通道，透明区域铺白底
+		canvas := imaging.New(img.Bounds().Dx(), img.Bounds().Dy(), color.White)
+		canvas = imaging.Overlay(canvas, img, image.Pt(0, 0), 1.0)
+		err = imaging.Encode(&buf, canvas, imaging.JPEG, imaging.JPEGQuality(opts.quality))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,64 @@
+package picture
+
+import (
+	"bytes"
+	v1 "cloud/api/user/v1"
+	"image"
+	"testing"
+)
+
+func Test_renderImage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+	cases := []struct {
+		req  v1.PictureRenderReq
+		w, h int
+	}{
+		{v1.PictureRenderReq{Width: 128}, 128, 64},
+		{v1.PictureRenderReq{Height: 100, Fit: "cover"}, 256, 128}, // 高度向上取整到128
+		{v1.PictureRenderReq{Width: 128, Height: 128}, 128, 64},
+		{v1.PictureRenderReq{Width: 128, Height: 128, Fit: "cover"}, 128, 128},
+		{v1.PictureRenderReq{Width: 128, Height: 128, Fit: "fill"}, 128, 128},
+		{v1.PictureRenderReq{Width: 800, Height: 800}, 400, 200}, // 等比缩放不放大
+		{v1.PictureRenderReq{Format: "png"}, 400, 200},
+	}
+	for _, c := range cases {
+		data, err := renderImage(img, newRenderOptions(&c.req, "png"))
+		if err != nil {
+			t.Fatalf("%+v 转换失败: %v", c.req, err)
+		}
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil || cfg.Width != c.w || cfg.Height != c.h {
+			t.Errorf("%+v 尺寸错误: %dx%d, want %dx%d", c.req, cfg.Width, cfg.Height, c.w, c.h)
+		}
+	}
+}
+
+func Test_renditionKey(t *testing.T) {
+	opts := newRenderOptions(&v1.PictureRenderReq{Width: 800, Format: "jpg"}, "webp")
+	if got := renditionKey("space/1/a.webp", opts); got != "space/1/a_r800x0_contain_q80.jpeg" {
+		t.Errorf("key错误: %s", got)
+	}
+	// 非jpeg格式忽略质量参数
+	opts = newRenderOptions(&v1.PictureRenderReq{Quality: 50}, "png")
+	if got := renditionKey("Public/a.png", opts); got != "Public/a_r0x0_contain_q0.png" {
+		t.Errorf("key错误: %s", got)
+	}
+}
+
+func Test_newRenderOptions_snap(t *testing.T) {
+	cases := []struct {
+		req              v1.PictureRenderReq
+		width, height, q int
+	}{
+		{v1.PictureRenderReq{Width: 801, Height: 1}, 1024, 64, 80},
+		{v1.PictureRenderReq{Width: 4096, Quality: 73}, 4096, 0, 80},
+		{v1.PictureRenderReq{Width: 5000, Quality: 100}, 4096, 0, 95},
+		{v1.PictureRenderReq{Quality: 1}, 0, 0, 50},
+	}
+	for _, c := range cases {
+		opts := newRenderOptions(&c.req, "jpeg")
+		if opts.width != c.width || opts.height != c.height || opts.quality != c.q {
+			t.Errorf("%+v 取整错误: %dx%d q%d, want %dx%d q%d", c.req, opts.width, opts.height, opts.quality, c.width, c.height, c.q)
+		}
+	}
+}
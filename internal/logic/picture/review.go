@@ -2,16 +2,37 @@ package picture
 
 import (
 	v1 "cloud/api/user/v1"
+	"cloud/internal/consts"
 	"cloud/internal/dao"
 	"cloud/internal/model/do"
 	"cloud/internal/model/entity"
 	"cloud/internal/service"
 	"context"
+	"math"
+	"slices"
+	"time"
 
+	"github.com/gogf/gf/v2/database/gdb"
 	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/os/gtime"
 )
 
+// 审核记录来源
+const (
+	reviewSourceManual    = "manual"    // 管理员单张审核
+	reviewSourceBatch     = "batch"     // 管理员批量审核
+	reviewSourceAuto      = "auto"      // 自动审核
+	reviewSourceBlocklist = "blocklist" // 加入黑名单时拒绝
+	reviewSourceEdit      = "edit"      // 图片修改后重新进入待审核
+)
+
+const (
+	maxReviewBatch         = 100 // 批量审核一次最多处理的图片数
+	defaultReviewStatsDays = 7
+	maxReviewStatsDays     = 366
+)
+
 // Review 审核图片
 func (s *sPicture) Review(ctx context.Context, req *v1.PictureReviewReq) (res *v1.PictureReviewRes, err error) {
 	var picture *entity.Picture
@@ -32,21 +53,362 @@ func (s *sPicture) Review(ctx context.Context, req *v1.PictureReviewReq) (res *v
 		return nil, gerror.New("无权限审核图片，只有管理员可以审核")
 	}
 
-	// 4. 更新审核信息
+	// 4. 更新审核信息并记录审核日志，加锁读取原审核状态，防止并发审核时记录错误的原状态
+	err = g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		var locked *entity.Picture
+		if scanErr := dao.Picture.Ctx(ctx).TX(tx).Where(pic.Id, req.Id).
+			Where(pic.IsDelete, 0).LockUpdate().Scan(&locked); scanErr != nil || locked == nil {
+			return gerror.New("图片不存在")
+		}
+		return s.applyReview(ctx, tx, locked, req.ReviewStatus, req.ReviewMessage, user.Id, reviewSourceManual)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.PictureReviewRes{
+		Success: true,
+	}, nil
+}
+
+// ReviewByBatch 批量审核图片，所有图片使用相同的审核结论与审核信息，不存在或已删除的图片跳过
+func (s *sPicture) ReviewByBatch(ctx context.Context, req *v1.PictureReviewBatchReq) (res *v1.PictureReviewBatchRes, err error) {
+	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
+	if err != nil {
+		return nil, err
+	}
+	if user.UserRole != consts.Admin {
+		return nil, gerror.New("无权限审核图片，只有管理员可以审核")
+	}
+
+	ids := slices.Compact(slices.Sorted(slices.Values(req.IdList)))
+	if len(ids) > maxReviewBatch {
+		return nil, gerror.Newf("一次最多审核%d张图片", maxReviewBatch)
+	}
+
+	res = &v1.PictureReviewBatchRes{FailedIdList: []int64{}}
+	pic := dao.Picture.Columns()
+	err = g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		var pictures []entity.Picture
+		if scanErr := dao.Picture.Ctx(ctx).TX(tx).WhereIn(pic.Id, ids).
+			Where(pic.IsDelete, 0).LockUpdate().Scan(&pictures); scanErr != nil {
+			return gerror.New("查询图片失败")
+		}
+		found := make(map[int64]bool, len(pictures))
+		for i := range pictures {
+			if reviewErr := s.applyReview(ctx, tx, &pictures[i], req.ReviewStatus, req.ReviewMessage, user.Id, reviewSourceBatch); reviewErr != nil {
+				return reviewErr
+			}
+			found[pictures[i].Id] = true
+		}
+		for _, id := range ids {
+			if !found[id] {
+				res.FailedIdList = append(res.FailedIdList, id)
+			}
+		}
+		res.SuccessCount = len(pictures)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g.Log().Infof(ctx, "批量审核图片完成，审核人ID: %d，审核状态: %d，成功: %d，跳过: %d",
+		user.Id, req.ReviewStatus, res.SuccessCount, len(res.FailedIdList))
+	return res, nil
+}
+
+// applyReview 在事务中保存人工审核结论并记录审核日志
+func (s *sPicture) applyReview(ctx context.Context, tx gdb.TX, picture *entity.Picture, status int, message string, reviewerId int64, source string) error {
 	updateData := do.Picture{
-		ReviewStatus:  req.ReviewStatus,
-		ReviewMessage: req.ReviewMessage,
-		ReviewerId:    user.Id,
-		ReviewTime:    gtime.Now(),
-		UpdateTime:    gtime.Now(),
+		ReviewStatus:   status,
+		ReviewMessage:  message,
+		ReviewerId:     reviewerId,
+		ReviewTime:     gtime.Now(),
+		ModerationRule: "manual", // 人工审核结论，风险分保留自动审核的评估
+		UpdateTime:     gtime.Now(),
+	}
+	if _, err := dao.Picture.Ctx(ctx).TX(tx).Where(dao.Picture.Columns().Id, picture.Id).Data(updateData).Update(); err != nil {
+		g.Log().Errorf(ctx, "审核图片失败 id=%d: %v", picture.Id, err)
+		return gerror.New("审核操作失败")
+	}
+	return recordReviewLog(ctx, tx, do.PictureReviewLog{
+		PictureId:  picture.Id,
+		ReviewerId: reviewerId,
+		OldStatus:  picture.ReviewStatus,
+		NewStatus:  status,
+		Message:    message,
+		Source:     source,
+		RiskScore:  picture.RiskScore,
+	})
+}
+
+// recordReviewReset 图片修改后重新进入待审核时记录审核日志，保留被覆盖的审核结论；原本就是待审核的不记录
+func (s *sPicture) recordReviewReset(ctx context.Context, tx gdb.TX, picture *entity.Picture, userId int64) error {
+	if picture.ReviewStatus == consts.DefRwStatus {
+		return nil
+	}
+	return recordReviewLog(ctx, tx, do.PictureReviewLog{
+		PictureId:  picture.Id,
+		ReviewerId: userId,
+		OldStatus:  picture.ReviewStatus,
+		NewStatus:  consts.DefRwStatus,
+		Message:    "图片已修改，重新进入待审核",
+		Source:     reviewSourceEdit,
+		RiskScore:  picture.RiskScore,
+	})
+}
+
+// recordReviewLog 记录一条审核日志
+func recordReviewLog(ctx context.Context, tx gdb.TX, entry do.PictureReviewLog) error {
+	entry.CreateTime = gtime.Now()
+	if _, err := dao.PictureReviewLog.Ctx(ctx).TX(tx).Data(entry).Insert(); err != nil {
+		g.Log().Errorf(ctx, "记录审核日志失败 pictureId=%v: %v", entry.PictureId, err)
+		return gerror.New("记录审核日志失败")
+	}
+	return nil
+}
+
+// ListReviewLogs 分页查询审核记录，按时间倒序
+func (s *sPicture) ListReviewLogs(ctx context.Context, req *v1.PictureReviewLogListReq) (res *v1.PictureReviewLogListRes, err error) {
+	rl := dao.PictureReviewLog.Columns()
+	db := dao.PictureReviewLog.Ctx(ctx)
+	if req.PictureId > 0 {
+		db = db.Where(rl.PictureId, req.PictureId)
+	}
+	if req.ReviewerId != nil {
+		db = db.Where(rl.ReviewerId, *req.ReviewerId)
+	}
+	if req.Source != "" {
+		db = db.Where(rl.Source, req.Source)
+	}
+	if req.StartTime != "" {
+		db = db.WhereGTE(rl.CreateTime, req.StartTime)
+	}
+	if req.EndTime != "" {
+		db = db.WhereLTE(rl.CreateTime, endOfDay(req.EndTime))
 	}
 
-	_, err = dao.Picture.Ctx(ctx).Where(pic.Id, req.Id).Data(updateData).Update()
+	total, err := db.Count()
 	if err != nil {
-		return nil, gerror.New("审核操作失败")
+		return nil, gerror.New("查询失败")
+	}
+	var logs []entity.PictureReviewLog
+	if err = db.Page(req.Current, req.PageSize).OrderDesc(rl.Id).Scan(&logs); err != nil {
+		return nil, gerror.New("查询失败")
 	}
 
-	return &v1.PictureReviewRes{
-		Success: true,
+	reviewerIds := make([]int64, 0, len(logs))
+	for _, log := range logs {
+		reviewerIds = append(reviewerIds, log.ReviewerId)
+	}
+	names := s.reviewerNames(ctx, reviewerIds)
+	records := make([]v1.PictureReviewLogVO, 0, len(logs))
+	for _, log := range logs {
+		records = append(records, v1.PictureReviewLogVO{
+			Id:           log.Id,
+			PictureId:    log.PictureId,
+			ReviewerId:   log.ReviewerId,
+			ReviewerName: names[log.ReviewerId],
+			OldStatus:    log.OldStatus,
+			NewStatus:    log.NewStatus,
+			Message:      log.Message,
+			Source:       log.Source,
+			Rule:         log.Rule,
+			RiskScore:    log.RiskScore,
+			CreateTime:   log.CreateTime.Format(consts.Y_m_d_His),
+		})
+	}
+	return &v1.PictureReviewLogListRes{
+		Records: records,
+		PageInfo: &v1.PageInfo{
+			Current: req.Current,
+			Size:    req.PageSize,
+			Total:   total,
+			Pages:   (total + req.PageSize - 1) / req.PageSize,
+		},
+	}, nil
+}
+
+// ListPendingReview 分页查询待审核队列，等待最久的图片排在最前
+func (s *sPicture) ListPendingReview(ctx context.Context, req *v1.PictureReviewPendingReq) (res *v1.PictureReviewPendingRes, err error) {
+	pic := dao.Picture.Columns()
+	db := dao.Picture.Ctx(ctx).Where(pic.IsDelete, 0).Where(pic.ReviewStatus, consts.DefRwStatus)
+	if req.SpaceId != nil {
+		if *req.SpaceId == 0 {
+			db = s.wherePublic(db)
+		} else {
+			db = db.Where(pic.SpaceId, *req.SpaceId)
+		}
+	}
+	if req.UserId > 0 {
+		db = db.Where(pic.UserId, req.UserId)
+	}
+	if req.Category != "" {
+		db = db.Where(pic.Category, req.Category)
+	}
+	if req.MinRiskScore > 0 {
+		db = db.WhereGTE(pic.RiskScore, req.MinRiskScore)
+	}
+	if req.ModerationRule != "" {
+		db = db.Where(pic.ModerationRule, req.ModerationRule)
+	}
+
+	total, err := db.Count()
+	if err != nil {
+		return nil, gerror.New("查询失败")
+	}
+	// 图片上传或修改时更新编辑时间，即进入待审核的时间
+	var pictures []entity.Picture
+	if err = db.Page(req.Current, req.PageSize).OrderAsc(pic.EditTime).OrderAsc(pic.Id).Scan(&pictures); err != nil {
+		return nil, gerror.New("查询失败")
+	}
+
+	now := time.Now()
+	records := make([]v1.PictureReviewPendingVO, 0, len(pictures))
+	for i := range pictures {
+		record := v1.PictureReviewPendingVO{Picture: *s.entityToPicture(ctx, &pictures[i])}
+		s.signPictureUrls(ctx, record.SpaceId, &record.Url, &record.ThumbnailUrl)
+		if since := pendingSince(&pictures[i]); since != nil {
+			record.WaitMinutes = int64(now.Sub(since.Time).Minutes())
+		}
+		records = append(records, record)
+	}
+	return &v1.PictureReviewPendingRes{
+		Records: records,
+		PageInfo: &v1.PageInfo{
+			Current: req.Current,
+			Size:    req.PageSize,
+			Total:   total,
+			Pages:   (total + req.PageSize - 1) / req.PageSize,
+		},
 	}, nil
 }
+
+// pendingSince 图片进入待审核的时间
+func pendingSince(picture *entity.Picture) *gtime.Time {
+	if picture.EditTime != nil {
+		return picture.EditTime
+	}
+	return picture.CreateTime
+}
+
+// reviewerStats 审核人工作量统计行
+type reviewerStats struct {
+	ReviewerId     int64
+	Total          int
+	Approved       int
+	Rejected       int
+	LastReviewTime *gtime.Time
+}
+
+// ReviewStats 统计时间范围内各审核人的审核量，只统计通过与拒绝的结论，自动审核单独统计为审核人0
+func (s *sPicture) ReviewStats(ctx context.Context, req *v1.PictureReviewStatsReq) (res *v1.PictureReviewStatsRes, err error) {
+	start, end, err := reviewStatsRange(req.StartTime, req.EndTime, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	rl := dao.PictureReviewLog.Columns()
+	var rows []reviewerStats
+	err = dao.PictureReviewLog.Ctx(ctx).
+		Fields(rl.ReviewerId+" AS reviewerId", "COUNT(*) AS total",
+			"SUM("+rl.NewStatus+" = 1) AS approved", "SUM("+rl.NewStatus+" = 2) AS rejected",
+			"MAX("+rl.CreateTime+") AS lastReviewTime").
+		WhereIn(rl.Source, []string{reviewSourceManual, reviewSourceBatch, reviewSourceAuto, reviewSourceBlocklist}).
+		WhereIn(rl.NewStatus, []int{1, 2}).
+		WhereBetween(rl.CreateTime, start, end).
+		Group(rl.ReviewerId).OrderDesc("total").Scan(&rows)
+	if err != nil {
+		g.Log().Errorf(ctx, "统计审核量失败: %v", err)
+		return nil, gerror.New("统计审核量失败")
+	}
+
+	pending, err := dao.Picture.Ctx(ctx).Where(dao.Picture.Columns().IsDelete, 0).
+		Where(dao.Picture.Columns().ReviewStatus, consts.DefRwStatus).Count()
+	if err != nil {
+		return nil, gerror.New("统计待审核图片失败")
+	}
+
+	reviewerIds := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		reviewerIds = append(reviewerIds, row.ReviewerId)
+	}
+	names := s.reviewerNames(ctx, reviewerIds)
+	days := math.Max(1, math.Ceil(end.Sub(start).Hours()/24))
+	reviewers := make([]v1.ReviewerStatsVO, 0, len(rows))
+	for _, row := range rows {
+		vo := v1.ReviewerStatsVO{
+			ReviewerId:   row.ReviewerId,
+			ReviewerName: names[row.ReviewerId],
+			Total:        row.Total,
+			Approved:     row.Approved,
+			Rejected:     row.Rejected,
+			DailyAverage: math.Round(float64(row.Total)/days*100) / 100,
+		}
+		if row.LastReviewTime != nil {
+			vo.LastReviewTime = row.LastReviewTime.Format(consts.Y_m_d_His)
+		}
+		reviewers = append(reviewers, vo)
+	}
+	return &v1.PictureReviewStatsRes{
+		StartTime:    start.Format(consts.Y_m_d_His),
+		EndTime:      end.Format(consts.Y_m_d_His),
+		PendingCount: pending,
+		Reviewers:    reviewers,
+	}, nil
+}
+
+// reviewerNames 查询审核人昵称，自动审核（审核人0）显示为“自动审核”
+func (s *sPicture) reviewerNames(ctx context.Context, reviewerIds []int64) map[int64]string {
+	names := map[int64]string{0: "自动审核"}
+	ids := make([]int64, 0, len(reviewerIds))
+	for _, id := range reviewerIds {
+		if id > 0 && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return names
+	}
+	var users []entity.User
+	if err := dao.User.Ctx(ctx).WhereIn(dao.User.Columns().Id, ids).Scan(&users); err != nil {
+		g.Log().Warningf(ctx, "查询审核人信息失败: %v", err)
+		return names
+	}
+	for _, user := range users {
+		names[user.Id] = user.UserName
+	}
+	return names
+}
+
+// reviewStatsRange 解析统计时间范围：截止时间默认为当前时间，只传日期时包含当天；起始时间默认为截止前7天
+func reviewStatsRange(startTime, endTime string, now time.Time) (start, end *gtime.Time, err error) {
+	end = gtime.NewFromTime(now)
+	if endTime != "" {
+		if end, err = gtime.StrToTime(endOfDay(endTime)); err != nil {
+			return nil, nil, gerror.New("截止时间格式错误")
+		}
+	}
+	start = end.Add(-defaultReviewStatsDays * 24 * time.Hour)
+	if startTime != "" {
+		if start, err = gtime.StrToTime(startTime); err != nil {
+			return nil, nil, gerror.New("起始时间格式错误")
+		}
+	}
+	if !start.Before(end) {
+		return nil, nil, gerror.New("起始时间必须早于截止时间")
+	}
+	if end.Sub(start) > maxReviewStatsDays*24*time.Hour {
+		return nil, nil, gerror.Newf("统计范围不能超过%d天", maxReviewStatsDays)
+	}
+	return start, end, nil
+}
+
+// endOfDay 只传日期时补全为当天结束时间
+func endOfDay(value string) string {
+	if len(value) == len("2006-01-02") {
+		return value + " 23:59:59"
+	}
+	return value
+}
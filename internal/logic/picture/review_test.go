@@ -0,0 +1,44 @@
+package picture
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_reviewStatsRange(t *testing.T) {
+	now := time.Date(2024, 5, 20, 10, 30, 0, 0, time.Local)
+
+	start, end, err := reviewStatsRange("", "", now)
+	if err != nil || !end.Time.Equal(now) || end.Sub(start) != 7*24*time.Hour {
+		t.Errorf("默认应统计最近7天, start=%v end=%v err=%v", start, end, err)
+	}
+
+	start, end, err = reviewStatsRange("2024-05-01", "2024-05-10", now)
+	if err != nil {
+		t.Fatalf("解析时间范围失败: %v", err)
+	}
+	if got := start.Format("Y-m-d H:i:s"); got != "2024-05-01 00:00:00" {
+		t.Errorf("起始时间错误: %s", got)
+	}
+	if got := end.Format("Y-m-d H:i:s"); got != "2024-05-10 23:59:59" {
+		t.Errorf("只传日期时截止时间应包含当天: %s", got)
+	}
+
+	if _, _, err = reviewStatsRange("", "2024-05-10", now); err != nil {
+		t.Errorf("只传截止时间时起始时间应默认为截止前7天: %v", err)
+	}
+
+	errCases := []struct {
+		name       string
+		start, end string
+	}{
+		{"起始晚于截止", "2024-05-10", "2024-05-01"},
+		{"超过最大范围", "2022-01-01", "2024-05-01"},
+		{"格式错误", "not-a-date", ""},
+	}
+	for _, tc := range errCases {
+		if _, _, err = reviewStatsRange(tc.start, tc.end, now); err == nil {
+			t.Errorf("%s: 应返回错误", tc.name)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package picture
+
+import (
+	v1 "cloud/api/user/v1"
+	"cloud/internal/dao"
+	"cloud/internal/model/entity"
+	"cloud/internal/service"
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+const (
+	similarSearchLimit     = 12    // 以图搜图最多返回的图片数量
+	similarScanLimit       = 20000 // 以图搜图最多参与比较的图片数量（按上传时间取最新的图片）
+	duplicateScanLimit     = 5000  // 近似重复聚类最多参与比较的图片数量
+	defaultSimilarDistance = 10    // 默认判定为相似的最大汉明距离
+)
+
+// SearchByPicture 以图搜图（基于感知哈希在本地图库中查找相似图片）
+func (s *sPicture) SearchByPicture(ctx context.Context, req *v1.SearchPictureByPictureReq) (res []v1.SearchPictureByPictureRes, err error) {
+	// 获取当前登录用户
+	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
+	if err != nil {
+		return nil, gerror.New("用户未登录")
+	}
+
+	// 获取要搜索的图片信息
+	var picture *entity.Picture
+	pic := dao.Picture.Columns()
+	err = dao.Picture.Ctx(ctx).Where(pic.Id, req.PictureId).
+		Where(pic.IsDelete, 0).Scan(&picture)
+	if err != nil || picture == nil {
+		return nil, gerror.New("图片不存在")
+	}
+
+	// 需要有图片的查看权限
+	if !slices.Contains(s.getPicturePermissions(ctx, s.entityToPicture(ctx, picture), user), "picture:view") {
+		return nil, gerror.New("无权访问该图片")
+	}
+	sourceHash, ok := parsePicHash(picture.PicHash)
+	if !ok {
+		return nil, gerror.New("该图片暂不支持以图搜图")
+	}
+
+	// 只在与原图相同的可见范围内查找：空间图片搜索同一空间，公共图片搜索已过审或自己上传的公共图片
+	db := s.picHashQuery(ctx).WhereNot(pic.Id, picture.Id)
+	if picture.SpaceId > 0 {
+		db = db.Where(pic.SpaceId, picture.SpaceId)
+	} else {
+		db = s.wherePublic(db).Where("("+pic.ReviewStatus+" = 1 OR "+pic.UserId+" = ?)", user.Id)
+	}
+	var candidates []entity.Picture
+	if err = db.OrderDesc(pic.Id).Limit(similarScanLimit).Scan(&candidates); err != nil {
+		g.Log().Errorf(ctx, "查询相似图片候选失败: %v", err)
+		return nil, gerror.New("搜索失败")
+	}
+
+	// 计算汉明距离，保留阈值内最相似的图片
+	type match struct {
+		id       int64
+		distance int
+	}
+	maxDistance := s.similarMaxDistance(ctx)
+	matches := make([]match, 0)
+	for _, candidate := range candidates {
+		hash, ok := parsePicHash(candidate.PicHash)
+		if !ok {
+			continue
+		}
+		if d := hammingDistance(sourceHash, hash); d <= maxDistance {
+			matches = append(matches, match{id: candidate.Id, distance: d})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+	if len(matches) > similarSearchLimit {
+		matches = matches[:similarSearchLimit]
+	}
+	if len(matches) == 0 {
+		return []v1.SearchPictureByPictureRes{}, nil
+	}
+
+	ids := make([]int64, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m.id)
+	}
+	pictureMap, err := s.pictureMapByIds(ctx, ids)
+	if err != nil {
+		return nil, gerror.New("搜索失败")
+	}
+
+	res = make([]v1.SearchPictureByPictureRes, 0, len(matches))
+	for _, m := range matches {
+		similar, ok := pictureMap[m.id]
+		if !ok {
+			continue
+		}
+		vo := s.entityToVO(ctx, similar)
+		res = append(res, v1.SearchPictureByPictureRes{
+			PictureVO:  vo,
+			FromUrl:    vo.Url,
+			ThumbUrl:   vo.ThumbnailUrl,
+			Similarity: 1 - float64(m.distance)/64,
+		})
+	}
+	g.Log().Infof(ctx, "以图搜图完成 pictureId=%d，找到 %d 张相似图片", req.PictureId, len(res))
+	return res, nil
+}
+
+// DuplicateClusters 近似重复图片聚类报告（管理员）
+func (s *sPicture) DuplicateClusters(ctx context.Context, req *v1.PictureDuplicateClusterReq) (res *v1.PictureDuplicateClusterRes, err error) {
+	pic := dao.Picture.Columns()
+	db := s.picHashQuery(ctx)
+	if req.SpaceId != nil {
+		if *req.SpaceId > 0 {
+			db = db.Where(pic.SpaceId, *req.SpaceId)
+		} else {
+			db = s.wherePublic(db)
+		}
+	}
+	var pictures []entity.Picture
+	if err = db.OrderDesc(pic.Id).Limit(duplicateScanLimit).Scan(&pictures); err != nil {
+		g.Log().Errorf(ctx, "查询图片感知哈希失败: %v", err)
+		return nil, gerror.New("查询失败")
+	}
+
+	maxDistance := req.MaxDistance
+	if maxDistance <= 0 {
+		maxDistance = s.similarMaxDistance(ctx)
+	}
+
+	// 两两比较，距离在阈值内的图片用并查集合并为同一簇
+	hashes := make([]uint64, 0, len(pictures))
+	ids := make([]int64, 0, len(pictures))
+	for _, p := range pictures {
+		if hash, ok := parsePicHash(p.PicHash); ok {
+			hashes = append(hashes, hash)
+			ids = append(ids, p.Id)
+		}
+	}
+	parent := make([]int, len(hashes))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range hashes {
+		for j := i + 1; j < len(hashes); j++ {
+			if hammingDistance(hashes[i], hashes[j]) <= maxDistance {
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+
+	groups := make(map[int][]int64)
+	for i := range hashes {
+		root := find(i)
+		groups[root] = append(groups[root], ids[i])
+	}
+	memberIds := make([]int64, 0)
+	clusterIds := make([][]int64, 0)
+	for _, members := range groups {
+		if len(members) < 2 {
+			continue
+		}
+		clusterIds = append(clusterIds, members)
+		memberIds = append(memberIds, members...)
+	}
+	sort.Slice(clusterIds, func(i, j int) bool {
+		if len(clusterIds[i]) != len(clusterIds[j]) {
+			return len(clusterIds[i]) > len(clusterIds[j])
+		}
+		return clusterIds[i][0] > clusterIds[j][0]
+	})
+
+	res = &v1.PictureDuplicateClusterRes{
+		Clusters: make([]v1.PictureDuplicateCluster, 0, len(clusterIds)),
+		Scanned:  len(hashes),
+	}
+	if len(memberIds) == 0 {
+		return res, nil
+	}
+	pictureMap, err := s.pictureMapByIds(ctx, memberIds)
+	if err != nil {
+		return nil, gerror.New("查询失败")
+	}
+	for _, members := range clusterIds {
+		cluster := v1.PictureDuplicateCluster{
+			Pictures: make([]v1.PictureVO, 0, len(members)),
+		}
+		for _, id := range members {
+			if p, ok := pictureMap[id]; ok {
+				cluster.Pictures = append(cluster.Pictures, *s.entityToVO(ctx, p))
+			}
+		}
+		cluster.Size = len(cluster.Pictures)
+		res.Clusters = append(res.Clusters, cluster)
+	}
+	return res, nil
+}
+
+// picHashQuery 查询已计算感知哈希的未删除图片（只取比较所需字段）
+func (s *sPicture) picHashQuery(ctx context.Context) *gdb.Model {
+	pic := dao.Picture.Columns()
+	return dao.Picture.Ctx(ctx).Fields(pic.Id, pic.PicHash).
+		Where(pic.IsDelete, 0).
+		WhereNotNull(pic.PicHash).
+		WhereNot(pic.PicHash, "")
+}
+
+// wherePublic 公共图库条件（spaceId 为空或为0）
+func (s *sPicture) wherePublic(db *gdb.Model) *gdb.Model {
+	pic := dao.Picture.Columns()
+	return db.Where("(" + pic.SpaceId + " IS NULL OR " + pic.SpaceId + " = 0)")
+}
+
+// pictureMapByIds 按ID批量查询图片
+func (s *sPicture) pictureMapByIds(ctx context.Context, ids []int64) (map[int64]*entity.Picture, error) {
+	var pictures []entity.Picture
+	err := dao.Picture.Ctx(ctx).WhereIn(dao.Picture.Columns().Id, ids).Scan(&pictures)
+	if err != nil {
+		g.Log().Errorf(ctx, "批量查询图片失败: %v", err)
+		return nil, err
+	}
+	pictureMap := make(map[int64]*entity.Picture, len(pictures))
+	for i := range pictures {
+		pictureMap[pictures[i].Id] = &pictures[i]
+	}
+	return pictureMap, nil
+}
+
+// similarMaxDistance 判定为相似图片的最大汉明距离
+func (s *sPicture) similarMaxDistance(ctx context.Context) int {
+	distance := g.Cfg().MustGet(ctx, "picture.similarity.maxDistance", defaultSimilarDistance).Int()
+	if distance <= 0 {
+		distance = defaultSimilarDistance
+	}
+	return distance
+}
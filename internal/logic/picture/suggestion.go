@@ -0,0 +1,350 @@
+package picture
+
+import (
+	v1 "cloud/api/user/v1"
+	"cloud/internal/consts"
+	"cloud/internal/dao"
+	"cloud/internal/model/do"
+	"cloud/internal/model/entity"
+	"cloud/internal/service"
+	"context"
+	"slices"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+// 智能标注建议状态
+const (
+	suggestionPending   = 0
+	suggestionAccepted  = 1
+	suggestionDismissed = 2
+)
+
+const maxSuggestionGenerate = 20 // 单次手动生成建议的最大图片数
+
+// 可采纳的字段
+const (
+	suggestionFieldTags         = "tags"
+	suggestionFieldCategory     = "category"
+	suggestionFieldIntroduction = "introduction"
+)
+
+// suggestAsync 上传完成后在后台生成智能标注建议，生成失败不影响上传结果
+func (s *sPicture) suggestAsync(ctx context.Context, pictureId int64) {
+	if !g.Cfg().MustGet(ctx, "picture.suggestion.enabled", true).Bool() {
+		return
+	}
+	s.runBackground(ctx, "生成智能标注建议", pictureId, func(ctx context.Context, picture *entity.Picture) error {
+		_, err := s.generateSuggestion(ctx, picture)
+		return err
+	})
+}
+
+// generateSuggestion 为图片生成智能标注建议并保存，每张图片只保留最新一条建议（重新生成后恢复为待处理）
+// 远程服务调用失败时回退到本地推断
+func (s *sPicture) generateSuggestion(ctx context.Context, picture *entity.Picture) (*entity.PictureSuggestion, error) {
+	req := &visionRequest{
+		ImageUrl: service.Bucket().SignedUrl(ctx, picture.Url),
+		Name:     picture.Name,
+		Width:    picture.PicWidth,
+		Height:   picture.PicHeight,
+		Format:   picture.PicFormat,
+		Color:    picture.PicColor,
+	}
+	var exif *entity.PictureExif
+	if err := dao.PictureExif.Ctx(ctx).Where(dao.PictureExif.Columns().PictureId, picture.Id).Scan(&exif); err == nil && exif != nil {
+		req.CameraMake, req.CameraModel = exif.Make, exif.Model
+	}
+
+	var suggestion *visionSuggestion
+	provider, err := newVisionProvider(ctx)
+	if err == nil {
+		suggestion, err = provider.Suggest(ctx, req)
+	}
+	if err != nil {
+		g.Log().Warningf(ctx, "智能标注服务调用失败，使用本地推断 pictureId=%d: %v", picture.Id, err)
+		provider, suggestion = heuristicVision{}, heuristicSuggest(req)
+	}
+	tagsJson, _ := gjson.Encode(suggestion.Tags)
+
+	ps := dao.PictureSuggestion.Columns()
+	data := do.PictureSuggestion{
+		PictureId:    picture.Id,
+		UserId:       picture.UserId,
+		SpaceId:      picture.SpaceId,
+		Tags:         string(tagsJson),
+		Category:     suggestion.Category,
+		Introduction: suggestion.Introduction,
+		Provider:     provider.Name(),
+		Status:       suggestionPending,
+		CreateTime:   gtime.Now(),
+		UpdateTime:   gtime.Now(),
+	}
+	// 每张图片只保留一条建议（uk_pictureId），已存在时覆盖，上传后的后台生成与手动重新生成并发时不会冲突
+	_, err = dao.PictureSuggestion.Ctx(ctx).Data(data).
+		OnDuplicate(ps.UserId, ps.SpaceId, ps.Tags, ps.Category, ps.Introduction, ps.Provider, ps.Status, ps.UpdateTime).
+		Save()
+	if err != nil {
+		g.Log().Errorf(ctx, "保存智能标注建议失败 pictureId=%d: %v", picture.Id, err)
+		return nil, gerror.New("保存智能标注建议失败")
+	}
+
+	var saved *entity.PictureSuggestion
+	if err = dao.PictureSuggestion.Ctx(ctx).Where(ps.PictureId, picture.Id).Scan(&saved); err != nil || saved == nil {
+		return nil, gerror.New("查询智能标注建议失败")
+	}
+	return saved, nil
+}
+
+// ListSuggestions 分页查询自己图片的智能标注建议
+func (s *sPicture) ListSuggestions(ctx context.Context, req *v1.PictureSuggestionListReq) (res *v1.PictureSuggestionListRes, err error) {
+	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
+	if err != nil {
+		return nil, err
+	}
+
+	ps := dao.PictureSuggestion.Columns()
+	db := dao.PictureSuggestion.Ctx(ctx).Where(ps.UserId, user.Id).Where(ps.Status, req.Status)
+	if req.SpaceId > 0 {
+		db = db.Where(ps.SpaceId, req.SpaceId)
+	}
+	total, err := db.Count()
+	if err != nil {
+		return nil, gerror.New("查询失败")
+	}
+	var suggestions []entity.PictureSuggestion
+	if err = db.Page(req.Current, req.PageSize).OrderDesc(ps.UpdateTime).Scan(&suggestions); err != nil {
+		return nil, gerror.New("查询失败")
+	}
+
+	return &v1.PictureSuggestionListRes{
+		Records: s.suggestionsToVO(ctx, suggestions),
+		PageInfo: &v1.PageInfo{
+			Current: req.Current,
+			Size:    req.PageSize,
+			Total:   total,
+			Pages:   (total + req.PageSize - 1) / req.PageSize,
+		},
+	}, nil
+}
+
+// GenerateSuggestions 为已有图片重新生成智能标注建议（图片创建者或管理员）
+func (s *sPicture) GenerateSuggestions(ctx context.Context, req *v1.PictureSuggestionGenerateReq) (res *v1.PictureSuggestionGenerateRes, err error) {
+	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
+	if err != nil {
+		return nil, err
+	}
+	if len(req.PictureIdList) > maxSuggestionGenerate {
+		return nil, gerror.Newf("单次最多为 %d 张图片生成建议", maxSuggestionGenerate)
+	}
+
+	var pictures []*entity.Picture
+	pic := dao.Picture.Columns()
+	if err = dao.Picture.Ctx(ctx).WhereIn(pic.Id, req.PictureIdList).
+		Where(pic.IsDelete, 0).Scan(&pictures); err != nil {
+		return nil, gerror.New("查询图片失败")
+	}
+	if len(pictures) == 0 {
+		return nil, gerror.New("未找到有效的图片")
+	}
+	for _, picture := range pictures {
+		if picture.UserId != user.Id && user.UserRole != consts.Admin {
+			return nil, gerror.Newf("无权限为图片 %d 生成建议", picture.Id)
+		}
+	}
+
+	suggestions := make([]entity.PictureSuggestion, 0, len(pictures))
+	for _, picture := range pictures {
+		suggestion, genErr := s.generateSuggestion(ctx, picture)
+		if genErr != nil {
+			return nil, genErr
+		}
+		suggestions = append(suggestions, *suggestion)
+	}
+	return &v1.PictureSuggestionGenerateRes{
+		Records: s.suggestionsToVO(ctx, suggestions),
+	}, nil
+}
+
+// AcceptSuggestions 批量采纳智能标注建议：更新图片信息（标签与已有标签合并）并记录版本，图片需重新审核
+func (s *sPicture) AcceptSuggestions(ctx context.Context, req *v1.PictureSuggestionAcceptReq) (res *v1.PictureSuggestionAcceptRes, err error) {
+	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
+	if err != nil {
+		return nil, err
+	}
+	fields := req.Fields
+	if len(fields) == 0 {
+		fields = []string{suggestionFieldTags, suggestionFieldCategory, suggestionFieldIntroduction}
+	}
+	for _, field := range fields {
+		if field != suggestionFieldTags && field != suggestionFieldCategory && field != suggestionFieldIntroduction {
+			return nil, gerror.New("采纳的字段只能是tags、category、introduction")
+		}
+	}
+
+	suggestions, err := s.ownedSuggestions(ctx, user, req.IdList)
+	if err != nil {
+		return nil, err
+	}
+
+	accepted := 0
+	pic := dao.Picture.Columns()
+	ps := dao.PictureSuggestion.Columns()
+	err = g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		for i := range suggestions {
+			suggestion := &suggestions[i]
+			var picture *entity.Picture
+			if scanErr := dao.Picture.Ctx(ctx).TX(tx).Where(pic.Id, suggestion.PictureId).
+				Where(pic.IsDelete, 0).Scan(&picture); scanErr != nil {
+				return gerror.New("查询图片失败")
+			}
+			// 图片已删除时跳过，建议保留待处理
+			if picture == nil {
+				continue
+			}
+
+			updateData := do.Picture{
+				EditTime:     gtime.Now(),
+				UpdateTime:   gtime.Now(),
+				ReviewStatus: consts.DefRwStatus,
+			}
+			if slices.Contains(fields, suggestionFieldTags) {
+				tags := mergeTags(parseTags(picture.Tags), parseTags(suggestion.Tags))
+				tagsJson, _ := gjson.New(tags).ToJson()
+				updateData.Tags = string(tagsJson)
+			}
+			if slices.Contains(fields, suggestionFieldCategory) && suggestion.Category != "" {
+				updateData.Category = suggestion.Category
+			}
+			if slices.Contains(fields, suggestionFieldIntroduction) && suggestion.Introduction != "" {
+				updateData.Introduction = suggestion.Introduction
+			}
+			if _, upErr := dao.Picture.Ctx(ctx).TX(tx).Where(pic.Id, picture.Id).Data(updateData).Update(); upErr != nil {
+				g.Log().Errorf(ctx, "采纳智能标注建议失败 pictureId=%d: %v", picture.Id, upErr)
+				return gerror.Newf("更新图片失败，ID: %d", picture.Id)
+			}
+			if logErr := s.recordReviewReset(ctx, tx, picture, user.Id); logErr != nil {
+				return logErr
+			}
+			if versionErr := s.recordVersion(ctx, tx, picture, versionActionSuggest, user.Id, ""); versionErr != nil {
+				return versionErr
+			}
+			if _, upErr := dao.PictureSuggestion.Ctx(ctx).TX(tx).Where(ps.Id, suggestion.Id).Data(do.PictureSuggestion{
+				Status:     suggestionAccepted,
+				UpdateTime: gtime.Now(),
+			}).Update(); upErr != nil {
+				return gerror.New("更新建议状态失败")
+			}
+			accepted++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 采纳后的信息重新自动审核
+	for _, suggestion := range suggestions {
+		s.pruneVersions(ctx, suggestion.PictureId)
+		s.moderateAsync(ctx, suggestion.PictureId)
+	}
+	return &v1.PictureSuggestionAcceptRes{
+		AcceptedCount: accepted,
+	}, nil
+}
+
+// DismissSuggestions 批量忽略智能标注建议
+func (s *sPicture) DismissSuggestions(ctx context.Context, req *v1.PictureSuggestionDismissReq) (res *v1.PictureSuggestionDismissRes, err error) {
+	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
+	if err != nil {
+		return nil, err
+	}
+	suggestions, err := s.ownedSuggestions(ctx, user, req.IdList)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		ids = append(ids, suggestion.Id)
+	}
+	ps := dao.PictureSuggestion.Columns()
+	if _, err = dao.PictureSuggestion.Ctx(ctx).WhereIn(ps.Id, ids).Data(do.PictureSuggestion{
+		Status:     suggestionDismissed,
+		UpdateTime: gtime.Now(),
+	}).Update(); err != nil {
+		return nil, gerror.New("更新建议状态失败")
+	}
+	return &v1.PictureSuggestionDismissRes{
+		Success: true,
+	}, nil
+}
+
+// ownedSuggestions 查询待处理的建议，只有图片创建者或管理员可以处理
+func (s *sPicture) ownedSuggestions(ctx context.Context, user *v1.GetLoginUserRes, ids []int64) ([]entity.PictureSuggestion, error) {
+	var suggestions []entity.PictureSuggestion
+	ps := dao.PictureSuggestion.Columns()
+	if err := dao.PictureSuggestion.Ctx(ctx).WhereIn(ps.Id, ids).
+		Where(ps.Status, suggestionPending).Scan(&suggestions); err != nil {
+		return nil, gerror.New("查询建议失败")
+	}
+	if len(suggestions) == 0 {
+		return nil, gerror.New("未找到待处理的建议")
+	}
+	for _, suggestion := range suggestions {
+		if suggestion.UserId != user.Id && user.UserRole != consts.Admin {
+			return nil, gerror.New("无权限处理此建议")
+		}
+	}
+	return suggestions, nil
+}
+
+// suggestionsToVO 转换为视图对象，附带图片当前信息便于对比
+func (s *sPicture) suggestionsToVO(ctx context.Context, suggestions []entity.PictureSuggestion) []v1.PictureSuggestionVO {
+	pictureIds := make([]int64, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		pictureIds = append(pictureIds, suggestion.PictureId)
+	}
+	pictureMap := make(map[int64]*entity.Picture)
+	if len(pictureIds) > 0 {
+		var pictures []*entity.Picture
+		pic := dao.Picture.Columns()
+		if err := dao.Picture.Ctx(ctx).WhereIn(pic.Id, pictureIds).Where(pic.IsDelete, 0).Scan(&pictures); err == nil {
+			for _, picture := range pictures {
+				pictureMap[picture.Id] = picture
+			}
+		}
+	}
+
+	records := make([]v1.PictureSuggestionVO, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		vo := v1.PictureSuggestionVO{
+			Id:           suggestion.Id,
+			PictureId:    suggestion.PictureId,
+			Tags:         parseTags(suggestion.Tags),
+			Category:     suggestion.Category,
+			Introduction: suggestion.Introduction,
+			Provider:     suggestion.Provider,
+			Status:       suggestion.Status,
+			CreateTime:   suggestion.CreateTime.Format(consts.Y_m_d_His),
+		}
+		if picture, ok := pictureMap[suggestion.PictureId]; ok {
+			vo.Picture = s.entityToVO(ctx, picture)
+		}
+		records = append(records, vo)
+	}
+	return records
+}
+
+// mergeTags 合并标签，保留已有标签的顺序并去重
+func mergeTags(current []string, suggested []string) []string {
+	merged := make([]string, 0, len(current)+len(suggested))
+	for _, tag := range slices.Concat(current, suggested) {
+		if !slices.Contains(merged, tag) {
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
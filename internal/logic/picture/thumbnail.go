@@ -0,0 +1,68 @@
+package picture
+
+import (
+	"bytes"
+	"cloud/internal/service"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/disintegration/imaging"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// defaultThumbnailSizes 默认缩略图尺寸（长边像素）
+var defaultThumbnailSizes = []int{256, 1024}
+
+// generateThumbnails 按配置尺寸生成缩略图并上传到原图旁边，返回列表页使用的缩略图地址（最小尺寸）与全部缩略图key
+// 图片本身小于缩略图尺寸时不放大，所有尺寸都跳过时返回空地址，由调用方回退到原图
+func (s *sPicture) generateThumbnails(ctx context.Context, img image.Image, originalKey string) (thumbnailUrl string, keys []string) {
+	quality := g.Cfg().MustGet(ctx, "picture.thumbnail.quality", 80).Int()
+	bounds := img.Bounds()
+	longSide := max(bounds.Dx(), bounds.Dy())
+
+	for _, size := range s.thumbnailSizes(ctx) {
+		if longSide <= size {
+			continue
+		}
+		// 等比缩放到长边为size，JPEG不支持透明通道，透明区域铺白底
+		thumb := imaging.Fit(img, size, size, imaging.Lanczos)
+		canvas := imaging.New(thumb.Bounds().Dx(), thumb.Bounds().Dy(), color.White)
+		canvas = imaging.Overlay(canvas, thumb, image.Pt(0, 0), 1.0)
+
+		var buf bytes.Buffer
+		if err := imaging.Encode(&buf, canvas, imaging.JPEG, imaging.JPEGQuality(quality)); err != nil {
+			g.Log().Warningf(ctx, "生成缩略图失败 size=%d: %v", size, err)
+			continue
+		}
+		key := thumbnailKey(originalKey, size)
+		if err := service.Bucket().PutObject(ctx, key, buf.Bytes(), "image/jpeg"); err != nil {
+			g.Log().Warningf(ctx, "上传缩略图失败 key=%s: %v", key, err)
+			continue
+		}
+		if thumbnailUrl == "" {
+			thumbnailUrl = service.Bucket().GetFileUrl(key)
+		}
+		keys = append(keys, key)
+	}
+	return thumbnailUrl, keys
+}
+
+// thumbnailSizes 读取缩略图尺寸配置，按从小到大排序
+func (s *sPicture) thumbnailSizes(ctx context.Context) []int {
+	sizes := g.Cfg().MustGet(ctx, "picture.thumbnail.sizes").Ints()
+	if len(sizes) == 0 {
+		sizes = append([]int(nil), defaultThumbnailSizes...)
+	}
+	sort.Ints(sizes)
+	return sizes
+}
+
+// thumbnailKey 缩略图对象key：与原图同目录，文件名追加尺寸后缀，如 Public/a.png -> Public/a_256.jpg
+func thumbnailKey(originalKey string, size int) string {
+	return fmt.Sprintf("%s_%d.jpg", strings.TrimSuffix(originalKey, path.Ext(originalKey)), size)
+}
@@ -7,10 +7,10 @@ import (
 	"cloud/internal/model/do"
 	"cloud/internal/service"
 	"context"
-
-	"fmt"
+	"path"
 
 	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/net/ghttp"
 	"github.com/gogf/gf/v2/os/gtime"
@@ -18,179 +18,150 @@ import (
 
 // Upload 上传图片
 func (s *sPicture) Upload(ctx context.Context, req *v1.PictureUploadReq, file *ghttp.UploadFile) (res *v1.PictureUploadRes, err error) {
-	// 解析图片信息
-	width, height, format, scale, parseErr := s.parseImageInfo(ctx, file)
-	if parseErr != nil {
-		g.Log().Warningf(ctx, "解析图片信息失败，使用默认值: %v", parseErr)
-		// 如果解析失败，使用默认值
-		width = 0
-		height = 0
-		scale = 0
-		format = s.getFormatFromFilename(file.Filename)
-	}
-
-	// 提取图片主色调
-	picColor := "#000000" // 默认黑色
-	if parseErr == nil {
-		// 只有图片解析成功时才提取主色调
-		extractedColor, colorErr := s.extractDominantColor(ctx, file)
-		if colorErr != nil {
-			g.Log().Warningf(ctx, "提取图片主色调失败，使用默认值: %v", colorErr)
-		} else {
-			picColor = extractedColor
-		}
-	}
-
-	// 调用bucket服务上传文件
-	bucketReq := &v1.BucketUploadReq{
-		File: file,
-	}
-	bucketRes, err := service.Bucket().Upload(ctx, bucketReq)
+	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
 	if err != nil {
-		g.Log().Errorf(ctx, "文件上传失败: %v", err)
 		return nil, err
 	}
 
-	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
+	// 读取文件内容（哈希、解码、EXIF解析共用）
+	data, err := s.readUploadFile(ctx, file)
 	if err != nil {
-		return nil, err
+		return nil, gerror.New("读取上传文件失败")
 	}
 
-	// 使用事务：插入图片 + 更新空间统计
-	var id int64
-	err = g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
-		// 1) 插入图片
-		resp, inErr := dao.Picture.Ctx(ctx).TX(tx).Data(do.Picture{
-			Url:          consts.BucketURL + bucketRes.FileAddress,
-			Name:         file.Filename,
-			Introduction: "",
-			Category:     "默认",
-			Tags:         "[]",
-			PicSize:      file.Size,
-			PicWidth:     width,
-			PicHeight:    height,
-			PicScale:     scale,
-			PicFormat:    format,
-			UserId:       user.Id,
-			SpaceId:      req.SpaceId,
-			ReviewStatus: consts.DefRwStatus,
-			ThumbnailUrl: consts.BucketURL + bucketRes.FileAddress,
-			PicColor:     picColor,
-		}).Insert()
-		if inErr != nil {
-			g.Log().Errorf(ctx, "保存图片信息失败: %v", inErr)
-			return inErr
-		}
-		lastID, idErr := resp.LastInsertId()
-		if idErr != nil {
-			return idErr
-		}
-		id = lastID
-
-		// 2) 累加空间统计
-		if req.SpaceId > 0 {
-			space := dao.Space.Columns()
-			_, upErr := dao.Space.Ctx(ctx).TX(tx).
-				Where(space.Id, req.SpaceId).
-				Data(g.Map{
-					space.TotalSize:  gdb.Raw(fmt.Sprintf("totalSize + %d", file.Size)),
-					space.TotalCount: gdb.Raw("totalCount + 1"),
-				}).
-				Update()
-			if upErr != nil {
-				g.Log().Errorf(ctx, "更新空间统计失败 spaceId=%d: %v", req.SpaceId, upErr)
-				return upErr
-			}
-		}
-		return nil
-	})
+	pictureVO, err := s.uploadData(ctx, user.Id, req.SpaceId, file.Filename, data, uploadOptions{})
 	if err != nil {
 		return nil, err
 	}
-
-	// 创建图片VO对象
-	pictureVO := &v1.PictureVO{
-		Id:           id,                                       // 数据库生成的ID
-		Url:          consts.BucketURL + bucketRes.FileAddress, // 使用实际上传后的URL
-		Name:         file.Filename,
-		Introduction: "默认",
-		Category:     "",
-		Tags:         []string{},
-		PicSize:      file.Size, // 使用实际文件大小
-		PicWidth:     width,     // 解析得到的图片宽度
-		PicHeight:    height,    // 解析得到的图片高度
-		PicScale:     scale,     // 计算得到的宽高比例
-		PicFormat:    format,    // 解析得到的图片格式
-		UserId:       user.Id,   // 从上下文获取当前用户ID
-		SpaceId:      req.SpaceId,
-		CreateTime:   gtime.Now().Format(consts.Y_m_d_His),
-		EditTime:     gtime.Now().Format(consts.Y_m_d_His),
-		UpdateTime:   gtime.Now().Format(consts.Y_m_d_His),
-		ThumbnailUrl: consts.BucketURL + bucketRes.FileAddress, // 暂时使用原图URL
-		PicColor:     picColor,                                 // 提取的主色调
-	}
-
 	return &v1.PictureUploadRes{
 		PictureVO: pictureVO,
 	}, nil
 }
 
-// UploadByUrl 通过URL上传图片
-func (s *sPicture) UploadByUrl(ctx context.Context, req *v1.PictureUploadByUrlReq) (res *v1.PictureUploadByUrlRes, err error) {
-	// 解析图片信息
-	width, height, format, scale, fileSize, parseErr := s.parseImageInfoFromURL(ctx, req.FileUrl)
-	if parseErr != nil {
-		g.Log().Warningf(ctx, "解析URL图片信息失败，使用默认值: %v", parseErr)
-		// 如果解析失败，使用默认值
-		width = 0
-		height = 0
-		scale = 0
-		fileSize = 0
-		format = s.getFormatFromFilename(req.FileUrl)
+// uploadOptions 图片入库选项，不同上传方式的差异均通过选项传入
+type uploadOptions struct {
+	StoredKey       string // 客户端已直传到存储的对象key，为空时由入库流程写入存储；该对象未被使用（重复图片、入库失败）时删除
+	SourceUrl       string // URL上传、AI生成结果的来源地址，用于推断文件扩展名
+	SourcePictureId int64  // AI生成结果的来源图片，普通上传为0
+}
+
+// uploadData 图片入库（普通上传、分片上传合并后、直传完成后、URL上传、保存AI生成结果共用）
+// 依次完成去重、解码与EXIF解析、摆正与去除元数据、写入存储、生成缩略图、入库并占用空间额度，最后触发后台审核与智能标注
+func (s *sPicture) uploadData(ctx context.Context, userId int64, spaceId int64, fileName string, data []byte, opts uploadOptions) (*v1.PictureVO, error) {
+	// 直传对象被本次入库使用后，入库失败时由 uploadedKeys 统一清理
+	storedUsed := false
+	defer func() {
+		if opts.StoredKey != "" && !storedUsed {
+			s.deleteObjects(ctx, opts.StoredKey)
+		}
+	}()
+
+	// 计算内容哈希（按原始内容），同一范围内已存在相同图片时复用
+	contentHash := s.contentHash(data)
+	duplicate := s.findDuplicate(ctx, contentHash, spaceId, userId)
+	if duplicate != nil && duplicate.UserId == userId {
+		// 同一用户重复上传同一张图片：直接返回已有图片
+		g.Log().Infof(ctx, "图片已存在，直接返回 id=%d", duplicate.Id)
+		return s.entityToVO(ctx, duplicate), nil
 	}
 
-	// 提取图片主色调
-	picColor := "#000000" // 默认黑色
-	if parseErr == nil {
-		// 只有图片解析成功时才提取主色调
-		extractedColor, colorErr := s.extractDominantColorFromURL(ctx, req.FileUrl)
+	// 解码图片（尺寸、主色调、缩略图共用一次解码结果）
+	var (
+		width, height int
+		scale         float64
+		picColor      = "#000000" // 默认黑色
+		picHash       string
+	)
+	img, format, decodeErr := s.decodeImage(ctx, data)
+	if format == "" {
+		// 文件头无法识别为图片；不信任文件名与客户端、远程服务声明的Content-Type
+		return nil, gerror.New("文件内容不是有效的图片")
+	}
+	exifInfo := parseExif(data)
+	// 按EXIF方向摆正图片，并按空间设置去除隐私元数据，得到实际写入存储的内容
+	img, data, normalizedFormat, err := s.normalizeImage(ctx, spaceId, data, img, format, exifInfo)
+	if err != nil {
+		return nil, err
+	}
+	converted := normalizedFormat != format
+	format = normalizedFormat
+	fileSize := int64(len(data))
+	if decodeErr != nil {
+		g.Log().Warningf(ctx, "解析图片信息失败，使用默认值: %v", decodeErr)
+	} else {
+		width, height, scale = s.imageSize(img)
+		picHash = formatPicHash(perceptualHash(img))
+		// 提取图片主色调
+		extractedColor, colorErr := s.extractDominantColorOptimized(img, ctx)
 		if colorErr != nil {
-			g.Log().Warningf(ctx, "提取URL图片主色调失败，使用默认值: %v", colorErr)
+			g.Log().Warningf(ctx, "提取图片主色调失败，使用默认值: %v", colorErr)
 		} else {
 			picColor = extractedColor
 		}
 	}
 
-	if req.FileName != "" {
-		req.FileName += service.Bucket().GetFileExtFromUrl(req.FileUrl)
+	// 上传前预检查空间额度，避免无效上传
+	if spaceId > 0 {
+		if err := service.Space().CheckQuota(ctx, spaceId, fileSize, 1); err != nil {
+			return nil, err
+		}
 	}
 
-	// 调用bucket服务上传URL文件
-	bucketRes, err := service.Bucket().UploadByUrl(ctx, &v1.BucketUploadByUrlReq{
-		FileUrl:  req.FileUrl,
-		FileName: req.FileName,
-		SpaceId:  req.SpaceId,
-	})
-	if err != nil {
-		g.Log().Errorf(ctx, "URL文件上传失败: %v", err)
-		return nil, err
+	// 来源地址的扩展名补到指定的文件名上；未指定文件名时按来源地址生成对象名
+	keyName := fileName
+	if opts.SourceUrl != "" {
+		if fileName != "" {
+			fileName += service.Bucket().GetFileExtFromUrl(opts.SourceUrl)
+			keyName = fileName
+		} else {
+			keyName = opts.SourceUrl
+		}
 	}
-
-	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
-	if err != nil {
-		return nil, err
+	// 重新编码为其他格式时，文件名与对象key使用新格式的扩展名
+	if converted {
+		if fileName != "" {
+			fileName = formatFileName(fileName, format)
+		}
+		keyName = formatFileName(path.Base(keyName), format)
 	}
 
-	// 使用bucket服务返回的文件名，如果没有则从URL提取
-	filename := bucketRes.FileName
-	if filename == "" {
-		filename = req.FileName
-	}
+	var (
+		fileUrl, thumbnailUrl string
+		uploadedKeys          []string // 本次新上传的对象，入库失败时清理
+	)
+	if duplicate != nil {
+		// 复用已有对象，不再重复上传
+		fileUrl, thumbnailUrl, fileSize = duplicate.Url, duplicate.ThumbnailUrl, duplicate.PicSize
+		if fileName == "" {
+			fileName = duplicate.Name
+		}
+	} else {
+		// 写入处理后的文件内容；直传的对象按识别出的格式重新写入，覆盖客户端直传时设置的Content-Type，转换格式后写入新的对象
+		objectKey := opts.StoredKey
+		if objectKey == "" || converted {
+			objectKey = service.Bucket().NewObjectKey(spaceId, keyName)
+		}
+		if uploadErr := service.Bucket().PutObject(ctx, objectKey, data, formatContentType(format, data)); uploadErr != nil {
+			g.Log().Errorf(ctx, "文件上传失败: %v", uploadErr)
+			return nil, uploadErr
+		}
+		fileUrl = service.Bucket().GetFileUrl(objectKey)
+		uploadedKeys = append(uploadedKeys, objectKey)
+		storedUsed = objectKey == opts.StoredKey
+
+		// 未指定文件名时使用生成的对象名
+		if fileName == "" {
+			fileName = path.Base(objectKey)
+		}
 
-	// 优先使用bucket服务返回的文件大小，如果为0则使用解析得到的大小
-	finalFileSize := bucketRes.FileSize
-	if finalFileSize == 0 {
-		finalFileSize = fileSize
+		// 生成缩略图，生成失败时列表页回退使用原图
+		thumbnailUrl = fileUrl
+		if decodeErr == nil {
+			if thumbUrl, keys := s.generateThumbnails(ctx, img, objectKey); thumbUrl != "" {
+				thumbnailUrl = thumbUrl
+				uploadedKeys = append(uploadedKeys, keys...)
+			}
+		}
 	}
 
 	// 使用事务：插入图片 + 更新空间统计
@@ -198,21 +169,24 @@ func (s *sPicture) UploadByUrl(ctx context.Context, req *v1.PictureUploadByUrlRe
 	err = g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
 		// 1) 插入图片
 		resp, inErr := dao.Picture.Ctx(ctx).TX(tx).Data(do.Picture{
-			Url:          consts.BucketURL + bucketRes.FileAddress,
-			Name:         filename,
-			Introduction: "",
-			Category:     "默认",
-			Tags:         "[]",
-			PicSize:      finalFileSize,
-			PicWidth:     width,
-			PicHeight:    height,
-			PicScale:     scale,
-			PicFormat:    format,
-			UserId:       user.Id,
-			SpaceId:      req.SpaceId,
-			ReviewStatus: consts.DefRwStatus,
-			ThumbnailUrl: consts.BucketURL + bucketRes.FileAddress,
-			PicColor:     picColor,
+			Url:             fileUrl,
+			Name:            fileName,
+			Introduction:    "",
+			Category:        "默认",
+			Tags:            "[]",
+			PicSize:         fileSize,
+			PicWidth:        width,
+			PicHeight:       height,
+			PicScale:        scale,
+			PicFormat:       format,
+			UserId:          userId,
+			SpaceId:         spaceId,
+			ReviewStatus:    consts.DefRwStatus,
+			ThumbnailUrl:    thumbnailUrl,
+			PicColor:        picColor,
+			ContentHash:     contentHash,
+			PicHash:         picHash,
+			SourcePictureId: opts.SourcePictureId,
 		}).Insert()
 		if inErr != nil {
 			g.Log().Errorf(ctx, "保存图片信息失败: %v", inErr)
@@ -224,48 +198,77 @@ func (s *sPicture) UploadByUrl(ctx context.Context, req *v1.PictureUploadByUrlRe
 		}
 		id = lastID
 
-		// 2) 累加空间统计
-		if req.SpaceId > 0 {
-			space := dao.Space.Columns()
-			_, upErr := dao.Space.Ctx(ctx).TX(tx).
-				Where(space.Id, req.SpaceId).
-				Data(g.Map{
-					space.TotalSize:  gdb.Raw(fmt.Sprintf("totalSize + %d", finalFileSize)),
-					space.TotalCount: gdb.Raw("totalCount + 1"),
-				}).
-				Update()
-			if upErr != nil {
-				g.Log().Errorf(ctx, "更新空间统计失败 spaceId=%d: %v", req.SpaceId, upErr)
-				return upErr
+		// 2) 保存EXIF信息
+		if exifErr := s.saveExif(ctx, tx, id, exifInfo); exifErr != nil {
+			return exifErr
+		}
+
+		// 3) 占用空间额度（超出容量或数量限制时整体回滚）
+		if spaceId > 0 {
+			if quotaErr := service.Space().ReserveQuota(ctx, tx, spaceId, fileSize, 1); quotaErr != nil {
+				return quotaErr
 			}
 		}
 		return nil
 	})
 	if err != nil {
+		s.deleteObjects(ctx, uploadedKeys...)
 		return nil, err
 	}
 
+	// 创建图片VO对象
 	pictureVO := &v1.PictureVO{
-		Id:           id,                                       // 数据库生成的ID
-		Url:          consts.BucketURL + bucketRes.FileAddress, // 使用实际上传后的URL
-		Name:         filename,
-		Introduction: "",
-		Category:     "默认",
-		Tags:         []string{},
-		PicSize:      finalFileSize, // 使用实际文件大小
-		PicWidth:     width,         // 解析得到的图片宽度
-		PicHeight:    height,        // 解析得到的图片高度
-		PicScale:     scale,         // 计算得到的宽高比例
-		PicFormat:    format,        // 解析得到的图片格式
-		UserId:       user.Id,       // 从上下文获取当前用户ID
-		SpaceId:      req.SpaceId,
-		CreateTime:   gtime.Now().Format(consts.Y_m_d_His),
-		EditTime:     gtime.Now().Format(consts.Y_m_d_His),
-		UpdateTime:   gtime.Now().Format(consts.Y_m_d_His),
-		ThumbnailUrl: consts.BucketURL + bucketRes.FileAddress, // 暂时使用原图URL
-		PicColor:     picColor,                                 // 提取的主色调
+		Id:              id,      // 数据库生成的ID
+		Url:             fileUrl, // 使用实际上传后的URL
+		Name:            fileName,
+		Introduction:    "",
+		Category:        "默认",
+		Tags:            []string{},
+		PicSize:         fileSize, // 使用实际文件大小
+		PicWidth:        width,    // 解析得到的图片宽度
+		PicHeight:       height,   // 解析得到的图片高度
+		PicScale:        scale,    // 计算得到的宽高比例
+		PicFormat:       format,   // 解析得到的图片格式
+		UserId:          userId,   // 从上下文获取当前用户ID
+		SpaceId:         spaceId,
+		CreateTime:      gtime.Now().Format(consts.Y_m_d_His),
+		EditTime:        gtime.Now().Format(consts.Y_m_d_His),
+		UpdateTime:      gtime.Now().Format(consts.Y_m_d_His),
+		ThumbnailUrl:    thumbnailUrl,
+		PicColor:        picColor, // 提取的主色调
+		SourcePictureId: opts.SourcePictureId,
+	}
+	if exifInfo != nil {
+		pictureVO.Exif = s.exifToVO(exifInfo)
+	}
+	s.signPictureUrls(ctx, spaceId, &pictureVO.Url, &pictureVO.ThumbnailUrl)
+
+	// 后台自动审核，并生成标签、分类、简介建议由图片所有者确认采纳
+	s.moderateAsync(ctx, id)
+	s.suggestAsync(ctx, id)
+
+	return pictureVO, nil
+}
+
+// UploadByUrl 通过URL上传图片
+func (s *sPicture) UploadByUrl(ctx context.Context, req *v1.PictureUploadByUrlReq) (res *v1.PictureUploadByUrlRes, err error) {
+	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
+	if err != nil {
+		return nil, err
 	}
 
+	// 只下载一次，下载内容供哈希、元数据解析、主色调提取与存储共用
+	data, _, err := service.Bucket().Fetch(ctx, req.FileUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	pictureVO, err := s.uploadData(ctx, user.Id, req.SpaceId, req.FileName, data, uploadOptions{
+		SourceUrl: req.FileUrl,
+	})
+	if err != nil {
+		return nil, err
+	}
 	return &v1.PictureUploadByUrlRes{
 		PictureVO: pictureVO,
 	}, nil
@@ -0,0 +1,450 @@
+package picture
+
+import (
+	v1 "cloud/api/user/v1"
+	"cloud/internal/consts"
+	"cloud/internal/dao"
+	"cloud/internal/model/do"
+	"cloud/internal/model/entity"
+	"cloud/internal/service"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+// 版本变更类型
+const (
+	versionActionCreate   = "create"    // 首次变更时补录的初始版本
+	versionActionEdit     = "edit"      // 编辑图片信息
+	versionActionUpdate   = "update"    // 更新图片信息（管理员）
+	versionActionBatch    = "batchEdit" // 批量编辑
+	versionActionFileEdit = "fileEdit"  // 协同编辑保存（替换图片文件）
+	versionActionRollback = "rollback"  // 回滚到历史版本
+	versionActionAI       = "ai"        // 保存AI扩图、AI编辑结果
+	versionActionSuggest  = "suggest"   // 采纳智能标注建议
+)
+
+// defaultVersionMaxCount 每张图片默认保留的版本数
+const defaultVersionMaxCount = 20
+
+// pictureSnapshot 图片版本快照：回滚时恢复的图片信息与文件
+type pictureSnapshot struct {
+	Name         string  `json:"name"`
+	Introduction string  `json:"introduction"`
+	Category     string  `json:"category"`
+	Tags         string  `json:"tags"`
+	Url          string  `json:"url"`
+	ThumbnailUrl string  `json:"thumbnailUrl"`
+	PicSize      int64   `json:"picSize"`
+	PicWidth     int     `json:"picWidth"`
+	PicHeight    int     `json:"picHeight"`
+	PicScale     float64 `json:"picScale"`
+	PicFormat    string  `json:"picFormat"`
+	PicColor     string  `json:"picColor"`
+	ContentHash  string  `json:"contentHash"`
+	PicHash      string  `json:"picHash"`
+}
+
+func snapshotOf(picture *entity.Picture) pictureSnapshot {
+	return pictureSnapshot{
+		Name:         picture.Name,
+		Introduction: picture.Introduction,
+		Category:     picture.Category,
+		Tags:         picture.Tags,
+		Url:          picture.Url,
+		ThumbnailUrl: picture.ThumbnailUrl,
+		PicSize:      picture.PicSize,
+		PicWidth:     picture.PicWidth,
+		PicHeight:    picture.PicHeight,
+		PicScale:     picture.PicScale,
+		PicFormat:    picture.PicFormat,
+		PicColor:     picture.PicColor,
+		ContentHash:  picture.ContentHash,
+		PicHash:      picture.PicHash,
+	}
+}
+
+// changedFields 比较两个快照，返回变更的字段；文件相关字段合并为 file
+func (a pictureSnapshot) changedFields(b pictureSnapshot) []string {
+	fields := make([]string, 0)
+	if a.Name != b.Name {
+		fields = append(fields, "name")
+	}
+	if a.Introduction != b.Introduction {
+		fields = append(fields, "introduction")
+	}
+	if a.Category != b.Category {
+		fields = append(fields, "category")
+	}
+	if a.Tags != b.Tags {
+		fields = append(fields, "tags")
+	}
+	if a.Url != b.Url {
+		fields = append(fields, "file")
+	}
+	return fields
+}
+
+// decodeSnapshot 解析版本快照
+func decodeSnapshot(version *entity.PictureVersion) (snapshot pictureSnapshot, err error) {
+	err = gjson.DecodeTo(version.Snapshot, &snapshot)
+	return
+}
+
+// recordVersion 在事务中记录图片变更后的新版本，before 为变更前的图片；没有实际变更时不记录
+// 图片首次记录版本时先补录变更前的状态作为初始版本，保证可以回滚到变更前；事务提交后调用方需调用 pruneVersions 清理超出保留数的旧版本
+func (s *sPicture) recordVersion(ctx context.Context, tx gdb.TX, before *entity.Picture, action string, userId int64, remark string) error {
+	var after *entity.Picture
+	pv := dao.PictureVersion.Columns()
+	if err := dao.Picture.Ctx(ctx).TX(tx).Where(dao.Picture.Columns().Id, before.Id).Scan(&after); err != nil || after == nil {
+		return gerror.New("查询图片失败")
+	}
+	prev, next := snapshotOf(before), snapshotOf(after)
+	changed := prev.changedFields(next)
+	if len(changed) == 0 {
+		return nil
+	}
+
+	// 图片行已在本事务中更新并加锁，同一图片的版本号不会并发分配
+	latest, err := dao.PictureVersion.Ctx(ctx).TX(tx).Where(pv.PictureId, before.Id).Max(pv.Version)
+	if err != nil {
+		g.Log().Errorf(ctx, "查询图片版本失败 id=%d: %v", before.Id, err)
+		return gerror.New("记录图片版本失败")
+	}
+	version := int(latest)
+	if version == 0 {
+		version++
+		if err = s.insertVersion(ctx, tx, before.Id, version, versionActionCreate, nil, prev, before.UserId, ""); err != nil {
+			return err
+		}
+	}
+	return s.insertVersion(ctx, tx, before.Id, version+1, action, changed, next, userId, remark)
+}
+
+func (s *sPicture) insertVersion(ctx context.Context, tx gdb.TX, pictureId int64, version int, action string, changed []string, snapshot pictureSnapshot, userId int64, remark string) error {
+	snapshotJson, err := gjson.Encode(snapshot)
+	if err != nil {
+		return err
+	}
+	changedJson, err := gjson.Encode(changed)
+	if err != nil {
+		return err
+	}
+	_, err = dao.PictureVersion.Ctx(ctx).TX(tx).Data(do.PictureVersion{
+		PictureId:     pictureId,
+		Version:       version,
+		Action:        action,
+		ChangedFields: string(changedJson),
+		Snapshot:      string(snapshotJson),
+		Url:           snapshot.Url,
+		Remark:        remark,
+		UserId:        userId,
+		CreateTime:    gtime.Now(),
+	}).Insert()
+	if err != nil {
+		g.Log().Errorf(ctx, "保存图片版本失败 pictureId=%d version=%d: %v", pictureId, version, err)
+		return gerror.New("记录图片版本失败")
+	}
+	return nil
+}
+
+// ListVersions 分页查询图片的版本历史（新版本在前），权限与获取图片详情一致
+func (s *sPicture) ListVersions(ctx context.Context, req *v1.PictureVersionListReq) (res *v1.PictureVersionListRes, err error) {
+	picture, err := s.viewablePicture(ctx, req.PictureId)
+	if err != nil {
+		return nil, err
+	}
+
+	pv := dao.PictureVersion.Columns()
+	db := dao.PictureVersion.Ctx(ctx).Where(pv.PictureId, picture.Id)
+	total, err := db.Count()
+	if err != nil {
+		return nil, gerror.New("查询失败")
+	}
+	var versions []entity.PictureVersion
+	if err = db.Page(req.Current, req.PageSize).OrderDesc(pv.Version).Scan(&versions); err != nil {
+		return nil, gerror.New("查询失败")
+	}
+
+	current := snapshotOf(picture)
+	userMap := make(map[int64]*v1.UserVO)
+	records := make([]v1.PictureVersionVO, 0, len(versions))
+	for i := range versions {
+		snapshot, decodeErr := decodeSnapshot(&versions[i])
+		if decodeErr != nil {
+			g.Log().Warningf(ctx, "解析图片版本快照失败 id=%d: %v", versions[i].Id, decodeErr)
+			continue
+		}
+		var changed []string
+		_ = gjson.DecodeTo(versions[i].ChangedFields, &changed)
+		userId := versions[i].UserId
+		if _, ok := userMap[userId]; !ok {
+			if userResp, userErr := service.User().GetUserById(ctx, &v1.GetUserByIdReq{Id: userId}); userErr == nil && userResp != nil {
+				userMap[userId] = &v1.UserVO{Id: userResp.Id, UserName: userResp.UserName, UserAvatar: userResp.UserAvatar}
+			} else {
+				userMap[userId] = nil
+			}
+		}
+		vo := v1.PictureVersionVO{
+			Id:            versions[i].Id,
+			PictureId:     versions[i].PictureId,
+			Version:       versions[i].Version,
+			Action:        versions[i].Action,
+			ChangedFields: changed,
+			Remark:        versions[i].Remark,
+			Current:       len(snapshot.changedFields(current)) == 0,
+			Name:          snapshot.Name,
+			Introduction:  snapshot.Introduction,
+			Category:      snapshot.Category,
+			Tags:          parseTags(snapshot.Tags),
+			Url:           snapshot.Url,
+			ThumbnailUrl:  snapshot.ThumbnailUrl,
+			PicSize:       snapshot.PicSize,
+			PicWidth:      snapshot.PicWidth,
+			PicHeight:     snapshot.PicHeight,
+			PicFormat:     snapshot.PicFormat,
+			UserId:        userId,
+			User:          userMap[userId],
+			CreateTime:    versions[i].CreateTime.Format(consts.Y_m_d_His),
+		}
+		s.signPictureUrls(ctx, picture.SpaceId, &vo.Url, &vo.ThumbnailUrl)
+		records = append(records, vo)
+	}
+
+	return &v1.PictureVersionListRes{
+		Records: records,
+		PageInfo: &v1.PageInfo{
+			Current: req.Current,
+			Size:    req.PageSize,
+			Total:   total,
+			Pages:   (total + req.PageSize - 1) / req.PageSize,
+		},
+	}, nil
+}
+
+// RollbackVersion 将图片恢复到指定的历史版本（信息与文件），按文件大小变化修正空间用量，回滚本身记录为新版本
+func (s *sPicture) RollbackVersion(ctx context.Context, req *v1.PictureVersionRollbackReq) (res *v1.PictureVersionRollbackRes, err error) {
+	user, err := service.User().GetLoginUser(ctx, &v1.GetLoginUserReq{})
+	if err != nil {
+		return nil, err
+	}
+	var picture *entity.Picture
+	pic := dao.Picture.Columns()
+	err = dao.Picture.Ctx(ctx).Where(pic.Id, req.PictureId).
+		Where(pic.IsDelete, 0).Scan(&picture)
+	if err != nil || picture == nil {
+		return nil, gerror.New("图片不存在")
+	}
+	// 与协同编辑保存的权限一致：能为图片产生新版本的空间成员也可以回滚
+	if !slices.Contains(s.getPicturePermissions(ctx, s.entityToPicture(ctx, picture), user), "picture:edit") {
+		return nil, gerror.New("无权限回滚此图片")
+	}
+
+	var version *entity.PictureVersion
+	pv := dao.PictureVersion.Columns()
+	err = dao.PictureVersion.Ctx(ctx).Where(pv.Id, req.VersionId).
+		Where(pv.PictureId, picture.Id).Scan(&version)
+	if err != nil || version == nil {
+		return nil, gerror.New("版本不存在")
+	}
+	target, err := decodeSnapshot(version)
+	if err != nil {
+		return nil, gerror.New("版本数据已损坏，无法回滚")
+	}
+	if len(snapshotOf(picture).changedFields(target)) == 0 {
+		return nil, gerror.New("图片已是该版本")
+	}
+
+	sizeDelta := target.PicSize - picture.PicSize
+	err = g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		// 以当前地址为条件更新，防止与其他编辑并发时覆盖
+		result, upErr := dao.Picture.Ctx(ctx).TX(tx).
+			Where(pic.Id, picture.Id).
+			Where(pic.Url, picture.Url).
+			Where(pic.IsDelete, 0).
+			Data(do.Picture{
+				Name:         target.Name,
+				Introduction: target.Introduction,
+				Category:     target.Category,
+				Tags:         target.Tags,
+				Url:          target.Url,
+				ThumbnailUrl: target.ThumbnailUrl,
+				PicSize:      target.PicSize,
+				PicWidth:     target.PicWidth,
+				PicHeight:    target.PicHeight,
+				PicScale:     target.PicScale,
+				PicFormat:    target.PicFormat,
+				PicColor:     target.PicColor,
+				ContentHash:  target.ContentHash,
+				PicHash:      target.PicHash,
+				ReviewStatus: consts.DefRwStatus,
+				EditTime:     gtime.Now(),
+				UpdateTime:   gtime.Now(),
+			}).Update()
+		if upErr != nil {
+			g.Log().Errorf(ctx, "回滚图片失败 id=%d: %v", picture.Id, upErr)
+			return gerror.New("回滚图片失败")
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return gerror.New("图片已被修改，请刷新后重试")
+		}
+
+		// 空间用量只统计图片当前文件的大小
+		if picture.SpaceId > 0 && sizeDelta > 0 {
+			if quotaErr := service.Space().ReserveQuota(ctx, tx, picture.SpaceId, sizeDelta, 0); quotaErr != nil {
+				return quotaErr
+			}
+		}
+		if picture.SpaceId > 0 && sizeDelta < 0 {
+			if quotaErr := service.Space().ReleaseQuota(ctx, tx, picture.SpaceId, -sizeDelta, 0); quotaErr != nil {
+				return quotaErr
+			}
+		}
+		if logErr := s.recordReviewReset(ctx, tx, picture, user.Id); logErr != nil {
+			return logErr
+		}
+		return s.recordVersion(ctx, tx, picture, versionActionRollback, user.Id, fmt.Sprintf("回滚到版本 %d", version.Version))
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.Log().Infof(ctx, "用户 %d 将图片 %d 回滚到版本 %d", user.Id, picture.Id, version.Version)
+	// 回滚后的文件与信息重新自动审核
+	s.moderateAsync(ctx, picture.Id)
+	s.pruneVersions(ctx, picture.Id)
+
+	var updated *entity.Picture
+	if err = dao.Picture.Ctx(ctx).Where(pic.Id, picture.Id).Scan(&updated); err != nil || updated == nil {
+		return nil, gerror.New("查询图片失败")
+	}
+	return &v1.PictureVersionRollbackRes{
+		PictureVO: s.entityToVO(ctx, updated),
+	}, nil
+}
+
+// deletePictureFiles 删除图片当前及各历史版本的存储对象（含缩略图与转换结果），仍被其他图片引用的文件保留，并清理版本记录
+func (s *sPicture) deletePictureFiles(ctx context.Context, picture *entity.Picture) {
+	files := []*entity.Picture{picture}
+	seen := map[string]bool{picture.Url: true}
+	var versions []entity.PictureVersion
+	pv := dao.PictureVersion.Columns()
+	if err := dao.PictureVersion.Ctx(ctx).Where(pv.PictureId, picture.Id).Scan(&versions); err != nil {
+		// 查询失败时保守处理，只删除当前文件，历史版本的文件与记录保留
+		g.Log().Errorf(ctx, "查询图片版本失败 id=%d: %v", picture.Id, err)
+		versions = nil
+	}
+	for i := range versions {
+		if seen[versions[i].Url] {
+			continue
+		}
+		seen[versions[i].Url] = true
+		snapshot, _ := decodeSnapshot(&versions[i])
+		files = append(files, &entity.Picture{Id: picture.Id, Url: versions[i].Url, ThumbnailUrl: snapshot.ThumbnailUrl})
+	}
+
+	for _, file := range files {
+		s.deleteUnsharedFile(ctx, file)
+	}
+	if len(versions) > 0 {
+		if _, err := dao.PictureVersion.Ctx(ctx).Where(pv.PictureId, picture.Id).Delete(); err != nil {
+			g.Log().Warningf(ctx, "删除图片版本失败 id=%d: %v", picture.Id, err)
+		}
+	}
+}
+
+// deleteUnsharedFile 删除图片文件的存储对象（含缩略图与转换结果），去重复用的对象仍被其他图片或版本引用时保留
+func (s *sPicture) deleteUnsharedFile(ctx context.Context, file *entity.Picture) {
+	if s.objectShared(ctx, file) {
+		return
+	}
+	s.deleteObjects(ctx, s.pictureObjectKeys(ctx, file)...)
+	_, _ = g.Redis().Del(ctx, renditionSetKey+service.Bucket().GetFileKey(file.Url))
+}
+
+// pruneVersions 记录新版本的事务提交后只保留最近的若干个版本：删除更早的版本记录，
+// 以及只被这些版本引用的旧文件，避免反复编辑使版本记录与保留的旧文件无限增长
+func (s *sPicture) pruneVersions(ctx context.Context, pictureId int64) {
+	keep := g.Cfg().MustGet(ctx, "picture.version.maxCount", defaultVersionMaxCount).Int()
+	if keep <= 0 {
+		return
+	}
+	var (
+		pruned []entity.PictureVersion
+		files  []entity.Picture
+	)
+	pic := dao.Picture.Columns()
+	pv := dao.PictureVersion.Columns()
+	err := g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		// 锁定图片行，与记录版本、回滚等变更互斥
+		var picture *entity.Picture
+		if err := dao.Picture.Ctx(ctx).TX(tx).Where(pic.Id, pictureId).LockUpdate().Scan(&picture); err != nil || picture == nil {
+			return gerror.New("查询图片失败")
+		}
+		var versions []entity.PictureVersion
+		if err := dao.PictureVersion.Ctx(ctx).TX(tx).Where(pv.PictureId, pictureId).Scan(&versions); err != nil {
+			return gerror.New("查询图片版本失败")
+		}
+		pruned, files = versionsToPrune(versions, keep, picture.Url)
+		if len(pruned) == 0 {
+			return nil
+		}
+		ids := make([]int64, 0, len(pruned))
+		for _, version := range pruned {
+			ids = append(ids, version.Id)
+		}
+		_, err := dao.PictureVersion.Ctx(ctx).TX(tx).WhereIn(pv.Id, ids).Delete()
+		return err
+	})
+	if err != nil {
+		g.Log().Warningf(ctx, "清理图片旧版本失败 id=%d: %v", pictureId, err)
+		return
+	}
+	// 版本记录删除后再删除文件，删除失败的对象由bucket服务定时重试
+	for i := range files {
+		s.deleteUnsharedFile(ctx, &files[i])
+	}
+	if len(pruned) > 0 {
+		g.Log().Infof(ctx, "清理图片 %d 的旧版本 %d 个，删除文件 %d 个", pictureId, len(pruned), len(files))
+	}
+}
+
+// versionsToPrune 按版本号从新到旧保留 keep 个版本，返回需要删除的版本，
+// 以及只被这些版本引用的文件（不是图片当前文件，也不被保留的版本引用）
+func versionsToPrune(versions []entity.PictureVersion, keep int, currentUrl string) ([]entity.PictureVersion, []entity.Picture) {
+	if len(versions) <= keep {
+		return nil, nil
+	}
+	sorted := slices.Clone(versions)
+	slices.SortFunc(sorted, func(a, b entity.PictureVersion) int {
+		return b.Version - a.Version
+	})
+	retained := map[string]bool{currentUrl: true}
+	for _, version := range sorted[:keep] {
+		retained[version.Url] = true
+	}
+	pruned := sorted[keep:]
+	var files []entity.Picture
+	index := make(map[string]int) // 文件地址 -> files 下标，多个版本引用同一文件时只删除一次
+	for i := range pruned {
+		url := pruned[i].Url
+		if retained[url] {
+			continue
+		}
+		snapshot, _ := decodeSnapshot(&pruned[i])
+		if j, ok := index[url]; ok {
+			if files[j].ThumbnailUrl == "" {
+				files[j].ThumbnailUrl = snapshot.ThumbnailUrl
+			}
+			continue
+		}
+		index[url] = len(files)
+		files = append(files, entity.Picture{Id: pruned[i].PictureId, Url: url, ThumbnailUrl: snapshot.ThumbnailUrl})
+	}
+	return pruned, files
+}
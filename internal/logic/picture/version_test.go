@@ -0,0 +1,46 @@
+package picture
+
+import (
+	"cloud/internal/model/entity"
+	"reflect"
+	"testing"
+)
+
+func Test_snapshotChangedFields(t *testing.T) {
+	before := snapshotOf(&entity.Picture{Name: "a", Tags: `["x"]`, Url: "/upload/a.jpg", PicSize: 100})
+	if fields := before.changedFields(before); len(fields) != 0 {
+		t.Errorf("相同快照不应有变更字段, got=%v", fields)
+	}
+
+	// 文件相关字段统一记为 file
+	after := snapshotOf(&entity.Picture{Name: "b", Tags: `["x"]`, Url: "/upload/b.jpg", PicSize: 200})
+	if fields := before.changedFields(after); !reflect.DeepEqual(fields, []string{"name", "file"}) {
+		t.Errorf("变更字段错误, got=%v", fields)
+	}
+}
+
+func Test_versionsToPrune(t *testing.T) {
+	versions := []entity.PictureVersion{
+		{Id: 1, PictureId: 9, Version: 1, Url: "/upload/a.jpg", Snapshot: `{"thumbnailUrl":"/upload/a_thumb.jpg"}`},
+		{Id: 2, PictureId: 9, Version: 2, Url: "/upload/a.jpg"},
+		{Id: 3, PictureId: 9, Version: 3, Url: "/upload/b.jpg"},
+		{Id: 5, PictureId: 9, Version: 5, Url: "/upload/d.jpg"},
+		{Id: 4, PictureId: 9, Version: 4, Url: "/upload/c.jpg"},
+	}
+	if pruned, files := versionsToPrune(versions, 5, "/upload/d.jpg"); pruned != nil || files != nil {
+		t.Errorf("未超出保留数不应清理, got=%v %v", pruned, files)
+	}
+
+	// 保留版本4、5；版本1-3被删除，b.jpg 是当前文件，只删除 a.jpg
+	pruned, files := versionsToPrune(versions, 2, "/upload/b.jpg")
+	ids := make([]int64, 0, len(pruned))
+	for _, version := range pruned {
+		ids = append(ids, version.Id)
+	}
+	if !reflect.DeepEqual(ids, []int64{3, 2, 1}) {
+		t.Errorf("应删除最早的版本, got=%v", ids)
+	}
+	if len(files) != 1 || files[0].Url != "/upload/a.jpg" || files[0].ThumbnailUrl != "/upload/a_thumb.jpg" || files[0].Id != 9 {
+		t.Errorf("只应删除不再被引用的文件, got=%+v", files)
+	}
+}
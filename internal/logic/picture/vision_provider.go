@@ -0,0 +1,76 @@
+package picture
+
+import (
+	"cloud/internal/consts"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+const (
+	maxSuggestionTags         = 5
+	maxSuggestionIntroduction = 100 // 建议简介的最大字数
+)
+
+// visionRequest 智能标注请求：图片访问地址与已解析的图片信息
+type visionRequest struct {
+	ImageUrl    string // 图片访问地址（私有空间为签名地址，供远程服务访问）
+	Name        string
+	Width       int
+	Height      int
+	Format      string
+	Color       string // 主色调，如 #FFAA00
+	CameraMake  string // 相机厂商（EXIF）
+	CameraModel string // 相机型号（EXIF）
+}
+
+// visionSuggestion 智能标注建议
+type visionSuggestion struct {
+	Tags         []string `json:"tags"`
+	Category     string   `json:"category"`
+	Introduction string   `json:"introduction"`
+}
+
+// visionProvider 智能标注服务：根据图片给出标签、分类与简介建议
+type visionProvider interface {
+	// Name 服务名称，记录在建议上
+	Name() string
+	// Suggest 生成建议，标签与分类应取自 tagList、categoryList 词表
+	Suggest(ctx context.Context, req *visionRequest) (*visionSuggestion, error)
+}
+
+// newVisionProvider 根据配置创建智能标注服务，未配置时默认使用本地推断
+func newVisionProvider(ctx context.Context) (visionProvider, error) {
+	provider := g.Cfg().MustGet(ctx, consts.AiVisionProvider, consts.AiVisionHeuristic).String()
+	switch provider {
+	case consts.AiVisionHeuristic:
+		return heuristicVision{}, nil
+	case consts.AiProviderVolcengine:
+		return newVolcengineVision(ctx), nil
+	default:
+		return nil, gerror.Newf("不支持的智能标注服务: %s", provider)
+	}
+}
+
+// normalizeSuggestion 整理服务返回的建议：只保留词表中的标签与分类，标签去重并限制数量，简介限制字数
+func normalizeSuggestion(suggestion *visionSuggestion) *visionSuggestion {
+	result := &visionSuggestion{Tags: []string{}}
+	for _, tag := range suggestion.Tags {
+		tag = strings.TrimSpace(tag)
+		if len(result.Tags) < maxSuggestionTags && slices.Contains(tagList, tag) && !slices.Contains(result.Tags, tag) {
+			result.Tags = append(result.Tags, tag)
+		}
+	}
+	if category := strings.TrimSpace(suggestion.Category); slices.Contains(categoryList, category) {
+		result.Category = category
+	}
+	introduction := []rune(strings.TrimSpace(suggestion.Introduction))
+	if len(introduction) > maxSuggestionIntroduction {
+		introduction = introduction[:maxSuggestionIntroduction]
+	}
+	result.Introduction = string(introduction)
+	return result
+}
@@ -0,0 +1,150 @@
+package picture
+
+import (
+	"cloud/internal/consts"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// heuristicVision 本地智能标注：不读取图片内容，按主色调、格式、尺寸与EXIF推断标签、分类并生成简介
+type heuristicVision struct{}
+
+func (heuristicVision) Name() string {
+	return consts.AiVisionHeuristic
+}
+
+func (heuristicVision) Suggest(ctx context.Context, req *visionRequest) (*visionSuggestion, error) {
+	return heuristicSuggest(req), nil
+}
+
+// heuristicSuggest 按图片信息推断建议
+func heuristicSuggest(req *visionRequest) *visionSuggestion {
+	var tags []string
+	format := strings.ToLower(req.Format)
+	photo := req.CameraMake != "" || req.CameraModel != ""
+	if photo {
+		tags = append(tags, "摄影")
+	}
+
+	// 色彩
+	tone := colorTone(req.Color)
+	if tone != "" {
+		tags = append(tags, tone)
+	}
+	if tone != "黑白" && req.Color != "" {
+		tags = append(tags, "彩色")
+	}
+
+	// 格式与尺寸
+	pixels := req.Width * req.Height
+	ratio := 0.0
+	if req.Height > 0 {
+		ratio = float64(req.Width) / float64(req.Height)
+	}
+	if format == "svg" {
+		tags = append(tags, "矢量")
+	}
+	switch {
+	case pixels >= 3840*2160:
+		tags = append(tags, "4K", "高清")
+	case pixels >= 1920*1080:
+		tags = append(tags, "高清")
+	}
+	small := req.Width > 0 && req.Width <= 512 && req.Height <= 512
+	switch {
+	case ratio >= 3:
+		tags = append(tags, "banner")
+	case ratio >= 1.6 && req.Width >= 1920:
+		tags = append(tags, "壁纸")
+	case ratio > 0 && ratio <= 0.75 && !photo:
+		tags = append(tags, "海报")
+	case ratio >= 0.9 && ratio <= 1.1 && small && (format == "png" || format == "svg" || format == "ico"):
+		tags = append(tags, "图标")
+	case ratio >= 0.9 && ratio <= 1.1 && small:
+		tags = append(tags, "头像")
+	}
+
+	// 分类
+	category := "其他"
+	switch {
+	case format == "svg":
+		category = "矢量图形"
+	case slices.Contains(tags, "图标"):
+		category = "图标素材"
+	case photo:
+		category = "摄影作品"
+	case slices.Contains(tags, "海报") || slices.Contains(tags, "banner"):
+		category = "海报设计"
+	}
+
+	return normalizeSuggestion(&visionSuggestion{
+		Tags:         tags,
+		Category:     category,
+		Introduction: heuristicIntroduction(req, tone),
+	})
+}
+
+// heuristicIntroduction 生成简介，如“一张 1920×1080 的横版 JPEG 图片，主色调为暖色调，由 Canon EOS R5 拍摄”
+func heuristicIntroduction(req *visionRequest, tone string) string {
+	var b strings.Builder
+	b.WriteString("一张")
+	if req.Width > 0 && req.Height > 0 {
+		orientation := "方形"
+		if req.Width > req.Height {
+			orientation = "横版"
+		} else if req.Width < req.Height {
+			orientation = "竖版"
+		}
+		b.WriteString(fmt.Sprintf(" %d×%d 的%s", req.Width, req.Height, orientation))
+	}
+	if req.Format != "" {
+		b.WriteString(" " + strings.ToUpper(req.Format) + " ")
+	}
+	b.WriteString("图片")
+	if tone != "" {
+		b.WriteString("，主色调为" + tone)
+	}
+	if camera := strings.TrimSpace(req.CameraMake + " " + req.CameraModel); camera != "" {
+		b.WriteString("，由 " + camera + " 拍摄")
+	}
+	return b.String()
+}
+
+// colorTone 根据主色调判断色系：饱和度低为黑白，红黄色相为暖色调，青蓝色相为冷色调，其余不判断
+func colorTone(hexColor string) string {
+	var r, g, b int
+	if _, err := fmt.Sscanf(strings.TrimPrefix(hexColor, "#"), "%02x%02x%02x", &r, &g, &b); err != nil {
+		return ""
+	}
+	maxC := float64(max(r, g, b)) / 255
+	minC := float64(min(r, g, b)) / 255
+	if maxC == 0 || (maxC-minC)/maxC < 0.15 {
+		return "黑白"
+	}
+
+	// 色相（0-360）
+	var hue float64
+	delta := maxC - minC
+	rf, gf, bf := float64(r)/255, float64(g)/255, float64(b)/255
+	switch maxC {
+	case rf:
+		hue = math.Mod((gf-bf)/delta, 6) * 60
+	case gf:
+		hue = ((bf-rf)/delta + 2) * 60
+	default:
+		hue = ((rf-gf)/delta + 4) * 60
+	}
+	if hue < 0 {
+		hue += 360
+	}
+	switch {
+	case hue < 70 || hue >= 320:
+		return "暖色调"
+	case hue >= 160 && hue < 280:
+		return "冷色调"
+	}
+	return ""
+}
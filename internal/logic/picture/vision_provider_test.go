@@ -0,0 +1,81 @@
+package picture
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_colorTone(t *testing.T) {
+	tests := map[string]string{
+		"#FF8800": "暖色调",
+		"#2060E0": "冷色调",
+		"#808080": "黑白",
+		"#000000": "黑白",
+		"#40C040": "",
+		"invalid": "",
+	}
+	for color, want := range tests {
+		if got := colorTone(color); got != want {
+			t.Errorf("colorTone(%s) = %q, want %q", color, got, want)
+		}
+	}
+}
+
+func Test_heuristicSuggest(t *testing.T) {
+	photo := heuristicSuggest(&visionRequest{Width: 3840, Height: 2160, Format: "jpeg", Color: "#E07020", CameraMake: "Canon", CameraModel: "EOS R5"})
+	if want := []string{"摄影", "暖色调", "彩色", "4K", "高清"}; !reflect.DeepEqual(photo.Tags, want) {
+		t.Errorf("照片标签错误, got=%v want=%v", photo.Tags, want)
+	}
+	if photo.Category != "摄影作品" {
+		t.Errorf("照片分类错误, got=%s", photo.Category)
+	}
+	if photo.Introduction != "一张 3840×2160 的横版 JPEG 图片，主色调为暖色调，由 Canon EOS R5 拍摄" {
+		t.Errorf("照片简介错误, got=%s", photo.Introduction)
+	}
+
+	icon := heuristicSuggest(&visionRequest{Width: 128, Height: 128, Format: "png", Color: "#2060E0"})
+	if icon.Category != "图标素材" || !reflect.DeepEqual(icon.Tags, []string{"冷色调", "彩色", "图标"}) {
+		t.Errorf("图标建议错误, got=%+v", icon)
+	}
+
+	vector := heuristicSuggest(&visionRequest{Format: "svg"})
+	if vector.Category != "矢量图形" || !reflect.DeepEqual(vector.Tags, []string{"矢量"}) {
+		t.Errorf("矢量图建议错误, got=%+v", vector)
+	}
+}
+
+func Test_normalizeSuggestion(t *testing.T) {
+	got := normalizeSuggestion(&visionSuggestion{
+		Tags:         []string{" 风景 ", "不存在的标签", "风景", "天空", "日落", "自然", "山川", "海洋"},
+		Category:     "不存在的分类",
+		Introduction: string(make([]rune, maxSuggestionIntroduction+10)),
+	})
+	if !reflect.DeepEqual(got.Tags, []string{"风景", "天空", "日落", "自然", "山川"}) {
+		t.Errorf("标签应过滤、去重并限制数量, got=%v", got.Tags)
+	}
+	if got.Category != "" {
+		t.Errorf("词表外的分类应丢弃, got=%s", got.Category)
+	}
+	if len([]rune(got.Introduction)) != maxSuggestionIntroduction {
+		t.Errorf("简介应截断, got=%d", len([]rune(got.Introduction)))
+	}
+}
+
+func Test_parseVisionAnswer(t *testing.T) {
+	got, err := parseVisionAnswer("```json\n{\"tags\":[\"风景\",\"夕阳\"],\"category\":\"风光摄影\",\"introduction\":\"海边日落\"}\n```")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Tags, []string{"风景"}) || got.Category != "风光摄影" || got.Introduction != "海边日落" {
+		t.Errorf("解析结果错误, got=%+v", got)
+	}
+	if _, err = parseVisionAnswer("无法识别这张图片"); err == nil {
+		t.Error("非JSON回复应返回错误")
+	}
+}
+
+func Test_mergeTags(t *testing.T) {
+	if got := mergeTags([]string{"风景", "天空"}, []string{"天空", "日落"}); !reflect.DeepEqual(got, []string{"风景", "天空", "日落"}) {
+		t.Errorf("mergeTags() = %v", got)
+	}
+}
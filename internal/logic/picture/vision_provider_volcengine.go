@@ -0,0 +1,92 @@
+package picture
+
+import (
+	"cloud/internal/consts"
+	"context"
+	"strings"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime"
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime/model"
+	"github.com/volcengine/volcengine-go-sdk/volcengine"
+)
+
+// volcengineVision 火山引擎方舟视觉理解模型
+type volcengineVision struct {
+	apiKey string
+	model  string
+}
+
+// newVolcengineVision 读取 ai.vision 配置，密钥与图片生成服务共用
+func newVolcengineVision(ctx context.Context) *volcengineVision {
+	return &volcengineVision{
+		apiKey: volcengineApiKey(ctx),
+		model:  g.Cfg().MustGet(ctx, "ai.vision.model", "doubao-1-5-vision-pro-32k-250115").String(),
+	}
+}
+
+func (p *volcengineVision) Name() string {
+	return consts.AiProviderVolcengine
+}
+
+func (p *volcengineVision) Suggest(ctx context.Context, req *visionRequest) (*visionSuggestion, error) {
+	if p.apiKey == "" {
+		return nil, gerror.New("AI服务未配置密钥")
+	}
+	prompt := "请为这张图片生成标注，只返回JSON：{\"tags\":[...],\"category\":\"...\",\"introduction\":\"...\"}。" +
+		"tags 从以下标签中选择1到5个：" + strings.Join(tagList, "、") + "；" +
+		"category 从以下分类中选择一个：" + strings.Join(categoryList, "、") + "；" +
+		"introduction 为不超过50字的中文简介。"
+	answer, err := volcengineChat(ctx, p.apiKey, p.model, req.ImageUrl, prompt)
+	if err != nil {
+		return nil, err
+	}
+	return parseVisionAnswer(answer)
+}
+
+// volcengineChat 调用视觉理解模型，以图片与文字提问，返回模型的文字回复
+func volcengineChat(ctx context.Context, apiKey string, modelName string, imageUrl string, prompt string) (string, error) {
+	client := arkruntime.NewClientWithApiKey(apiKey)
+	resp, err := client.CreateChatCompletion(ctx, model.CreateChatCompletionRequest{
+		Model: modelName,
+		Messages: []*model.ChatCompletionMessage{{
+			Role: model.ChatMessageRoleUser,
+			Content: &model.ChatCompletionMessageContent{ListValue: []*model.ChatCompletionMessageContentPart{
+				{Type: model.ChatCompletionMessageContentPartTypeImageURL, ImageURL: &model.ChatMessageImageURL{URL: imageUrl}},
+				{Type: model.ChatCompletionMessageContentPartTypeText, Text: prompt},
+			}},
+		}},
+		Temperature: volcengine.Float32(0.2),
+	})
+	if err != nil {
+		return "", gerror.Newf("AI服务调用失败: %v", err)
+	}
+	if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == nil || resp.Choices[0].Message.Content.StringValue == nil {
+		return "", gerror.New("AI服务返回结果为空")
+	}
+	return *resp.Choices[0].Message.Content.StringValue, nil
+}
+
+// extractJSON 取出模型回复中的JSON对象，兼容包裹在代码块或说明文字中的回复
+func extractJSON(answer string) (string, bool) {
+	start, end := strings.Index(answer, "{"), strings.LastIndex(answer, "}")
+	if start < 0 || end < start {
+		return "", false
+	}
+	return answer[start : end+1], true
+}
+
+// parseVisionAnswer 解析模型回复的标注建议
+func parseVisionAnswer(answer string) (*visionSuggestion, error) {
+	content, ok := extractJSON(answer)
+	if !ok {
+		return nil, gerror.New("AI服务返回结果格式错误")
+	}
+	var suggestion *visionSuggestion
+	if err := gjson.DecodeTo(content, &suggestion); err != nil || suggestion == nil {
+		return nil, gerror.New("AI服务返回结果格式错误")
+	}
+	return normalizeSuggestion(suggestion), nil
+}
@@ -28,20 +28,19 @@ func (s *sSpace) Add(ctx context.Context, req *v1.SpaceAddReq) (res *v1.SpaceAdd
 	}
 
 	// 根据空间级别设置限制
-	var maxSize, maxCount int64
-	switch req.SpaceLevel {
-	case 0: // 普通版
-		maxSize = 100 * 1024 * 1024 // 100MB
-		maxCount = 100
-	case 1: // 专业版
-		maxSize = 1024 * 1024 * 1024 // 1GB
-		maxCount = 1000
-	case 2: // 旗舰版
-		maxSize = 10 * 1024 * 1024 * 1024 // 10GB
-		maxCount = 10000
-	default:
+	level := getSpaceLevel(req.SpaceLevel)
+	if level == nil {
 		return nil, gerror.New("无效的空间级别")
 	}
+	maxSize, maxCount := level.MaxSize, level.MaxCount
+
+	// 未指定时按系统配置决定是否去除隐私元数据
+	stripMetadata := 0
+	if req.StripMetadata != nil {
+		stripMetadata = *req.StripMetadata
+	} else if g.Cfg().MustGet(ctx, consts.PictureStripMetadata, true).Bool() {
+		stripMetadata = 1
+	}
 
 	// 使用分布式锁防止并发创建
 	lockKey := fmt.Sprintf("space:create:user:%d", user.Id)
@@ -83,14 +82,15 @@ func (s *sSpace) Add(ctx context.Context, req *v1.SpaceAddReq) (res *v1.SpaceAdd
 
 		// 插入空间记录
 		result, insertErr := dao.Space.Ctx(ctx).Data(do.Space{
-			SpaceName:  req.SpaceName,
-			SpaceLevel: req.SpaceLevel,
-			MaxSize:    maxSize,
-			MaxCount:   maxCount,
-			TotalSize:  0,
-			TotalCount: 0,
-			UserId:     user.Id,
-			SpaceType:  req.SpaceType, // 使用请求中的空间类型
+			SpaceName:     req.SpaceName,
+			SpaceLevel:    req.SpaceLevel,
+			MaxSize:       maxSize,
+			MaxCount:      maxCount,
+			TotalSize:     0,
+			TotalCount:    0,
+			UserId:        user.Id,
+			SpaceType:     req.SpaceType, // 使用请求中的空间类型
+			StripMetadata: stripMetadata,
 		}).Insert()
 		if insertErr != nil {
 			g.Log().Errorf(ctx, "创建空间失败: %v", insertErr)
@@ -129,12 +129,16 @@ func (s *sSpace) Edit(ctx context.Context, req *v1.SpaceEditReq) (res *v1.SpaceE
 	}
 
 	// 更新空间信息
+	editData := do.Space{
+		SpaceName: req.SpaceName,
+		EditTime:  gtime.Now(),
+	}
+	if req.StripMetadata != nil {
+		editData.StripMetadata = *req.StripMetadata
+	}
 	_, err = dao.Space.Ctx(ctx).Where(dao.Space.Columns().Id, req.Id).
 		Where(dao.Space.Columns().IsDelete, 0).
-		Data(do.Space{
-			SpaceName: req.SpaceName,
-			EditTime:  gtime.Now(),
-		}).Update()
+		Data(editData).Update()
 	if err != nil {
 		g.Log().Errorf(ctx, "编辑空间失败: %v", err)
 		return nil, gerror.New("编辑空间失败")
@@ -215,32 +215,71 @@ func (s *sSpace) ListVOByPage(ctx context.Context, req *v1.SpaceQueryReq) (res *
 	}, nil
 }
 
-// ListLevel 获取空间级别列表
-func (s *sSpace) ListLevel(ctx context.Context, req *v1.SpaceLevelListReq) (res *v1.SpaceLevelListRes, err error) {
-	records := []v1.SpaceLevel{
-		{
-			Level:       0,
-			Name:        "普通版",
-			Description: "适合个人用户，提供基础功能",
-			MaxSize:     100 * 1024 * 1024, // 100MB
-			MaxCount:    100,
-		},
-		{
-			Level:       1,
-			Name:        "专业版",
-			Description: "适合小团队，提供更多存储空间",
-			MaxSize:     1024 * 1024 * 1024, // 1GB
-			MaxCount:    1000,
-		},
-		{
-			Level:       2,
-			Name:        "旗舰版",
-			Description: "适合大团队，提供最大存储空间",
-			MaxSize:     10 * 1024 * 1024 * 1024, // 10GB
-			MaxCount:    10000,
-		},
+// spaceLevelList 空间级别定义，创建空间与额度校验均以此为准
+var spaceLevelList = []v1.SpaceLevel{
+	{
+		Level:                0,
+		Name:                 "普通版",
+		Description:          "适合个人用户，提供基础功能",
+		MaxSize:              100 * 1024 * 1024, // 100MB
+		MaxCount:             100,
+		MaxOutPaintingScale:  2,
+		MaxOutPaintingPixels: 2048 * 2048,
+	},
+	{
+		Level:                1,
+		Name:                 "专业版",
+		Description:          "适合小团队，提供更多存储空间",
+		MaxSize:              1024 * 1024 * 1024, // 1GB
+		MaxCount:             1000,
+		MaxOutPaintingScale:  3,
+		MaxOutPaintingPixels: 4096 * 4096,
+	},
+	{
+		Level:                2,
+		Name:                 "旗舰版",
+		Description:          "适合大团队，提供最大存储空间",
+		MaxSize:              10 * 1024 * 1024 * 1024, // 10GB
+		MaxCount:             10000,
+		MaxOutPaintingScale:  4,
+		MaxOutPaintingPixels: 6144 * 6144,
+	},
+}
+
+// getSpaceLevel 根据级别获取空间级别定义
+func getSpaceLevel(level int) *v1.SpaceLevel {
+	for i := range spaceLevelList {
+		if spaceLevelList[i].Level == level {
+			return &spaceLevelList[i]
+		}
 	}
+	return nil
+}
 
+// GetLevel 获取空间所属级别的定义，公共图库（spaceId为0）按普通版处理
+func (s *sSpace) GetLevel(ctx context.Context, spaceId int64) (*v1.SpaceLevel, error) {
+	level := 0
+	if spaceId > 0 {
+		var space *entity.Space
+		err := dao.Space.Ctx(ctx).Where(dao.Space.Columns().Id, spaceId).
+			Where(dao.Space.Columns().IsDelete, 0).Scan(&space)
+		if err != nil || space == nil {
+			return nil, gerror.New("空间不存在")
+		}
+		level = space.SpaceLevel
+	}
+	spaceLevel := getSpaceLevel(level)
+	if spaceLevel == nil {
+		return nil, gerror.New("空间级别不存在")
+	}
+	levelCopy := *spaceLevel
+	return &levelCopy, nil
+}
+
+// ListLevel 获取空间级别列表
+func (s *sSpace) ListLevel(ctx context.Context, req *v1.SpaceLevelListReq) (res *v1.SpaceLevelListRes, err error) {
+	records := make([]v1.SpaceLevel, len(spaceLevelList))
+	copy(records, spaceLevelList)
 	return &v1.SpaceLevelListRes{
 		Records: records,
 	}, nil
@@ -249,19 +288,20 @@ func (s *sSpace) ListLevel(ctx context.Context, req *v1.SpaceLevelListReq) (res
 // entityToSpace 将entity转换为Space
 func (s *sSpace) entityToSpace(ctx context.Context, space *entity.Space) *v1.Space {
 	return &v1.Space{
-		Id:         space.Id,
-		SpaceName:  space.SpaceName,
-		SpaceLevel: space.SpaceLevel,
-		MaxSize:    space.MaxSize,
-		MaxCount:   space.MaxCount,
-		TotalSize:  space.TotalSize,
-		TotalCount: space.TotalCount,
-		UserId:     space.UserId,
-		CreateTime: space.CreateTime.Format(consts.Y_m_d_His),
-		EditTime:   space.EditTime.Format(consts.Y_m_d_His),
-		UpdateTime: space.UpdateTime.Format(consts.Y_m_d_His),
-		IsDelete:   space.IsDelete,
-		SpaceType:  space.SpaceType,
+		Id:            space.Id,
+		SpaceName:     space.SpaceName,
+		SpaceLevel:    space.SpaceLevel,
+		MaxSize:       space.MaxSize,
+		MaxCount:      space.MaxCount,
+		TotalSize:     space.TotalSize,
+		TotalCount:    space.TotalCount,
+		UserId:        space.UserId,
+		CreateTime:    space.CreateTime.Format(consts.Y_m_d_His),
+		EditTime:      space.EditTime.Format(consts.Y_m_d_His),
+		UpdateTime:    space.UpdateTime.Format(consts.Y_m_d_His),
+		IsDelete:      space.IsDelete,
+		SpaceType:     space.SpaceType,
+		StripMetadata: space.StripMetadata,
 	}
 }
 
@@ -291,6 +331,7 @@ func (s *sSpace) entityToSpaceVO(ctx context.Context, space *entity.Space, userI
 		EditTime:       space.EditTime.Format(consts.Y_m_d_His),
 		UpdateTime:     space.UpdateTime.Format(consts.Y_m_d_His),
 		SpaceType:      space.SpaceType,
+		StripMetadata:  space.StripMetadata,
 		PermissionList: permissions,
 	}
 }
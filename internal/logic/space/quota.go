@@ -0,0 +1,118 @@
+package space
+
+import (
+	v1 "cloud/api/user/v1"
+	"cloud/internal/consts"
+	"cloud/internal/dao"
+	"cloud/internal/model/entity"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// CheckQuota 预检查空间剩余额度（不占用额度，用于上传文件前快速失败）
+func (s *sSpace) CheckQuota(ctx context.Context, spaceId int64, size int64, count int64) error {
+	var space *entity.Space
+	err := dao.Space.Ctx(ctx).Where(dao.Space.Columns().Id, spaceId).
+		Where(dao.Space.Columns().IsDelete, 0).Scan(&space)
+	if err != nil || space == nil {
+		return gerror.New("空间不存在")
+	}
+	return s.quotaError(space, size, count)
+}
+
+// ReserveQuota 在事务中原子占用空间额度，超出空间容量或数量限制时返回额度不足错误
+func (s *sSpace) ReserveQuota(ctx context.Context, tx gdb.TX, spaceId int64, size int64, count int64) error {
+	space := dao.Space.Columns()
+	// 条件更新：只有累加后仍不超过限制时才会命中，保证并发上传时不会超额
+	result, err := dao.Space.Ctx(ctx).TX(tx).
+		Where(space.Id, spaceId).
+		Where(space.IsDelete, 0).
+		Where(fmt.Sprintf("%s + ? <= %s", space.TotalSize, s.limitExpr(space.MaxSize, func(l v1.SpaceLevel) int64 { return l.MaxSize })), size).
+		Where(fmt.Sprintf("%s + ? <= %s", space.TotalCount, s.limitExpr(space.MaxCount, func(l v1.SpaceLevel) int64 { return l.MaxCount })), count).
+		Data(g.Map{
+			space.TotalSize:  gdb.Raw(fmt.Sprintf("%s + %d", space.TotalSize, size)),
+			space.TotalCount: gdb.Raw(fmt.Sprintf("%s + %d", space.TotalCount, count)),
+		}).
+		Update()
+	if err != nil {
+		g.Log().Errorf(ctx, "更新空间统计失败 spaceId=%d: %v", spaceId, err)
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	// 未命中时区分空间不存在与额度不足
+	var current *entity.Space
+	err = dao.Space.Ctx(ctx).TX(tx).Where(space.Id, spaceId).
+		Where(space.IsDelete, 0).Scan(&current)
+	if err != nil || current == nil {
+		return gerror.New("空间不存在")
+	}
+	if quotaErr := s.quotaError(current, size, count); quotaErr != nil {
+		return quotaErr
+	}
+	return gerror.NewCode(consts.CodeSpaceQuotaExceeded, "空间额度不足")
+}
+
+// ReleaseQuota 在事务中释放空间额度（删除图片时调用），用量不会减到负数
+func (s *sSpace) ReleaseQuota(ctx context.Context, tx gdb.TX, spaceId int64, size int64, count int64) error {
+	space := dao.Space.Columns()
+	_, err := dao.Space.Ctx(ctx).TX(tx).
+		Where(space.Id, spaceId).
+		Data(g.Map{
+			space.TotalSize:  gdb.Raw(fmt.Sprintf("GREATEST(%s - %d, 0)", space.TotalSize, size)),
+			space.TotalCount: gdb.Raw(fmt.Sprintf("GREATEST(%s - %d, 0)", space.TotalCount, count)),
+		}).
+		Update()
+	if err != nil {
+		g.Log().Errorf(ctx, "释放空间额度失败 spaceId=%d: %v", spaceId, err)
+	}
+	return err
+}
+
+// quotaError 根据空间当前用量判断是否超出额度
+func (s *sSpace) quotaError(space *entity.Space, size int64, count int64) error {
+	maxSize, maxCount := s.effectiveLimit(space)
+	if space.TotalCount+count > maxCount {
+		return gerror.NewCodef(consts.CodeSpaceQuotaExceeded, "空间图片数量已达上限（%d 张）", maxCount)
+	}
+	if space.TotalSize+size > maxSize {
+		return gerror.NewCodef(consts.CodeSpaceQuotaExceeded, "空间存储容量不足，剩余 %.2fMB", float64(max(maxSize-space.TotalSize, 0))/1024/1024)
+	}
+	return nil
+}
+
+// effectiveLimit 获取空间实际生效的限制：空间自身未设置时使用所属级别的默认限制
+func (s *sSpace) effectiveLimit(space *entity.Space) (maxSize int64, maxCount int64) {
+	maxSize, maxCount = space.MaxSize, space.MaxCount
+	if level := getSpaceLevel(space.SpaceLevel); level != nil {
+		if maxSize <= 0 {
+			maxSize = level.MaxSize
+		}
+		if maxCount <= 0 {
+			maxCount = level.MaxCount
+		}
+	}
+	return maxSize, maxCount
+}
+
+// limitExpr 构造与 effectiveLimit 一致的SQL限制表达式
+func (s *sSpace) limitExpr(column string, levelLimit func(level v1.SpaceLevel) int64) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("IF(%s > 0, %s, CASE %s", column, column, dao.Space.Columns().SpaceLevel))
+	for _, level := range spaceLevelList {
+		b.WriteString(fmt.Sprintf(" WHEN %d THEN %d", level.Level, levelLimit(level)))
+	}
+	b.WriteString(" ELSE 0 END)")
+	return b.String()
+}
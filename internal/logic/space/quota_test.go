@@ -0,0 +1,71 @@
+package space
+
+import (
+	v1 "cloud/api/user/v1"
+	"cloud/internal/consts"
+	"cloud/internal/model/entity"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+func Test_effectiveLimit(t *testing.T) {
+	s := New()
+	// 未设置限制时使用所属级别的默认限制
+	maxSize, maxCount := s.effectiveLimit(&entity.Space{SpaceLevel: 1})
+	if maxSize != 1024*1024*1024 || maxCount != 1000 {
+		t.Errorf("应使用专业版默认限制, got=%d %d", maxSize, maxCount)
+	}
+	// 空间自身的限制优先
+	maxSize, maxCount = s.effectiveLimit(&entity.Space{SpaceLevel: 1, MaxSize: 500, MaxCount: 5})
+	if maxSize != 500 || maxCount != 5 {
+		t.Errorf("应使用空间自身的限制, got=%d %d", maxSize, maxCount)
+	}
+	// 未知级别且未设置限制时不允许占用
+	maxSize, maxCount = s.effectiveLimit(&entity.Space{SpaceLevel: 99})
+	if maxSize != 0 || maxCount != 0 {
+		t.Errorf("未知级别限制应为0, got=%d %d", maxSize, maxCount)
+	}
+}
+
+func Test_quotaError(t *testing.T) {
+	s := New()
+	space := &entity.Space{MaxSize: 1000, MaxCount: 10, TotalSize: 900, TotalCount: 9}
+	cases := []struct {
+		name        string
+		size, count int64
+		exceeded    bool
+	}{
+		{"恰好用满", 100, 1, false},
+		{"容量超出1字节", 101, 1, true},
+		{"数量超出", 0, 2, true},
+		{"只调整容量", -100, 0, false},
+	}
+	for _, c := range cases {
+		err := s.quotaError(space, c.size, c.count)
+		if c.exceeded != (err != nil) {
+			t.Errorf("%s: err=%v", c.name, err)
+			continue
+		}
+		if err != nil && gerror.Code(err) != consts.CodeSpaceQuotaExceeded {
+			t.Errorf("%s: 错误码应为额度不足, got=%v", c.name, gerror.Code(err))
+		}
+	}
+
+	// 已超额的空间剩余容量不显示为负数
+	err := s.quotaError(&entity.Space{MaxSize: 1000, MaxCount: 10, TotalSize: 2000}, 1, 0)
+	if err == nil || !strings.Contains(err.Error(), "剩余 0.00MB") {
+		t.Errorf("剩余容量错误: %v", err)
+	}
+}
+
+func Test_limitExpr(t *testing.T) {
+	s := New()
+	// SQL限制表达式与 effectiveLimit 一致：空间自身限制优先，否则按级别取默认值
+	expr := s.limitExpr("maxCount", func(level v1.SpaceLevel) int64 { return level.MaxCount })
+	want := "IF(maxCount > 0, maxCount, CASE spaceLevel WHEN 0 THEN 100 WHEN 1 THEN 1000 WHEN 2 THEN 10000 ELSE 0 END)"
+	if expr != want {
+		t.Errorf("限制表达式错误:\n got=%s\nwant=%s", expr, want)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"cloud/internal/consts"
 	"cloud/internal/model/entity"
 	wsmodel "cloud/internal/model/websocket"
+	"cloud/internal/service"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -147,9 +148,10 @@ func (s *sWebSocket) removeConnection(pictureID int64, conn *websocket.Conn, ses
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if connMap, exists := s.pictureConnections[pictureID]; exists {
-		// 如果是当前编辑者，清除编辑状态
+		// 如果是当前编辑者，清除编辑状态及未保存的操作
 		if s.pictureEditingUsers[pictureID] == session.UserID {
 			delete(s.pictureEditingUsers, pictureID)
+			delete(s.pictureEditSteps, pictureID)
 		}
 		delete(connMap, conn)
 
@@ -220,6 +222,8 @@ func (s *sWebSocket) handleMessage(ctx context.Context, pictureID int64, conn *w
 		s.handleExitEdit(ctx, pictureID, conn, session)
 	case wsmodel.MessageTypeEditAction:
 		s.handleEditAction(ctx, pictureID, conn, session, requestMsg)
+	case wsmodel.MessageTypeCommitEdit:
+		s.handleCommitEdit(ctx, pictureID, conn, session)
 	default:
 		s.sendErrorMessage(conn, "未知的消息类型")
 	}
@@ -230,8 +234,9 @@ func (s *sWebSocket) handleEnterEdit(ctx context.Context, pictureID int64, conn
 	// 先尝试获取编辑权限
 	s.mu.Lock()
 	if _, exists := s.pictureEditingUsers[pictureID]; !exists {
-		// 设置当前用户为编辑者
+		// 设置当前用户为编辑者，开始新的编辑会话
 		s.pictureEditingUsers[pictureID] = session.UserID
+		delete(s.pictureEditSteps, pictureID)
 		s.mu.Unlock()
 
 		// 先给发送者发送成功消息
@@ -262,7 +267,9 @@ func (s *sWebSocket) handleExitEdit(ctx context.Context, pictureID int64, conn *
 	s.mu.Lock()
 	editingUserID, exists := s.pictureEditingUsers[pictureID]
 	if exists && editingUserID == session.UserID {
+		// 未保存的操作随退出编辑丢弃
 		delete(s.pictureEditingUsers, pictureID)
+		delete(s.pictureEditSteps, pictureID)
 		s.mu.Unlock()
 
 		// 先给发送者发送成功消息
@@ -289,31 +296,85 @@ func (s *sWebSocket) handleExitEdit(ctx context.Context, pictureID int64, conn *
 
 // handleEditAction 处理编辑操作
 func (s *sWebSocket) handleEditAction(ctx context.Context, pictureID int64, conn *websocket.Conn, session *WebSocketSession, requestMsg wsmodel.PictureEditRequestMessage) {
-	// 检查是否是当前编辑者
-	s.mu.RLock()
-	editingUserID, exists := s.pictureEditingUsers[pictureID]
-	s.mu.RUnlock()
+	if requestMsg.EditAction == wsmodel.ActionCrop && (requestMsg.Crop == nil || requestMsg.Crop.Width <= 0 || requestMsg.Crop.Height <= 0) {
+		s.sendErrorMessage(conn, "裁剪区域无效")
+		return
+	}
 
+	// 检查是否是当前编辑者，并记录需要保存的变换操作
+	s.mu.Lock()
+	editingUserID, exists := s.pictureEditingUsers[pictureID]
 	if !exists || editingUserID != session.UserID {
+		s.mu.Unlock()
 		s.sendErrorMessage(conn, "您不是当前编辑者")
 		return
 	}
+	if requestMsg.EditAction.Persistent() {
+		s.pictureEditSteps[pictureID] = append(s.pictureEditSteps[pictureID], wsmodel.PictureEditStep{
+			Action: requestMsg.EditAction,
+			Crop:   requestMsg.Crop,
+		})
+	}
+	s.mu.Unlock()
 
 	// 在锁外广播编辑操作给其他用户（排除发送者）
 	s.broadcastMessage(ctx, pictureID, wsmodel.PictureEditResponseMessage{
 		Type:       wsmodel.MessageTypeEditAction,
 		Message:    fmt.Sprintf("%s 执行 %s", session.UserName, requestMsg.EditAction.GetActionText()),
 		EditAction: requestMsg.EditAction,
+		Crop:       requestMsg.Crop,
 		User:       &entity.User{Id: session.UserID, UserName: session.UserName},
 	}, session)
 }
 
+// handleCommitEdit 保存编辑：将会话中累积的变换操作应用到原图生成新文件，并广播新地址给所有连接的用户
+func (s *sWebSocket) handleCommitEdit(ctx context.Context, pictureID int64, conn *websocket.Conn, session *WebSocketSession) {
+	s.mu.RLock()
+	editingUserID, exists := s.pictureEditingUsers[pictureID]
+	steps := append([]wsmodel.PictureEditStep(nil), s.pictureEditSteps[pictureID]...)
+	s.mu.RUnlock()
+
+	if !exists || editingUserID != session.UserID {
+		s.sendErrorMessage(conn, "您不是当前编辑者")
+		return
+	}
+	if len(steps) == 0 {
+		s.sendErrorMessage(conn, "没有需要保存的编辑操作")
+		return
+	}
+
+	pictureVO, err := service.Picture().ApplyEditActions(ctx, pictureID, session.UserID, steps)
+	if err != nil {
+		g.Log().Errorf(ctx, "保存图片编辑失败 pictureId=%d: %v", pictureID, err)
+		s.sendErrorMessage(conn, err.Error())
+		return
+	}
+
+	// 已保存的操作从会话中移除（保存期间编辑者只能通过当前连接追加操作，消息按顺序处理）
+	s.mu.Lock()
+	if pending := s.pictureEditSteps[pictureID]; len(pending) > len(steps) {
+		s.pictureEditSteps[pictureID] = pending[len(steps):]
+	} else {
+		delete(s.pictureEditSteps, pictureID)
+	}
+	s.mu.Unlock()
+
+	s.broadcastMessage(ctx, pictureID, wsmodel.PictureEditResponseMessage{
+		Type:         wsmodel.MessageTypeCommitEdit,
+		Message:      fmt.Sprintf("用户 %s 保存了图片编辑", session.UserName),
+		User:         &entity.User{Id: session.UserID, UserName: session.UserName},
+		Url:          pictureVO.Url,
+		ThumbnailUrl: pictureVO.ThumbnailUrl,
+	}, nil)
+}
+
 // handleUserExit 处理用户退出
 func (s *sWebSocket) handleUserExit(ctx context.Context, pictureID int64, conn *websocket.Conn, session *WebSocketSession) {
 	// 如果是编辑者，自动退出编辑状态
 	s.mu.Lock()
 	if editingUserID, exists := s.pictureEditingUsers[pictureID]; exists && editingUserID == session.UserID {
 		delete(s.pictureEditingUsers, pictureID)
+		delete(s.pictureEditSteps, pictureID)
 	}
 	s.mu.Unlock()
 
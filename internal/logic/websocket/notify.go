@@ -0,0 +1,104 @@
+package websocket
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gorilla/websocket"
+)
+
+// notifyWriteTimeout 推送消息的写超时，避免慢连接阻塞推送方
+const notifyWriteTimeout = 10 * time.Second
+
+// userConn 用户通知连接，推送可能来自多个协程，写操作需串行
+type userConn struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+// AITaskNotify 建立AI任务通知连接，任务完成时向当前用户推送结果
+func (s *sWebSocket) AITaskNotify(ctx context.Context, r *ghttp.Request) {
+	loginUser := s.getLoginUserFromRequest(ctx, r)
+	if loginUser == nil {
+		r.Response.WriteJsonExit(g.Map{
+			"code":    40100,
+			"message": "用户未登录",
+		})
+		return
+	}
+
+	ws, err := wsUpGrader.Upgrade(r.Response.Writer, r.Request, nil)
+	if err != nil {
+		r.Response.WriteJsonExit(g.Map{
+			"code":    40500,
+			"message": "WebSocket升级失败",
+			"data":    err.Error(),
+		})
+		return
+	}
+	defer ws.Close()
+
+	conn := &userConn{conn: ws}
+	s.addUserConnection(loginUser.Id, conn)
+	defer s.removeUserConnection(loginUser.Id, conn)
+
+	// 只推送不处理客户端消息，持续读取以感知连接断开
+	for {
+		if _, _, err = ws.ReadMessage(); err != nil {
+			break
+		}
+	}
+}
+
+// NotifyUser 向用户的全部通知连接推送消息（仅限连接到本实例的客户端）
+func (s *sWebSocket) NotifyUser(ctx context.Context, userId int64, message any) {
+	messageBytes, err := gjson.Encode(message)
+	if err != nil {
+		g.Log().Error(ctx, "序列化消息失败:", err)
+		return
+	}
+
+	s.mu.RLock()
+	conns := make([]*userConn, 0, len(s.userConnections[userId]))
+	for conn := range s.userConnections[userId] {
+		conns = append(conns, conn)
+	}
+	s.mu.RUnlock()
+
+	for _, conn := range conns {
+		conn.mu.Lock()
+		_ = conn.conn.SetWriteDeadline(time.Now().Add(notifyWriteTimeout))
+		err = conn.conn.WriteMessage(ghttp.WsMsgText, messageBytes)
+		conn.mu.Unlock()
+		if err != nil {
+			g.Log().Warning(ctx, "发送WebSocket消息失败:", err)
+			s.removeUserConnection(userId, conn)
+		}
+	}
+}
+
+// addUserConnection 添加用户通知连接
+func (s *sWebSocket) addUserConnection(userId int64, conn *userConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.userConnections[userId] == nil {
+		s.userConnections[userId] = make(map[*userConn]struct{})
+	}
+	s.userConnections[userId][conn] = struct{}{}
+}
+
+// removeUserConnection 移除用户通知连接
+func (s *sWebSocket) removeUserConnection(userId int64, conn *userConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if conns, exists := s.userConnections[userId]; exists {
+		delete(conns, conn)
+		if len(conns) == 0 {
+			delete(s.userConnections, userId)
+		}
+	}
+}
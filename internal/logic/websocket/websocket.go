@@ -1,6 +1,7 @@
 package websocket
 
 import (
+	wsmodel "cloud/internal/model/websocket"
 	"cloud/internal/service"
 	"sync"
 
@@ -14,7 +15,9 @@ func init() {
 type sWebSocket struct {
 	mu                  sync.RWMutex
 	pictureConnections  map[int64]map[*websocket.Conn]*WebSocketSession
-	pictureEditingUsers map[int64]int64 // pictureId -> userId
+	pictureEditingUsers map[int64]int64                     // pictureId -> userId
+	pictureEditSteps    map[int64][]wsmodel.PictureEditStep // pictureId -> 当前编辑会话中待保存的变换操作
+	userConnections     map[int64]map[*userConn]struct{}    // userId -> AI任务通知连接
 }
 
 type WebSocketSession struct {
@@ -27,5 +30,7 @@ func New() *sWebSocket {
 	return &sWebSocket{
 		pictureConnections:  make(map[int64]map[*websocket.Conn]*WebSocketSession),
 		pictureEditingUsers: make(map[int64]int64),
+		pictureEditSteps:    make(map[int64][]wsmodel.PictureEditStep),
+		userConnections:     make(map[int64]map[*userConn]struct{}),
 	}
 }
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"cloud/internal/service"
+	"net/http"
+
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+// SignedFile 本地存储静态文件访问校验：私有/团队空间的文件签名无效或已过期时拒绝访问
+func SignedFile(r *ghttp.Request) {
+	if service.Bucket().VerifyLocalAccess(r.Context(), r.URL.Path, r.GetQuery("expires").String(), r.GetQuery("sign").String()) {
+		return
+	}
+	r.Response.WriteStatus(http.StatusForbidden)
+	r.ExitAll()
+}
@@ -0,0 +1,16 @@
+package wsmodel
+
+// MessageTypeAITask AI任务状态变更通知
+const MessageTypeAITask = "AI_TASK"
+
+// AITaskMessage AI任务完成（成功或失败）时推送给任务创建者的消息
+type AITaskMessage struct {
+	Type            string `json:"type"`                      // 消息类型
+	TaskId          string `json:"taskId"`                    // 任务ID
+	TaskType        string `json:"taskType"`                  // 任务类型
+	PictureId       int64  `json:"pictureId"`                 // 原图ID
+	TaskStatus      string `json:"taskStatus"`                // 任务状态
+	OutputImageUrl  string `json:"outputImageUrl,omitempty"`  // 输出图片URL
+	ResultPictureId int64  `json:"resultPictureId,omitempty"` // 保存到图库后的图片ID
+	ErrorMessage    string `json:"errorMessage,omitempty"`    // 错误信息
+}
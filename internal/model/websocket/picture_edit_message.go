@@ -11,6 +11,7 @@ const (
 	MessageTypeEnterEdit  PictureEditMessageType = "ENTER_EDIT"
 	MessageTypeExitEdit   PictureEditMessageType = "EXIT_EDIT"
 	MessageTypeEditAction PictureEditMessageType = "EDIT_ACTION"
+	MessageTypeCommitEdit PictureEditMessageType = "COMMIT_EDIT"
 )
 
 // PictureEditAction 图片编辑动作枚举
@@ -21,20 +22,51 @@ const (
 	ActionZoomOut     PictureEditAction = "ZOOM_OUT"
 	ActionRotateLeft  PictureEditAction = "ROTATE_LEFT"
 	ActionRotateRight PictureEditAction = "ROTATE_RIGHT"
+	ActionFlipH       PictureEditAction = "FLIP_HORIZONTAL"
+	ActionFlipV       PictureEditAction = "FLIP_VERTICAL"
+	ActionCrop        PictureEditAction = "CROP"
 )
 
+// CropArea 裁剪区域，坐标基于应用此前所有操作后的图片像素
+type CropArea struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// PictureEditStep 编辑会话中累积的一次图片变换操作
+type PictureEditStep struct {
+	Action PictureEditAction `json:"action"`
+	Crop   *CropArea         `json:"crop,omitempty"`
+}
+
 // PictureEditRequestMessage 图片编辑请求消息
 type PictureEditRequestMessage struct {
 	Type       PictureEditMessageType `json:"type"`       // 消息类型
 	EditAction PictureEditAction      `json:"editAction"` // 执行的编辑动作
+	Crop       *CropArea              `json:"crop"`       // 裁剪区域（CROP 动作）
 }
 
 // PictureEditResponseMessage 图片编辑响应消息
 type PictureEditResponseMessage struct {
-	Type       PictureEditMessageType `json:"type"`       // 消息类型
-	Message    string                 `json:"message"`    // 信息
-	EditAction PictureEditAction      `json:"editAction"` // 执行的编辑动作
-	User       *entity.User           `json:"user"`       // 用户信息
+	Type         PictureEditMessageType `json:"type"`                   // 消息类型
+	Message      string                 `json:"message"`                // 信息
+	EditAction   PictureEditAction      `json:"editAction"`             // 执行的编辑动作
+	Crop         *CropArea              `json:"crop,omitempty"`         // 裁剪区域
+	User         *entity.User           `json:"user"`                   // 用户信息
+	Url          string                 `json:"url,omitempty"`          // 保存编辑后的图片地址
+	ThumbnailUrl string                 `json:"thumbnailUrl,omitempty"` // 保存编辑后的缩略图地址
+}
+
+// Persistent 是否为需要保存到图片文件的变换操作（缩放只影响预览）
+func (action PictureEditAction) Persistent() bool {
+	switch action {
+	case ActionRotateLeft, ActionRotateRight, ActionFlipH, ActionFlipV, ActionCrop:
+		return true
+	default:
+		return false
+	}
 }
 
 // GetActionText 获取动作文本描述
@@ -48,6 +80,12 @@ func (action PictureEditAction) GetActionText() string {
 		return "左旋操作"
 	case ActionRotateRight:
 		return "右旋操作"
+	case ActionFlipH:
+		return "水平翻转操作"
+	case ActionFlipV:
+		return "垂直翻转操作"
+	case ActionCrop:
+		return "裁剪操作"
 	default:
 		return "未知操作"
 	}
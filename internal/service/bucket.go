@@ -8,6 +8,7 @@ package service
 import (
 	v1 "cloud/api/user/v1"
 	"context"
+	"time"
 )
 
 type (
@@ -16,8 +17,32 @@ type (
 		Upload(ctx context.Context, in *v1.BucketUploadReq) (res *v1.BucketUploadRes, err error)
 		// UploadByUrl URL上传
 		UploadByUrl(ctx context.Context, in *v1.BucketUploadByUrlReq) (res *v1.BucketUploadByUrlRes, err error)
+		// PresignPut 为指定key生成限时的预签名PUT上传地址，存储驱动不支持直传时返回错误
+		PresignPut(ctx context.Context, key string, expire time.Duration) (string, error)
+		// ReadObject 读取对象内容，超过大小上限时返回错误
+		ReadObject(ctx context.Context, key string, maxSize int64) ([]byte, error)
 		// Delete 删除
 		Delete(ctx context.Context, in *v1.BucketDeleteReq) (out *v1.BucketDeleteRes, err error)
+		// PutObject 按指定key写入对象（用于缩略图等服务端生成的文件）
+		PutObject(ctx context.Context, key string, data []byte, contentType string) error
+		// DeleteObjects 批量删除对象，删除失败的key记录到重试集合中，由定时任务补偿删除
+		DeleteObjects(ctx context.Context, keys ...string) (failed int)
+		// RetryFailedDeletes 重试删除之前删除失败的对象
+		RetryFailedDeletes(ctx context.Context)
+		// GetFileUrl 根据对象key拼接访问地址（前缀由当前存储驱动决定）
+		GetFileUrl(key string) string
+		// GetFileKey 根据访问地址解析对象key
+		GetFileKey(fileUrl string) string
+		// LocalStaticPath 本地存储驱动的静态访问路径与磁盘目录，其他驱动返回空
+		LocalStaticPath() (servePath string, root string)
+		// SignedUrl 为私有/团队空间的对象生成限时签名访问地址，公共对象及其他驱动写入的地址原样返回
+		SignedUrl(ctx context.Context, fileUrl string) string
+		// VerifyLocalAccess 校验本地存储静态文件的访问：私有/团队空间的文件必须携带有效且未过期的签名
+		VerifyLocalAccess(ctx context.Context, urlPath, expires, sign string) bool
+		// Fetch 下载远程文件到内存（只下载一次，超过大小上限时中止），返回文件内容与Content-Type
+		Fetch(ctx context.Context, fileUrl string) (data []byte, contentType string, err error)
+		// NewObjectKey 生成新的对象key：空间前缀 + 日期_UUID + 扩展名
+		NewObjectKey(spaceId int64, fileName string) string
 		// getFileExtFromUrl 从URL中提取文件扩展名
 		GetFileExtFromUrl(fileUrl string) string
 	}
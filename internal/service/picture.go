@@ -7,6 +7,7 @@ package service
 
 import (
 	v1 "cloud/api/user/v1"
+	wsmodel "cloud/internal/model/websocket"
 	"context"
 
 	"github.com/gogf/gf/v2/net/ghttp"
@@ -26,22 +27,82 @@ type (
 		Edit(ctx context.Context, req *v1.PictureEditReq) (res *v1.PictureEditRes, err error)
 		// Delete 删除图片
 		Delete(ctx context.Context, req *v1.DeleteReq) (res *v1.DeleteRes, err error)
+		// ListRecycleByPage 分页查询回收站中的图片
+		ListRecycleByPage(ctx context.Context, req *v1.PictureRecycleQueryReq) (res *v1.PictureRecycleQueryRes, err error)
+		// Restore 从回收站恢复图片，恢复到空间时重新占用空间额度
+		Restore(ctx context.Context, req *v1.PictureRestoreReq) (res *v1.PictureRestoreRes, err error)
+		// PurgeRecycleBin 彻底删除超过保留期的回收站图片（数据库记录与存储对象）
+		PurgeRecycleBin(ctx context.Context)
 		// Update 更新图片
 		Update(ctx context.Context, req *v1.PictureUpdateReq) (res *v1.PictureUpdateRes, err error)
+		// InitUpload 初始化分片上传会话
+		InitUpload(ctx context.Context, req *v1.PictureUploadInitReq) (res *v1.PictureUploadInitRes, err error)
+		// UploadChunk 上传单个分片，重复上传同一分片时覆盖
+		UploadChunk(ctx context.Context, req *v1.PictureUploadChunkReq, file *ghttp.UploadFile) (res *v1.PictureUploadChunkRes, err error)
+		// GetUploadStatus 查询分片上传进度（断点续传时获取已上传的分片）
+		GetUploadStatus(ctx context.Context, req *v1.PictureUploadSessionReq) (res *v1.PictureUploadStatusRes, err error)
+		// CompleteUpload 合并分片并校验文件，交由普通上传流程入库（含空间额度占用）
+		CompleteUpload(ctx context.Context, req *v1.PictureUploadSessionReq) (res *v1.PictureUploadRes, err error)
+		// AbortUpload 取消分片上传，删除已上传的分片
+		AbortUpload(ctx context.Context, req *v1.PictureUploadSessionReq) (res *v1.PictureUploadAbortRes, err error)
+		// CleanupUploadSessions 清理已过期会话遗留的分片临时文件与超时未完成的直传对象
+		CleanupUploadSessions(ctx context.Context)
+		// StartAITaskWorkers 启动AI任务处理协程，从任务表中领取到期的任务执行
+		StartAITaskWorkers(ctx context.Context)
+		// RecoverAITasks 处理执行超时的任务（如服务重启时正在执行）：未超过最大次数的重新排队，否则标记失败
+		RecoverAITasks(ctx context.Context)
+		// CleanupStubOutputs 删除本地AI服务替身生成的已过期结果
+		CleanupStubOutputs(ctx context.Context)
+		// PresignUpload 申请直传地址：预留对象key并生成限时的预签名PUT地址（存储驱动不支持时返回错误）
+		PresignUpload(ctx context.Context, req *v1.PictureUploadPresignReq) (res *v1.PictureUploadPresignRes, err error)
+		// FinalizeUpload 完成直传：校验对象已上传，解析图片信息后按普通上传的额度与审核规则入库
+		FinalizeUpload(ctx context.Context, req *v1.PictureUploadFinalizeReq) (res *v1.PictureUploadRes, err error)
 		// CreateOutPainting 创建扩图
 		CreateOutPainting(ctx context.Context, req *v1.CreatePictureOutPaintingReq) (res *v1.CreatePictureOutPaintingRes, err error)
 		// TagCategory 获取图片标签分类
 		TagCategory(ctx context.Context, req *v1.PictureTagCategoryReq) (res *v1.PictureTagCategoryRes, err error)
 		// Get 获取图片详情
 		Get(ctx context.Context, req *v1.PictureGetReq) (res *v1.PictureAdminGetRes, err error)
+		// Render 按参数实时转换图片（缩放、裁剪、格式、质量），权限与获取图片详情一致，转换结果缓存到对象存储
+		Render(ctx context.Context, req *v1.PictureRenderReq) (res *v1.PictureRenderRes, err error)
+		// ApplyEditActions 将协同编辑会话中累积的旋转、翻转、裁剪操作应用到原图，保存为图片的新文件
+		ApplyEditActions(ctx context.Context, pictureId int64, userId int64, steps []wsmodel.PictureEditStep) (*v1.PictureVO, error)
+		// ListVersions 分页查询图片的版本历史（新版本在前），权限与获取图片详情一致
+		ListVersions(ctx context.Context, req *v1.PictureVersionListReq) (res *v1.PictureVersionListRes, err error)
+		// RollbackVersion 将图片恢复到指定的历史版本（信息与文件），按文件大小变化修正空间用量，回滚本身记录为新版本
+		RollbackVersion(ctx context.Context, req *v1.PictureVersionRollbackReq) (res *v1.PictureVersionRollbackRes, err error)
+		// ListSuggestions 分页查询自己图片的智能标注建议
+		ListSuggestions(ctx context.Context, req *v1.PictureSuggestionListReq) (res *v1.PictureSuggestionListRes, err error)
+		// GenerateSuggestions 为已有图片重新生成智能标注建议（图片创建者或管理员）
+		GenerateSuggestions(ctx context.Context, req *v1.PictureSuggestionGenerateReq) (res *v1.PictureSuggestionGenerateRes, err error)
+		// AcceptSuggestions 批量采纳智能标注建议：更新图片信息（标签与已有标签合并）并记录版本，图片需重新审核
+		AcceptSuggestions(ctx context.Context, req *v1.PictureSuggestionAcceptReq) (res *v1.PictureSuggestionAcceptRes, err error)
+		// DismissSuggestions 批量忽略智能标注建议
+		DismissSuggestions(ctx context.Context, req *v1.PictureSuggestionDismissReq) (res *v1.PictureSuggestionDismissRes, err error)
+		// AddBlocklist 将图片加入黑名单，并拒绝所有内容相同的图片
+		AddBlocklist(ctx context.Context, req *v1.PictureBlocklistAddReq) (res *v1.PictureBlocklistAddRes, err error)
+		// ListBlocklist 分页查询图片黑名单
+		ListBlocklist(ctx context.Context, req *v1.PictureBlocklistListReq) (res *v1.PictureBlocklistListRes, err error)
+		// DeleteBlocklist 移出图片黑名单，已拒绝的图片不会自动恢复
+		DeleteBlocklist(ctx context.Context, req *v1.PictureBlocklistDeleteReq) (res *v1.PictureBlocklistDeleteRes, err error)
+		// ReviewByBatch 批量审核图片，所有图片使用相同的审核结论与审核信息，不存在或已删除的图片跳过
+		ReviewByBatch(ctx context.Context, req *v1.PictureReviewBatchReq) (res *v1.PictureReviewBatchRes, err error)
+		// ListReviewLogs 分页查询审核记录，按时间倒序
+		ListReviewLogs(ctx context.Context, req *v1.PictureReviewLogListReq) (res *v1.PictureReviewLogListRes, err error)
+		// ListPendingReview 分页查询待审核队列，等待最久的图片排在最前
+		ListPendingReview(ctx context.Context, req *v1.PictureReviewPendingReq) (res *v1.PictureReviewPendingRes, err error)
+		// ReviewStats 统计时间范围内各审核人的审核量，只统计通过与拒绝的结论，自动审核单独统计为审核人0
+		ReviewStats(ctx context.Context, req *v1.PictureReviewStatsReq) (res *v1.PictureReviewStatsRes, err error)
 		// GetVO 获取图片详情VO
 		GetVO(ctx context.Context, req *v1.PictureGetReq) (res *v1.PictureGetRes, err error)
 		// ListByPage 分页查询图片
 		ListByPage(ctx context.Context, req *v1.PictureQueryReq) (res *v1.PictureAdminQueryRes, err error)
 		// ListVOByPage 分页查询图片VO
 		ListVOByPage(ctx context.Context, req *v1.PictureQueryReq) (res *v1.PictureQueryRes, err error)
-		// SearchByPicture 以图搜图
+		// SearchByPicture 以图搜图（基于感知哈希在本地图库中查找相似图片）
 		SearchByPicture(ctx context.Context, req *v1.SearchPictureByPictureReq) (res []v1.SearchPictureByPictureRes, err error)
+		// DuplicateClusters 近似重复图片聚类报告（管理员）
+		DuplicateClusters(ctx context.Context, req *v1.PictureDuplicateClusterReq) (res *v1.PictureDuplicateClusterRes, err error)
 		// SearchByColor 按颜色搜索图片（基于欧氏距离的相似度搜索）
 		SearchByColor(ctx context.Context, req *v1.SearchPictureByColorReq) (res []v1.SearchPictureByColorRes, err error)
 		// Review 审核图片
@@ -8,6 +8,8 @@ package service
 import (
 	v1 "cloud/api/user/v1"
 	"context"
+
+	"github.com/gogf/gf/v2/database/gdb"
 )
 
 type (
@@ -28,8 +30,16 @@ type (
 		ListByPage(ctx context.Context, req *v1.SpaceQueryReq) (res *v1.SpaceQueryRes, err error)
 		// ListVOByPage 分页查询空间VO
 		ListVOByPage(ctx context.Context, req *v1.SpaceQueryReq) (res *v1.SpaceQueryVORes, err error)
+		// GetLevel 获取空间所属级别的定义，公共图库（spaceId为0）按普通版处理
+		GetLevel(ctx context.Context, spaceId int64) (*v1.SpaceLevel, error)
 		// ListLevel 获取空间级别列表
 		ListLevel(ctx context.Context, req *v1.SpaceLevelListReq) (res *v1.SpaceLevelListRes, err error)
+		// CheckQuota 预检查空间剩余额度（不占用额度，用于上传文件前快速失败）
+		CheckQuota(ctx context.Context, spaceId int64, size int64, count int64) error
+		// ReserveQuota 在事务中原子占用空间额度，超出空间容量或数量限制时返回额度不足错误
+		ReserveQuota(ctx context.Context, tx gdb.TX, spaceId int64, size int64, count int64) error
+		// ReleaseQuota 在事务中释放空间额度（删除图片时调用），用量不会减到负数
+		ReleaseQuota(ctx context.Context, tx gdb.TX, spaceId int64, size int64, count int64) error
 	}
 )
 
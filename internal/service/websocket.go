@@ -16,6 +16,10 @@ type (
 	IWebSocket interface {
 		// PictureEdit 添加用户
 		PictureEdit(ctx context.Context, r *ghttp.Request, req *v1.WebSocketPictureEditReq)
+		// AITaskNotify 建立AI任务通知连接，任务完成时向当前用户推送结果
+		AITaskNotify(ctx context.Context, r *ghttp.Request)
+		// NotifyUser 向用户的全部通知连接推送消息（仅限连接到本实例的客户端）
+		NotifyUser(ctx context.Context, userId int64, message any)
 	}
 )
 
@@ -0,0 +1,314 @@
+// Package safefetch 提供防SSRF的HTTP下载客户端：
+// 校验实际连接的IP（禁止回环、内网、链路本地等地址），限制重定向次数、响应大小与超时时间，
+// 并支持主机与网段的黑白名单配置。
+package safefetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gfile"
+)
+
+const (
+	DefaultTimeout      = 15 * time.Second // 默认整体超时时间
+	DefaultMaxSize      = "20MB"           // 默认响应大小上限
+	DefaultMaxRedirects = 3                // 默认最多跟随的重定向次数
+)
+
+var (
+	// ErrBlocked 目标地址被禁止访问
+	ErrBlocked = errors.New("safefetch: 禁止访问的地址")
+	// ErrTooLarge 响应内容超过大小上限
+	ErrTooLarge = errors.New("safefetch: 响应内容超过大小上限")
+)
+
+// blockedNets 默认禁止访问的网段：本网络、内网、运营商级NAT、回环、链路本地（含云厂商元数据地址）、保留及组播地址
+var blockedNets = mustParsePrefixes([]string{
+	"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16", "172.16.0.0/12",
+	"192.0.0.0/24", "192.168.0.0/16", "198.18.0.0/15", "224.0.0.0/4", "240.0.0.0/4",
+	"::/128", "::1/128", "fc00::/7", "fe80::/10", "ff00::/8",
+})
+
+// Config 客户端配置
+type Config struct {
+	Timeout      time.Duration // 整体超时时间（含重定向与读取响应）
+	MaxSize      int64         // 响应大小上限（字节），<=0 时不限制
+	MaxRedirects int           // 最多跟随的重定向次数
+	AllowHosts   []string      // 主机白名单（支持 *.example.com），非空时只允许访问名单内的主机
+	DenyHosts    []string      // 主机黑名单（支持 *.example.com）
+	AllowNets    []string      // 放行的网段（CIDR），优先于默认禁止网段，例如内网图床
+	DenyNets     []string      // 额外禁止的网段（CIDR）
+}
+
+// Client 防SSRF的HTTP客户端
+type Client struct {
+	config    Config
+	allowNets []netip.Prefix
+	denyNets  []netip.Prefix
+	client    *http.Client
+}
+
+var (
+	defaultClient *Client
+	defaultErr    error
+	defaultOnce   sync.Once
+)
+
+// Default 按配置文件 fetch 节点创建的全局客户端
+func Default(ctx context.Context) (*Client, error) {
+	defaultOnce.Do(func() {
+		cfg := g.Cfg()
+		defaultClient, defaultErr = New(Config{
+			Timeout:      cfg.MustGet(ctx, "fetch.timeout", DefaultTimeout).Duration(),
+			MaxSize:      gfile.StrToSize(cfg.MustGet(ctx, "fetch.maxSize", DefaultMaxSize).String()),
+			MaxRedirects: cfg.MustGet(ctx, "fetch.maxRedirects", DefaultMaxRedirects).Int(),
+			AllowHosts:   cfg.MustGet(ctx, "fetch.allowHosts").Strings(),
+			DenyHosts:    cfg.MustGet(ctx, "fetch.denyHosts").Strings(),
+			AllowNets:    cfg.MustGet(ctx, "fetch.allowNets").Strings(),
+			DenyNets:     cfg.MustGet(ctx, "fetch.denyNets").Strings(),
+		})
+	})
+	return defaultClient, defaultErr
+}
+
+// New 创建客户端
+func New(config Config) (*Client, error) {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.MaxRedirects < 0 {
+		config.MaxRedirects = 0
+	}
+	c := &Client{config: config}
+	var err error
+	if c.allowNets, err = parsePrefixes(config.AllowNets); err != nil {
+		return nil, err
+	}
+	if c.denyNets, err = parsePrefixes(config.DenyNets); err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   c.control,
+	}
+	c.client = &http.Client{
+		Timeout: config.Timeout,
+		Transport: &http.Transport{
+			// 不走代理，保证校验的是实际连接的地址
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: config.Timeout,
+			MaxIdleConns:          32,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: c.checkRedirect,
+	}
+	return c, nil
+}
+
+// MaxSize 响应大小上限（字节）
+func (c *Client) MaxSize() int64 {
+	return c.config.MaxSize
+}
+
+// Get 发送GET请求，响应体超过大小上限时读取返回 ErrTooLarge，调用方负责关闭响应体
+func (c *Client) Get(ctx context.Context, rawURL string, header http.Header) (*http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("safefetch: URL无效: %w", err)
+	}
+	if err = c.checkURL(u); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if c.config.MaxSize > 0 {
+		if resp.ContentLength > c.config.MaxSize {
+			resp.Body.Close()
+			return nil, ErrTooLarge
+		}
+		resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: c.config.MaxSize}
+	}
+	return resp, nil
+}
+
+// Fetch 下载完整响应内容，非200状态码时返回错误；未返回Content-Type时按内容推断
+func (c *Client) Fetch(ctx context.Context, rawURL string, header http.Header) (data []byte, contentType string, err error) {
+	resp, err := c.Get(ctx, rawURL, header)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("safefetch: 状态码错误: %d", resp.StatusCode)
+	}
+	if data, err = io.ReadAll(resp.Body); err != nil {
+		return nil, "", err
+	}
+	contentType = resp.Header.Get("Content-Type")
+	if contentType == "" && len(data) > 0 {
+		contentType = http.DetectContentType(data)
+	}
+	return data, contentType, nil
+}
+
+// checkRedirect 限制重定向次数，并校验重定向目标
+func (c *Client) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > c.config.MaxRedirects {
+		return fmt.Errorf("safefetch: 重定向次数超过 %d 次", c.config.MaxRedirects)
+	}
+	return c.checkURL(req.URL)
+}
+
+// checkURL 校验协议与主机名单，IP字面量直接校验地址
+func (c *Client) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: 不支持的协议 %q", ErrBlocked, u.Scheme)
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" {
+		return fmt.Errorf("%w: 主机为空", ErrBlocked)
+	}
+	if matchHost(c.config.DenyHosts, host) {
+		return fmt.Errorf("%w: %s", ErrBlocked, host)
+	}
+	if len(c.config.AllowHosts) > 0 && !matchHost(c.config.AllowHosts, host) {
+		return fmt.Errorf("%w: %s 不在白名单中", ErrBlocked, host)
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && !c.ipAllowed(ip) {
+		return fmt.Errorf("%w: %s", ErrBlocked, ip)
+	}
+	return nil
+}
+
+// control 建立连接前校验实际解析出的IP，防止通过DNS解析或重绑定访问内网
+func (c *Client) control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlocked, host)
+	}
+	if !c.ipAllowed(ip) {
+		return fmt.Errorf("%w: %s", ErrBlocked, ip)
+	}
+	return nil
+}
+
+// ipAllowed 判断IP是否允许访问：额外禁止网段 > 放行网段 > 默认禁止网段
+func (c *Client) ipAllowed(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if containsIP(c.denyNets, ip) {
+		return false
+	}
+	if containsIP(c.allowNets, ip) {
+		return true
+	}
+	return !containsIP(blockedNets, ip)
+}
+
+// limitedBody 限制读取大小的响应体
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ErrTooLarge
+	}
+	// 多读1个字节用于判断是否超出上限
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n, ErrTooLarge
+	}
+	return n, err
+}
+
+// matchHost 主机是否匹配名单，*.example.com 同时匹配 example.com 及其子域名
+func matchHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if host == suffix || strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func containsIP(prefixes []netip.Prefix, ip netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePrefixes 解析网段列表，单个IP视为/32或/128
+func parsePrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			ip, err := netip.ParseAddr(value)
+			if err != nil {
+				return nil, fmt.Errorf("safefetch: 无效的地址 %q: %w", value, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("safefetch: 无效的网段 %q: %w", value, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func mustParsePrefixes(values []string) []netip.Prefix {
+	prefixes, err := parsePrefixes(values)
+	if err != nil {
+		panic(err)
+	}
+	return prefixes
+}
@@ -0,0 +1,76 @@
+package safefetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func Test_ipAllowed(t *testing.T) {
+	c, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocked := []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "0.0.0.0", "::1", "::ffff:127.0.0.1", "fd00::1", "fe80::1"}
+	for _, s := range blocked {
+		if c.ipAllowed(netip.MustParseAddr(s)) {
+			t.Errorf("%s 应被禁止访问", s)
+		}
+	}
+	for _, s := range []string{"8.8.8.8", "1.1.1.1", "2606:4700::1111"} {
+		if !c.ipAllowed(netip.MustParseAddr(s)) {
+			t.Errorf("%s 应允许访问", s)
+		}
+	}
+}
+
+func Test_Fetch(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/metadata":
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+		case "/large":
+			w.Write([]byte(strings.Repeat("a", 2048)))
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	// 默认禁止访问回环地址
+	c, _ := New(Config{})
+	if _, _, err := c.Fetch(ctx, server.URL, nil); !errors.Is(err, ErrBlocked) {
+		t.Errorf("访问回环地址应被禁止, err=%v", err)
+	}
+	// 非 http(s) 协议
+	if _, _, err := c.Fetch(ctx, "file:///etc/passwd", nil); !errors.Is(err, ErrBlocked) {
+		t.Errorf("file 协议应被禁止, err=%v", err)
+	}
+
+	// 放行回环网段后可正常访问，但重定向到元数据地址仍被禁止
+	c, err := New(Config{AllowNets: []string{"127.0.0.0/8"}, MaxSize: 1024, MaxRedirects: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _, err := c.Fetch(ctx, server.URL, nil)
+	if err != nil || string(data) != "ok" {
+		t.Errorf("放行后应可访问, data=%q err=%v", data, err)
+	}
+	if _, _, err = c.Fetch(ctx, server.URL+"/metadata", nil); !errors.Is(err, ErrBlocked) {
+		t.Errorf("重定向到元数据地址应被禁止, err=%v", err)
+	}
+	if _, _, err = c.Fetch(ctx, server.URL+"/large", nil); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("超过大小上限应返回 ErrTooLarge, err=%v", err)
+	}
+
+	// 主机黑名单
+	c, _ = New(Config{AllowNets: []string{"127.0.0.1"}, DenyHosts: []string{"127.0.0.1"}})
+	if _, _, err = c.Fetch(ctx, server.URL, nil); !errors.Is(err, ErrBlocked) {
+		t.Errorf("黑名单主机应被禁止, err=%v", err)
+	}
+}